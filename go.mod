@@ -0,0 +1,3 @@
+module RelationalGraphDB
+
+go 1.21
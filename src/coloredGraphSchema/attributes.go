@@ -0,0 +1,278 @@
+package coloredGraphSchema
+
+// the types attribute values can have
+type Sort int
+
+const (
+	StringSort Sort = iota
+	IntSort
+	FloatSort
+	BoolSort
+	DateSort
+)
+
+func (s Sort) String() string {
+	switch s {
+	case StringSort:
+		return "string"
+	case IntSort:
+		return "int"
+	case FloatSort:
+		return "float"
+	case BoolSort:
+		return "bool"
+	case DateSort:
+		return "date"
+	}
+	return "unknown sort"
+}
+
+// second return is false if name is not one of the strings given by Sort.String
+func SortByName(name string) (Sort, bool) {
+	for _, s := range []Sort{StringSort, IntSort, FloatSort, BoolSort, DateSort} {
+		if s.String() == name {
+			return s, true
+		}
+	}
+	return StringSort, false
+}
+
+// a column on a vertex, every element of the source gets a value of the sort
+// unlike the other edges there is no target vertex
+type AttributeEdge struct {
+	source     Vertex
+	sort       Sort
+	identifier string
+}
+
+func (f AttributeEdge) GetSource() Vertex {
+	return f.source
+}
+
+func (f AttributeEdge) GetSort() Sort {
+	return f.sort
+}
+
+func (f AttributeEdge) GetIdentifier() string {
+	return f.identifier
+}
+
+func (f AttributeEdge) Contains(badVertexName string) bool {
+	return f.GetSource().identifier == badVertexName
+}
+
+// lhsPath followed by lhsAttribute equals rhsPath followed by rhsAttribute
+// so last name(manager(e)) = last name(e) has lhsPath [manager] and rhsPath []
+type AttributeEquation struct {
+	lhsPath      []FunctionEdge
+	lhsAttribute AttributeEdge
+	rhsPath      []FunctionEdge
+	rhsAttribute AttributeEdge
+	identifier   string
+}
+
+func NewAttributeEquation(lhsPath []FunctionEdge, lhsAttribute AttributeEdge, rhsPath []FunctionEdge, rhsAttribute AttributeEdge, identifier string) AttributeEquation {
+	return AttributeEquation{lhsPath: lhsPath, lhsAttribute: lhsAttribute, rhsPath: rhsPath, rhsAttribute: rhsAttribute, identifier: identifier}
+}
+
+func (aeq AttributeEquation) GetLHSPath() []FunctionEdge {
+	return aeq.lhsPath
+}
+
+func (aeq AttributeEquation) GetLHSAttribute() AttributeEdge {
+	return aeq.lhsAttribute
+}
+
+func (aeq AttributeEquation) GetRHSPath() []FunctionEdge {
+	return aeq.rhsPath
+}
+
+func (aeq AttributeEquation) GetRHSAttribute() AttributeEdge {
+	return aeq.rhsAttribute
+}
+
+func (aeq AttributeEquation) GetIdentifier() string {
+	return aeq.identifier
+}
+
+// the vertex both sides start at
+func (aeq AttributeEquation) GetSource() Vertex {
+	if len(aeq.lhsPath) > 0 {
+		return aeq.lhsPath[0].GetSource()
+	}
+	return aeq.lhsAttribute.GetSource()
+}
+
+// edgeName can be either a function edge or an attribute edge
+func (aeq AttributeEquation) Contains(edgeName string) bool {
+	for _, currentEdge := range aeq.lhsPath {
+		if currentEdge.GetIdentifier() == edgeName {
+			return true
+		}
+	}
+	for _, currentEdge := range aeq.rhsPath {
+		if currentEdge.GetIdentifier() == edgeName {
+			return true
+		}
+	}
+	return aeq.lhsAttribute.GetIdentifier() == edgeName || aeq.rhsAttribute.GetIdentifier() == edgeName
+}
+
+// the path has to end where the attribute starts
+func attributeTermEnd(path []FunctionEdge, attribute AttributeEdge) (bool, Vertex) {
+	if len(path) == 0 {
+		return true, attribute.GetSource()
+	}
+	valid, target := validPath(convertFEqToREq(path))
+	return valid && target == attribute.GetSource(), path[0].GetSource()
+}
+
+// both sides must be well formed terms starting at the same vertex and landing in the same sort
+func imposableAttributeEquation(equation AttributeEquation, allValidEdges map[PossiblyRelationEdge]bool, allValidAttributes map[AttributeEdge]bool) bool {
+	for _, currentEdge := range append(append([]FunctionEdge{}, equation.lhsPath...), equation.rhsPath...) {
+		if !allValidEdges[currentEdge] {
+			return false
+		}
+	}
+	if !allValidAttributes[equation.lhsAttribute] || !allValidAttributes[equation.rhsAttribute] {
+		return false
+	}
+	lhsValid, lhsStart := attributeTermEnd(equation.lhsPath, equation.lhsAttribute)
+	rhsValid, rhsStart := attributeTermEnd(equation.rhsPath, equation.rhsAttribute)
+	return lhsValid && rhsValid && lhsStart == rhsStart && equation.lhsAttribute.sort == equation.rhsAttribute.sort
+}
+
+func presentAttributes(myAttributes []AttributeEdge) map[AttributeEdge]bool {
+	myAttributeMap := make(map[AttributeEdge]bool)
+	for _, attribute := range myAttributes {
+		myAttributeMap[attribute] = true
+	}
+	return myAttributeMap
+}
+
+func (potentialSchema *SchemaGraph) GetAttributeEdges() []AttributeEdge {
+	return potentialSchema.attributeEdges
+}
+
+func (potentialSchema *SchemaGraph) GetAttributeEquations() []AttributeEquation {
+	return potentialSchema.attributeEquations
+}
+
+// make sure the source of this prospective attribute is in the graph
+// does not check if this attribute is already there
+func (startingSchema *SchemaGraph) AddAttributeEdge(newSource Vertex, sort Sort, description string) bool {
+	if vertexInVertices(newSource, startingSchema.vertices) {
+		newEdge := AttributeEdge{source: newSource, sort: sort, identifier: description}
+		startingSchema.attributeEdges = append(startingSchema.attributeEdges, newEdge)
+		return true
+	}
+	return false
+}
+
+// does not check if this attribute is already there
+func (startingSchema *SchemaGraph) AddAttributeEdge2(newSource string, sort Sort, description string) bool {
+	return startingSchema.AddAttributeEdge(Vertex{identifier: newSource}, sort, description)
+}
+
+func dummyAttributeEdge() AttributeEdge {
+	return AttributeEdge{source: dummyVertex(), identifier: "This is a dummy edge"}
+}
+
+func (startingSchema *SchemaGraph) GetAttributeEdgeByName(name string) (AttributeEdge, bool) {
+	for _, currentEdge := range startingSchema.attributeEdges {
+		if currentEdge.GetIdentifier() == name {
+			return currentEdge, true
+		}
+	}
+	return dummyAttributeEdge(), false
+}
+
+// whether any edge of any kind, attributes included, already uses name
+func (startingSchema *SchemaGraph) HasEdgeNamed(name string) bool {
+	_, isRelation := startingSchema.GetRelationEdgeByName(name)
+	_, isAttribute := startingSchema.GetAttributeEdgeByName(name)
+	return isRelation || isAttribute
+}
+
+// all the edges must be in the graph already
+// both sides must be paths of function edges ending at the source of their attribute
+// they must share source and sort
+// does not check if this equation is already there
+func (startingSchema *SchemaGraph) AddAttributeEquation(equation AttributeEquation) bool {
+	imposableEquation := imposableAttributeEquation(equation, presentEdgesF(startingSchema.functionEdges), presentAttributes(startingSchema.attributeEdges))
+	if imposableEquation {
+		startingSchema.attributeEquations = append(startingSchema.attributeEquations, equation)
+		return true
+	}
+	return false
+}
+
+// does not check if this equation is already there
+func (startingSchema *SchemaGraph) AddAttributeEquation2(lhsPathToBe []string, lhsAttributeToBe string, rhsPathToBe []string, rhsAttributeToBe string, identifierToBe string) bool {
+	newlhs := make([]FunctionEdge, len(lhsPathToBe))
+	newrhs := make([]FunctionEdge, len(rhsPathToBe))
+	var success bool
+	for i, currentString := range lhsPathToBe {
+		newlhs[i], success = startingSchema.GetFunctionEdgeByName(currentString)
+		if !success {
+			return false
+		}
+	}
+	for i, currentString := range rhsPathToBe {
+		newrhs[i], success = startingSchema.GetFunctionEdgeByName(currentString)
+		if !success {
+			return false
+		}
+	}
+	lhsAttribute, success := startingSchema.GetAttributeEdgeByName(lhsAttributeToBe)
+	if !success {
+		return false
+	}
+	rhsAttribute, success := startingSchema.GetAttributeEdgeByName(rhsAttributeToBe)
+	if !success {
+		return false
+	}
+	return startingSchema.AddAttributeEquation(AttributeEquation{lhsPath: newlhs, lhsAttribute: lhsAttribute, rhsPath: newrhs, rhsAttribute: rhsAttribute, identifier: identifierToBe})
+}
+
+// nothing goes wrong with validation
+func (startingSchema *SchemaGraph) RemoveAttributeEquation(toRemove string) int {
+	kept := make([]AttributeEquation, 0, len(startingSchema.attributeEquations))
+	for _, eq := range startingSchema.attributeEquations {
+		if eq.GetIdentifier() != toRemove {
+			kept = append(kept, eq)
+		}
+	}
+	removed := len(startingSchema.attributeEquations) - len(kept)
+	startingSchema.attributeEquations = kept
+	return removed
+}
+
+// every attribute equation mentioning the edge, function or attribute, called edgeName
+func (startingSchema *SchemaGraph) removeAttributeEquationsContaining(edgeName string) int {
+	attributeEquationsToRemove := make([]string, 0)
+	attributeEquationsRemoved := 0
+	for _, eq := range startingSchema.attributeEquations {
+		if eq.Contains(edgeName) {
+			attributeEquationsToRemove = append(attributeEquationsToRemove, eq.GetIdentifier())
+		}
+	}
+	for _, eqName := range attributeEquationsToRemove {
+		attributeEquationsRemoved = attributeEquationsRemoved + startingSchema.RemoveAttributeEquation(eqName)
+	}
+	return attributeEquationsRemoved
+}
+
+// when an attribute gets removed all the attribute equations that use it get removed as well
+func (startingSchema *SchemaGraph) RemoveAttributeEdge(toRemove string) RemovalCounts {
+	attributeEquationsRemoved := startingSchema.removeAttributeEquationsContaining(toRemove)
+	kept := make([]AttributeEdge, 0, len(startingSchema.attributeEdges))
+	for _, currentEdge := range startingSchema.attributeEdges {
+		if currentEdge.GetIdentifier() != toRemove {
+			kept = append(kept, currentEdge)
+		}
+	}
+	removed := len(startingSchema.attributeEdges) - len(kept)
+	startingSchema.attributeEdges = kept
+	return RemovalCounts{AttributeEdges: removed, AttributeEquations: attributeEquationsRemoved}
+}
@@ -0,0 +1,77 @@
+package coloredGraphSchema
+
+import "testing"
+
+// people with a name and an age working in departments with a name and a budget
+func peopleSchema(t *testing.T) SchemaGraph {
+	t.Helper()
+	schema := EmptySchemaGraph()
+	if !schema.AddVertex2("Person") || !schema.AddVertex2("Department") || !schema.AddFunctionEdge2("Person", "Department", "worksIn") {
+		t.Fatal("could not add people working in departments")
+	}
+	for _, attribute := range []struct {
+		source string
+		sort   Sort
+		name   string
+	}{{"Person", StringSort, "name"}, {"Person", IntSort, "age"}, {"Department", StringSort, "title"}, {"Department", IntSort, "budget"}} {
+		if !schema.AddAttributeEdge2(attribute.source, attribute.sort, attribute.name) {
+			t.Fatal("could not add " + attribute.name)
+		}
+	}
+	return schema
+}
+
+func TestAddAttributeEquation(t *testing.T) {
+	cases := []struct {
+		name         string
+		lhsPath      []string
+		lhsAttribute string
+		rhsPath      []string
+		rhsAttribute string
+		imposable    bool
+	}{
+		{"named after the department", []string{}, "name", []string{"worksIn"}, "title", true},
+		{"name is age", []string{}, "name", []string{}, "age", false},
+		{"age is budget", []string{}, "age", []string{"worksIn"}, "budget", true},
+		// both are strings but one starts at Person and the other at Department
+		{"name is title", []string{}, "name", []string{}, "title", false},
+		// age is on Person, not where worksIn ends
+		{"age of the department", []string{"worksIn"}, "age", []string{}, "age", false},
+		{"unknown attribute", []string{}, "name", []string{}, "nickname", false},
+		{"unknown edge", []string{"manages"}, "title", []string{}, "name", false},
+	}
+	for _, c := range cases {
+		schema := peopleSchema(t)
+		if added := schema.AddAttributeEquation2(c.lhsPath, c.lhsAttribute, c.rhsPath, c.rhsAttribute, c.name); added != c.imposable {
+			t.Errorf("%s: added %v, want %v", c.name, added, c.imposable)
+		}
+		if got := len(schema.GetAttributeEquations()); (got == 1) != c.imposable {
+			t.Errorf("%s: %d attribute equations in the schema", c.name, got)
+		}
+	}
+}
+
+func TestImposableAttributeEquation(t *testing.T) {
+	schema := peopleSchema(t)
+	worksIn, _ := schema.GetFunctionEdgeByName("worksIn")
+	attribute := func(name string) AttributeEdge {
+		edge, _ := schema.GetAttributeEdgeByName(name)
+		return edge
+	}
+	edges := presentEdgesF(schema.functionEdges)
+	attributes := presentAttributes(schema.attributeEdges)
+	if !imposableAttributeEquation(NewAttributeEquation([]FunctionEdge{worksIn}, attribute("budget"), nil, attribute("age"), "budget is age"), edges, attributes) {
+		t.Error("budget of the department is age was not imposable")
+	}
+	if imposableAttributeEquation(NewAttributeEquation([]FunctionEdge{worksIn}, attribute("title"), nil, attribute("age"), "title is age"), edges, attributes) {
+		t.Error("a string was imposed equal to an int")
+	}
+	if imposableAttributeEquation(NewAttributeEquation(nil, attribute("budget"), nil, attribute("age"), "budget is age"), edges, attributes) {
+		t.Error("sides starting at Department and Person were imposed equal")
+	}
+	// a lookalike of age on Department is not the attribute in the schema
+	moved := AttributeEdge{source: NewVertex("Department"), sort: IntSort, identifier: "age"}
+	if imposableAttributeEquation(NewAttributeEquation(nil, attribute("budget"), nil, moved, "budget is age"), edges, attributes) {
+		t.Error("an attribute not in the schema was used")
+	}
+}
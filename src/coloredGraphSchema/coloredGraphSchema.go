@@ -6,6 +6,10 @@ type Vertex struct {
 	identifier string
 }
 
+func NewVertex(identifier string) Vertex {
+	return Vertex{identifier: identifier}
+}
+
 func (v Vertex) GetIdentifier() string {
 	return v.identifier
 }
@@ -180,6 +184,18 @@ func convertFEqToREq(lhs []FunctionEdge) []PossiblyRelationEdge {
 	return interfaceSlice
 }
 
+func NewFunctionEquation(lhs []FunctionEdge, rhs []FunctionEdge, identifier string) FunctionEquation {
+	return FunctionEquation{lhs: lhs, rhs: rhs, identifier: identifier}
+}
+
+func (feq FunctionEquation) GetFunctionLHS() []FunctionEdge {
+	return feq.lhs
+}
+
+func (feq FunctionEquation) GetFunctionRHS() []FunctionEdge {
+	return feq.rhs
+}
+
 func (feq FunctionEquation) GetIdentifier() string {
 	return feq.identifier
 }
@@ -206,10 +222,21 @@ func (feq FunctionEquation) Contains(edgeName string) bool {
 	return false
 }
 
+// how the two sides of a partial function equation are compared
+// StrictEquality: defined on exactly the same elements and agree there
+// KleeneEquality: agree wherever both sides are defined
+type PartialEquality int
+
+const (
+	StrictEquality PartialEquality = iota
+	KleeneEquality
+)
+
 type PossiblyPartialFunctionEquation struct {
 	lhs        []PossiblyPartialFunctionEdge
 	rhs        []PossiblyPartialFunctionEdge
 	identifier string
+	equality   PartialEquality
 }
 
 func convertPFEqToREq(lhs []PossiblyPartialFunctionEdge) []PossiblyRelationEdge {
@@ -220,6 +247,28 @@ func convertPFEqToREq(lhs []PossiblyPartialFunctionEdge) []PossiblyRelationEdge
 	return interfaceSlice
 }
 
+func NewPartialFunctionEquation(lhs []PossiblyPartialFunctionEdge, rhs []PossiblyPartialFunctionEdge, identifier string) PossiblyPartialFunctionEquation {
+	return PossiblyPartialFunctionEquation{lhs: lhs, rhs: rhs, identifier: identifier}
+}
+
+func (feq PossiblyPartialFunctionEquation) GetPartialLHS() []PossiblyPartialFunctionEdge {
+	return feq.lhs
+}
+
+func (feq PossiblyPartialFunctionEquation) GetPartialRHS() []PossiblyPartialFunctionEdge {
+	return feq.rhs
+}
+
+func (feq PossiblyPartialFunctionEquation) WithEquality(equality PartialEquality) PossiblyPartialFunctionEquation {
+	feq.equality = equality
+	return feq
+}
+
+// defaults to StrictEquality
+func (feq PossiblyPartialFunctionEquation) GetEquality() PartialEquality {
+	return feq.equality
+}
+
 func (feq PossiblyPartialFunctionEquation) GetIdentifier() string {
 	return feq.identifier
 }
@@ -246,10 +295,35 @@ func (feq PossiblyPartialFunctionEquation) Contains(edgeName string) bool {
 	return false
 }
 
+// how the two sides of a relation equation are compared
+// SetEquality: every element is related to the same set by both sides
+// SetInclusion: every element is related by the lhs to a subset of what the rhs relates it to
+type RelationComparison int
+
+const (
+	SetEquality RelationComparison = iota
+	SetInclusion
+)
+
 type PossiblyRelationEquation struct {
 	lhs        []PossiblyRelationEdge
 	rhs        []PossiblyRelationEdge
 	identifier string
+	comparison RelationComparison
+}
+
+func NewRelationEquation(lhs []PossiblyRelationEdge, rhs []PossiblyRelationEdge, identifier string) PossiblyRelationEquation {
+	return PossiblyRelationEquation{lhs: lhs, rhs: rhs, identifier: identifier}
+}
+
+func (feq PossiblyRelationEquation) WithComparison(comparison RelationComparison) PossiblyRelationEquation {
+	feq.comparison = comparison
+	return feq
+}
+
+// defaults to SetEquality
+func (feq PossiblyRelationEquation) GetComparison() RelationComparison {
+	return feq.comparison
 }
 
 func (feq PossiblyRelationEquation) GetIdentifier() string {
@@ -286,9 +360,55 @@ type SchemaGraph struct {
 	functionEquations        []FunctionEquation
 	partialFunctionEquations []PossiblyPartialFunctionEquation
 	relationEquations        []PossiblyRelationEquation
+	attributeEdges           []AttributeEdge
+	attributeEquations       []AttributeEquation
+	// by edge name, only for function and partial function edges that do not use the default
+	deleteActions map[string]DeleteAction
+}
+
+func (potentialSchema *SchemaGraph) GetVertices() []Vertex {
+	return potentialSchema.vertices
+}
+
+func (potentialSchema *SchemaGraph) GetFunctionEdges() []FunctionEdge {
+	return potentialSchema.functionEdges
+}
+
+func (potentialSchema *SchemaGraph) GetPartialFunctionEdges() []PartialFunctionEdge {
+	return potentialSchema.partialFunctionEdges
+}
+
+func (potentialSchema *SchemaGraph) GetRelationEdges() []RelationEdge {
+	return potentialSchema.relationEdges
+}
+
+func (potentialSchema *SchemaGraph) GetFunctionEquations() []FunctionEquation {
+	return potentialSchema.functionEquations
+}
+
+func (potentialSchema *SchemaGraph) GetPartialFunctionEquations() []PossiblyPartialFunctionEquation {
+	return potentialSchema.partialFunctionEquations
+}
+
+func (potentialSchema *SchemaGraph) GetRelationEquations() []PossiblyRelationEquation {
+	return potentialSchema.relationEquations
 }
 
-func (potentialSchema *SchemaGraph) displayInfo() {
+// a schema with its own lists, so adding to or removing from either one leaves the other alone
+func (potentialSchema *SchemaGraph) Copy() SchemaGraph {
+	return SchemaGraph{vertices: append([]Vertex{}, potentialSchema.vertices...),
+		functionEdges:            append([]FunctionEdge{}, potentialSchema.functionEdges...),
+		partialFunctionEdges:     append([]PartialFunctionEdge{}, potentialSchema.partialFunctionEdges...),
+		relationEdges:            append([]RelationEdge{}, potentialSchema.relationEdges...),
+		functionEquations:        append([]FunctionEquation{}, potentialSchema.functionEquations...),
+		partialFunctionEquations: append([]PossiblyPartialFunctionEquation{}, potentialSchema.partialFunctionEquations...),
+		relationEquations:        append([]PossiblyRelationEquation{}, potentialSchema.relationEquations...),
+		attributeEdges:           append([]AttributeEdge{}, potentialSchema.attributeEdges...),
+		attributeEquations:       append([]AttributeEquation{}, potentialSchema.attributeEquations...),
+		deleteActions:            copyDeleteActions(potentialSchema.deleteActions)}
+}
+
+func (potentialSchema *SchemaGraph) DisplayInfo() {
 	for _, v := range potentialSchema.vertices {
 		fmt.Println("Vertex: " + v.GetIdentifier())
 	}
@@ -310,6 +430,12 @@ func (potentialSchema *SchemaGraph) displayInfo() {
 	for _, re := range potentialSchema.relationEquations {
 		fmt.Println("PossiblyRelationEquation: " + re.GetIdentifier())
 	}
+	for _, ae := range potentialSchema.attributeEdges {
+		fmt.Println("AttributeEdge: " + ae.GetIdentifier() + " : " + ae.GetSort().String())
+	}
+	for _, ae := range potentialSchema.attributeEquations {
+		fmt.Println("AttributeEquation: " + ae.GetIdentifier())
+	}
 
 }
 
@@ -441,7 +567,7 @@ func validateImposableEquationsR(equationsToImpose []PossiblyRelationEquation, a
 	return result
 }
 
-func validateGraph(potentialSchema SchemaGraph) bool {
+func ValidateGraph(potentialSchema SchemaGraph) bool {
 	myFunctionEdges := potentialSchema.functionEdges
 	myPartialFunctionEdges := potentialSchema.partialFunctionEdges
 	myRelationEdges := potentialSchema.relationEdges
@@ -486,10 +612,96 @@ func validateGraph(potentialSchema SchemaGraph) bool {
 	// imposable relation equations
 	myPresentEdges = addPresentEdgesR(myPresentEdges, myRelationEdges)
 	result = validateImposableEquationsR(potentialSchema.relationEquations, myPresentEdges)
+	if !result {
+		return false
+	}
+	// attributes and imposable attribute equations
+	for _, edge := range potentialSchema.attributeEdges {
+		result = myVertexMap[edge.source]
+		if !result {
+			return false
+		}
+	}
+	myPresentFunctionEdges := presentEdgesF(myFunctionEdges)
+	myPresentAttributes := presentAttributes(potentialSchema.attributeEdges)
+	for _, eq := range potentialSchema.attributeEquations {
+		result = imposableAttributeEquation(eq, myPresentFunctionEdges, myPresentAttributes)
+		if !result {
+			return false
+		}
+	}
 	return result
 }
 
-func emptySchemaGraph() SchemaGraph {
+// one of the reasons a schema fails ValidateGraph
+// either Edge has the endpoint Vertex which is not in the schema
+// or Equation can not be imposed with the edges of the right kinds
+type SchemaProblem struct {
+	Vertex   string
+	Edge     string
+	Equation string
+	Reason   string
+}
+
+func danglingEdgeProblems(edge UnspecifiedEdge, myVertexMap map[Vertex]bool) []SchemaProblem {
+	toReturn := make([]SchemaProblem, 0)
+	if !myVertexMap[edge.GetSource()] {
+		toReturn = append(toReturn, SchemaProblem{Vertex: edge.GetSource().identifier, Edge: edge.GetIdentifier(), Reason: "source is not a vertex of the schema"})
+	}
+	if !myVertexMap[edge.GetTarget()] {
+		toReturn = append(toReturn, SchemaProblem{Vertex: edge.GetTarget().identifier, Edge: edge.GetIdentifier(), Reason: "target is not a vertex of the schema"})
+	}
+	return toReturn
+}
+
+func unimposableEquationProblem(equation GeneralEquation, allValidEdges map[PossiblyRelationEdge]bool) []SchemaProblem {
+	if validateImposableEquation(equation, allValidEdges) {
+		return []SchemaProblem{}
+	}
+	return []SchemaProblem{{Equation: equation.GetIdentifier(), Reason: "sides are not composable paths of allowed edges with a common source and target"}}
+}
+
+// same checks as ValidateGraph but keeps going and says what went wrong
+func FindSchemaProblems(potentialSchema SchemaGraph) []SchemaProblem {
+	toReturn := make([]SchemaProblem, 0)
+	myVertexMap := potentialSchema.presentVertices()
+	for _, edge := range potentialSchema.functionEdges {
+		toReturn = append(toReturn, danglingEdgeProblems(edge, myVertexMap)...)
+	}
+	for _, edge := range potentialSchema.partialFunctionEdges {
+		toReturn = append(toReturn, danglingEdgeProblems(edge, myVertexMap)...)
+	}
+	for _, edge := range potentialSchema.relationEdges {
+		toReturn = append(toReturn, danglingEdgeProblems(edge, myVertexMap)...)
+	}
+	myPresentEdges := presentEdgesF(potentialSchema.functionEdges)
+	for _, eq := range potentialSchema.functionEquations {
+		toReturn = append(toReturn, unimposableEquationProblem(eq, myPresentEdges)...)
+	}
+	myPresentEdges = addPresentEdgesPF(myPresentEdges, potentialSchema.partialFunctionEdges)
+	for _, eq := range potentialSchema.partialFunctionEquations {
+		toReturn = append(toReturn, unimposableEquationProblem(eq, myPresentEdges)...)
+	}
+	myPresentEdges = addPresentEdgesR(myPresentEdges, potentialSchema.relationEdges)
+	for _, eq := range potentialSchema.relationEquations {
+		toReturn = append(toReturn, unimposableEquationProblem(eq, myPresentEdges)...)
+	}
+	for _, edge := range potentialSchema.attributeEdges {
+		if !myVertexMap[edge.source] {
+			toReturn = append(toReturn, SchemaProblem{Vertex: edge.source.identifier, Edge: edge.GetIdentifier(), Reason: "source is not a vertex of the schema"})
+		}
+	}
+	myPresentFunctionEdges := presentEdgesF(potentialSchema.functionEdges)
+	myPresentAttributes := presentAttributes(potentialSchema.attributeEdges)
+	for _, eq := range potentialSchema.attributeEquations {
+		if !imposableAttributeEquation(eq, myPresentFunctionEdges, myPresentAttributes) {
+			toReturn = append(toReturn, SchemaProblem{Equation: eq.GetIdentifier(), Reason: "sides are not function paths ending in attributes of the same sort with a common source"})
+		}
+	}
+	return toReturn
+}
+
+func EmptySchemaGraph() SchemaGraph {
 	returnVal1 := make([]Vertex, 0)
 	returnVal2 := make([]FunctionEdge, 0)
 	returnVal3 := make([]PartialFunctionEdge, 0)
@@ -497,13 +709,17 @@ func emptySchemaGraph() SchemaGraph {
 	returnVal5 := make([]FunctionEquation, 0)
 	returnVal6 := make([]PossiblyPartialFunctionEquation, 0)
 	returnVal7 := make([]PossiblyRelationEquation, 0)
-	return SchemaGraph{vertices: returnVal1, functionEdges: returnVal2, partialFunctionEdges: returnVal3, relationEdges: returnVal4, functionEquations: returnVal5, partialFunctionEquations: returnVal6, relationEquations: returnVal7}
+	returnVal8 := make([]AttributeEdge, 0)
+	returnVal9 := make([]AttributeEquation, 0)
+	returnVal10 := make(map[string]DeleteAction)
+	return SchemaGraph{vertices: returnVal1, functionEdges: returnVal2, partialFunctionEdges: returnVal3, relationEdges: returnVal4, functionEquations: returnVal5, partialFunctionEquations: returnVal6, relationEquations: returnVal7,
+		attributeEdges: returnVal8, attributeEquations: returnVal9, deleteActions: returnVal10}
 }
 
 // nothing can go wrong with validation
 // just a disjoint extra vertex
 // might be a repeated name which will cause problems later
-func (startingSchema *SchemaGraph) addVertex2(newVertex string) bool {
+func (startingSchema *SchemaGraph) AddVertex2(newVertex string) bool {
 	if vertexInVertices2(newVertex, startingSchema.vertices) {
 		return false
 	}
@@ -511,7 +727,7 @@ func (startingSchema *SchemaGraph) addVertex2(newVertex string) bool {
 	return true
 }
 
-func (startingSchema *SchemaGraph) addVertex(newVertex Vertex) bool {
+func (startingSchema *SchemaGraph) AddVertex(newVertex Vertex) bool {
 	if vertexInVertices(newVertex, startingSchema.vertices) {
 		return false
 	}
@@ -521,7 +737,7 @@ func (startingSchema *SchemaGraph) addVertex(newVertex Vertex) bool {
 
 //make sure both source and target of this prospective edge are in the graph
 // does not check if this edge is already there
-func (startingSchema *SchemaGraph) addFunctionEdge(newSource Vertex, newTarget Vertex, description string) bool {
+func (startingSchema *SchemaGraph) AddFunctionEdge(newSource Vertex, newTarget Vertex, description string) bool {
 	sourceOK := vertexInVertices(newSource, startingSchema.vertices)
 	targetOK := vertexInVertices(newTarget, startingSchema.vertices)
 	if sourceOK && targetOK {
@@ -533,20 +749,20 @@ func (startingSchema *SchemaGraph) addFunctionEdge(newSource Vertex, newTarget V
 }
 
 // does not check if this edge is already there
-func (startingSchema *SchemaGraph) addFunctionEdge2(newSource string, newTarget string, description string) bool {
+func (startingSchema *SchemaGraph) AddFunctionEdge2(newSource string, newTarget string, description string) bool {
 	newSource2 := Vertex{identifier: newSource}
 	newTarget2 := Vertex{identifier: newTarget}
-	return startingSchema.addFunctionEdge(newSource2, newTarget2, description)
+	return startingSchema.AddFunctionEdge(newSource2, newTarget2, description)
 }
 
-func (startingSchema *SchemaGraph) addCartesianProductVertex(factor1, factor2 Vertex) bool {
+func (startingSchema *SchemaGraph) AddCartesianProductVertex(factor1, factor2 Vertex) bool {
 	factor1OK := vertexInVertices(factor1, startingSchema.vertices)
 	factor2OK := vertexInVertices(factor2, startingSchema.vertices)
 	if factor1OK && factor2OK {
 		name := factor1.GetIdentifier() + " X " + factor2.GetIdentifier()
-		startingSchema.addVertex2(name)
-		startingSchema.addFunctionEdge2(name, factor1.GetIdentifier(), name+" projection 1")
-		startingSchema.addFunctionEdge2(name, factor2.GetIdentifier(), name+" projection 2")
+		startingSchema.AddVertex2(name)
+		startingSchema.AddFunctionEdge2(name, factor1.GetIdentifier(), name+" projection 1")
+		startingSchema.AddFunctionEdge2(name, factor2.GetIdentifier(), name+" projection 2")
 		return true
 	}
 	return false
@@ -554,7 +770,7 @@ func (startingSchema *SchemaGraph) addCartesianProductVertex(factor1, factor2 Ve
 
 //make sure both source and target of this prospective edge are in the graph
 // does not check if this edge is already there
-func (startingSchema *SchemaGraph) addPartialFunctionEdge(newSource Vertex, newTarget Vertex, description string) bool {
+func (startingSchema *SchemaGraph) AddPartialFunctionEdge(newSource Vertex, newTarget Vertex, description string) bool {
 	sourceOK := vertexInVertices(newSource, startingSchema.vertices)
 	targetOK := vertexInVertices(newTarget, startingSchema.vertices)
 	if sourceOK && targetOK {
@@ -566,15 +782,15 @@ func (startingSchema *SchemaGraph) addPartialFunctionEdge(newSource Vertex, newT
 }
 
 // does not check if this edge is already there
-func (startingSchema *SchemaGraph) addPartialFunctionEdge2(newSource string, newTarget string, description string) bool {
+func (startingSchema *SchemaGraph) AddPartialFunctionEdge2(newSource string, newTarget string, description string) bool {
 	newSource2 := Vertex{identifier: newSource}
 	newTarget2 := Vertex{identifier: newTarget}
-	return startingSchema.addPartialFunctionEdge(newSource2, newTarget2, description)
+	return startingSchema.AddPartialFunctionEdge(newSource2, newTarget2, description)
 }
 
 //make sure both source and target of this prospective edge are in the graph
 // does not check if this edge is already there
-func (startingSchema *SchemaGraph) addRelationEdge(newSource Vertex, newTarget Vertex, description string) bool {
+func (startingSchema *SchemaGraph) AddRelationEdge(newSource Vertex, newTarget Vertex, description string) bool {
 	sourceOK := vertexInVertices(newSource, startingSchema.vertices)
 	targetOK := vertexInVertices(newTarget, startingSchema.vertices)
 	if sourceOK && targetOK {
@@ -586,17 +802,17 @@ func (startingSchema *SchemaGraph) addRelationEdge(newSource Vertex, newTarget V
 }
 
 // does not check if this edge is already there
-func (startingSchema *SchemaGraph) addRelationEdge2(newSource string, newTarget string, description string) bool {
+func (startingSchema *SchemaGraph) AddRelationEdge2(newSource string, newTarget string, description string) bool {
 	newSource2 := Vertex{identifier: newSource}
 	newTarget2 := Vertex{identifier: newTarget}
-	return startingSchema.addRelationEdge(newSource2, newTarget2, description)
+	return startingSchema.AddRelationEdge(newSource2, newTarget2, description)
 }
 
 //all the edges in both lhs and rhs must be in the graph already
 //they must both be valid paths
 //they must share source and target
 // does not check if this equation is already there
-func (startingSchema *SchemaGraph) addFunctionEquation(equation FunctionEquation) bool {
+func (startingSchema *SchemaGraph) AddFunctionEquation(equation FunctionEquation) bool {
 	imposableEquation := validateImposableEquation(equation, presentEdgesF(startingSchema.functionEdges))
 	if imposableEquation {
 		startingSchema.functionEquations = append(startingSchema.functionEquations, equation)
@@ -613,7 +829,15 @@ func dummyEdge() FunctionEdge {
 	return FunctionEdge{source: dummyVertex(), target: dummyVertex(), identifier: "This is a dummy edge"}
 }
 
-func (startingSchema *SchemaGraph) getFunctionEdgeByName(name string) (FunctionEdge, bool) {
+func dummyPartialEdge() PartialFunctionEdge {
+	return PartialFunctionEdge{source: dummyVertex(), target: dummyVertex(), identifier: "This is a dummy edge"}
+}
+
+func dummyRelationEdge() RelationEdge {
+	return RelationEdge{source: dummyVertex(), target: dummyVertex(), identifier: "This is a dummy edge"}
+}
+
+func (startingSchema *SchemaGraph) GetFunctionEdgeByName(name string) (FunctionEdge, bool) {
 	for _, currentEdge := range startingSchema.functionEdges {
 		if currentEdge.GetIdentifier() == name {
 			return currentEdge, true
@@ -622,57 +846,65 @@ func (startingSchema *SchemaGraph) getFunctionEdgeByName(name string) (FunctionE
 	return dummyEdge(), false
 }
 
-func (startingSchema *SchemaGraph) getPartialFunctionEdgeByName(name string) (PossiblyPartialFunctionEdge, bool) {
-	toReturn, success := startingSchema.getFunctionEdgeByName(name)
-	if success {
-		return toReturn, true
-	}
+func (startingSchema *SchemaGraph) GetDefPartialFunctionEdgeByName(name string) (PartialFunctionEdge, bool) {
 	for _, currentEdge := range startingSchema.partialFunctionEdges {
 		if currentEdge.GetIdentifier() == name {
 			return currentEdge, true
 		}
 	}
-	return dummyEdge(), false
+	return dummyPartialEdge(), false
 }
 
-func (startingSchema *SchemaGraph) getRelationEdgeByName(name string) (PossiblyRelationEdge, bool) {
-	toReturn, success := startingSchema.getPartialFunctionEdgeByName(name)
+func (startingSchema *SchemaGraph) GetPartialFunctionEdgeByName(name string) (PossiblyPartialFunctionEdge, bool) {
+	toReturn, success := startingSchema.GetFunctionEdgeByName(name)
 	if success {
 		return toReturn, true
 	}
+	return startingSchema.GetDefPartialFunctionEdgeByName(name)
+}
+
+func (startingSchema *SchemaGraph) GetDefRelationEdgeByName(name string) (RelationEdge, bool) {
 	for _, currentEdge := range startingSchema.relationEdges {
 		if currentEdge.GetIdentifier() == name {
 			return currentEdge, true
 		}
 	}
-	return dummyEdge(), false
+	return dummyRelationEdge(), false
+}
+
+func (startingSchema *SchemaGraph) GetRelationEdgeByName(name string) (PossiblyRelationEdge, bool) {
+	toReturn, success := startingSchema.GetPartialFunctionEdgeByName(name)
+	if success {
+		return toReturn, true
+	}
+	return startingSchema.GetDefRelationEdgeByName(name)
 }
 
 // does not check if this equation is already there
-func (startingSchema *SchemaGraph) addFunctionEquation2(lhsToBe []string, rhsToBe []string, identifierToBe string) bool {
+func (startingSchema *SchemaGraph) AddFunctionEquation2(lhsToBe []string, rhsToBe []string, identifierToBe string) bool {
 	newlhs := make([]FunctionEdge, len(lhsToBe))
 	newrhs := make([]FunctionEdge, len(rhsToBe))
 	var success bool
 	for i, currentString := range lhsToBe {
-		newlhs[i], success = startingSchema.getFunctionEdgeByName(currentString)
+		newlhs[i], success = startingSchema.GetFunctionEdgeByName(currentString)
 		if !success {
 			return false
 		}
 	}
 	for i, currentString := range rhsToBe {
-		newrhs[i], success = startingSchema.getFunctionEdgeByName(currentString)
+		newrhs[i], success = startingSchema.GetFunctionEdgeByName(currentString)
 		if !success {
 			return false
 		}
 	}
-	return startingSchema.addFunctionEquation(FunctionEquation{lhs: newlhs, rhs: newrhs, identifier: identifierToBe})
+	return startingSchema.AddFunctionEquation(FunctionEquation{lhs: newlhs, rhs: newrhs, identifier: identifierToBe})
 }
 
 //all the edges in both lhs and rhs must be in the graph already
 //they must both be valid paths
 //they must share source and target
 // does not check if this equation is already there
-func (startingSchema *SchemaGraph) addPartialFunctionEquation(equation PossiblyPartialFunctionEquation) bool {
+func (startingSchema *SchemaGraph) AddPartialFunctionEquation(equation PossiblyPartialFunctionEquation) bool {
 	myPresentEdges := presentEdgesF(startingSchema.functionEdges)
 	myPresentEdges = addPresentEdgesPF(myPresentEdges, startingSchema.partialFunctionEdges)
 	imposableEquation := validateImposableEquation(equation, myPresentEdges)
@@ -684,30 +916,41 @@ func (startingSchema *SchemaGraph) addPartialFunctionEquation(equation PossiblyP
 }
 
 // does not check if this equation is already there
-func (startingSchema *SchemaGraph) addPartialFunctionEquation2(lhsToBe []string, rhsToBe []string, identifierToBe string) bool {
+// the equation is imposed with StrictEquality
+func (startingSchema *SchemaGraph) AddPartialFunctionEquation2(lhsToBe []string, rhsToBe []string, identifierToBe string) bool {
+	return startingSchema.addPartialFunctionEquation2(lhsToBe, rhsToBe, identifierToBe, StrictEquality)
+}
+
+// does not check if this equation is already there
+// the equation is imposed with KleeneEquality
+func (startingSchema *SchemaGraph) AddKleenePartialFunctionEquation2(lhsToBe []string, rhsToBe []string, identifierToBe string) bool {
+	return startingSchema.addPartialFunctionEquation2(lhsToBe, rhsToBe, identifierToBe, KleeneEquality)
+}
+
+func (startingSchema *SchemaGraph) addPartialFunctionEquation2(lhsToBe []string, rhsToBe []string, identifierToBe string, equality PartialEquality) bool {
 	newlhs := make([]PossiblyPartialFunctionEdge, len(lhsToBe))
 	newrhs := make([]PossiblyPartialFunctionEdge, len(rhsToBe))
 	var success bool
 	for i, currentString := range lhsToBe {
-		newlhs[i], success = startingSchema.getPartialFunctionEdgeByName(currentString)
+		newlhs[i], success = startingSchema.GetPartialFunctionEdgeByName(currentString)
 		if !success {
 			return false
 		}
 	}
 	for i, currentString := range rhsToBe {
-		newrhs[i], success = startingSchema.getPartialFunctionEdgeByName(currentString)
+		newrhs[i], success = startingSchema.GetPartialFunctionEdgeByName(currentString)
 		if !success {
 			return false
 		}
 	}
-	return startingSchema.addPartialFunctionEquation(PossiblyPartialFunctionEquation{lhs: newlhs, rhs: newrhs, identifier: identifierToBe})
+	return startingSchema.AddPartialFunctionEquation(PossiblyPartialFunctionEquation{lhs: newlhs, rhs: newrhs, identifier: identifierToBe, equality: equality})
 }
 
 //all the edges in both lhs and rhs must be in the graph already
 //they must both be valid paths
 //they must share source and target
 // does not check if this equation is already there
-func (startingSchema *SchemaGraph) addRelationEquation(equation PossiblyRelationEquation) bool {
+func (startingSchema *SchemaGraph) AddRelationEquation(equation PossiblyRelationEquation) bool {
 	myPresentEdges := presentEdgesF(startingSchema.functionEdges)
 	myPresentEdges = addPresentEdgesPF(myPresentEdges, startingSchema.partialFunctionEdges)
 	myPresentEdges = addPresentEdgesR(myPresentEdges, startingSchema.relationEdges)
@@ -720,27 +963,38 @@ func (startingSchema *SchemaGraph) addRelationEquation(equation PossiblyRelation
 }
 
 // does not check if this equation is already there
-func (startingSchema *SchemaGraph) addRelationEquation2(lhsToBe []string, rhsToBe []string, identifierToBe string) bool {
+// the equation is imposed with SetEquality
+func (startingSchema *SchemaGraph) AddRelationEquation2(lhsToBe []string, rhsToBe []string, identifierToBe string) bool {
+	return startingSchema.addRelationEquation2(lhsToBe, rhsToBe, identifierToBe, SetEquality)
+}
+
+// does not check if this equation is already there
+// only lhs contained in rhs is imposed
+func (startingSchema *SchemaGraph) AddRelationInclusion2(lhsToBe []string, rhsToBe []string, identifierToBe string) bool {
+	return startingSchema.addRelationEquation2(lhsToBe, rhsToBe, identifierToBe, SetInclusion)
+}
+
+func (startingSchema *SchemaGraph) addRelationEquation2(lhsToBe []string, rhsToBe []string, identifierToBe string, comparison RelationComparison) bool {
 	newlhs := make([]PossiblyRelationEdge, len(lhsToBe))
 	newrhs := make([]PossiblyRelationEdge, len(rhsToBe))
 	var success bool
 	for i, currentString := range lhsToBe {
-		newlhs[i], success = startingSchema.getRelationEdgeByName(currentString)
+		newlhs[i], success = startingSchema.GetRelationEdgeByName(currentString)
 		if !success {
 			return false
 		}
 	}
 	for i, currentString := range rhsToBe {
-		newrhs[i], success = startingSchema.getRelationEdgeByName(currentString)
+		newrhs[i], success = startingSchema.GetRelationEdgeByName(currentString)
 		if !success {
 			return false
 		}
 	}
-	return startingSchema.addRelationEquation(PossiblyRelationEquation{lhs: newlhs, rhs: newrhs, identifier: identifierToBe})
+	return startingSchema.AddRelationEquation(PossiblyRelationEquation{lhs: newlhs, rhs: newrhs, identifier: identifierToBe, comparison: comparison})
 }
 
 // nothing goes wrong with validation
-func (startingSchema *SchemaGraph) removeFunctionEquation(toRemove string) int {
+func (startingSchema *SchemaGraph) RemoveFunctionEquation(toRemove string) int {
 	indexRemove := make([]int, 0)
 	for i, eq := range startingSchema.functionEquations {
 		if eq.GetIdentifier() == toRemove {
@@ -754,7 +1008,7 @@ func (startingSchema *SchemaGraph) removeFunctionEquation(toRemove string) int {
 }
 
 // nothing goes wrong with validation
-func (startingSchema *SchemaGraph) removePartialFunctionEquation(toRemove string) int {
+func (startingSchema *SchemaGraph) RemovePartialFunctionEquation(toRemove string) int {
 	indexRemove := make([]int, 0)
 	for i, eq := range startingSchema.partialFunctionEquations {
 		if eq.GetIdentifier() == toRemove {
@@ -768,7 +1022,7 @@ func (startingSchema *SchemaGraph) removePartialFunctionEquation(toRemove string
 }
 
 // nothing goes wrong with validation
-func (startingSchema *SchemaGraph) removeRelationEquation(toRemove string) int {
+func (startingSchema *SchemaGraph) RemoveRelationEquation(toRemove string) int {
 	indexRemove := make([]int, 0)
 	for i, eq := range startingSchema.relationEquations {
 		if eq.GetIdentifier() == toRemove {
@@ -781,8 +1035,34 @@ func (startingSchema *SchemaGraph) removeRelationEquation(toRemove string) int {
 	return len(indexRemove)
 }
 
+// how many of each part of the schema a removal took out
+type RemovalCounts struct {
+	Vertices                 int
+	FunctionEdges            int
+	PartialFunctionEdges     int
+	RelationEdges            int
+	AttributeEdges           int
+	FunctionEquations        int
+	PartialFunctionEquations int
+	RelationEquations        int
+	AttributeEquations       int
+}
+
+func (counts *RemovalCounts) add(more RemovalCounts) {
+	counts.Vertices = counts.Vertices + more.Vertices
+	counts.FunctionEdges = counts.FunctionEdges + more.FunctionEdges
+	counts.PartialFunctionEdges = counts.PartialFunctionEdges + more.PartialFunctionEdges
+	counts.RelationEdges = counts.RelationEdges + more.RelationEdges
+	counts.AttributeEdges = counts.AttributeEdges + more.AttributeEdges
+	counts.FunctionEquations = counts.FunctionEquations + more.FunctionEquations
+	counts.PartialFunctionEquations = counts.PartialFunctionEquations + more.PartialFunctionEquations
+	counts.RelationEquations = counts.RelationEquations + more.RelationEquations
+	counts.AttributeEquations = counts.AttributeEquations + more.AttributeEquations
+}
+
 // when an edge gets removed all the equations that use it get removed as well
-func (startingSchema *SchemaGraph) removeFunctionEdge(toRemove string) (int, int, int, int) {
+// attribute equations count as using it when it appears in one of their paths
+func (startingSchema *SchemaGraph) RemoveFunctionEdge(toRemove string) RemovalCounts {
 	functionEquationsToRemove := make([]string, 0)
 	partialfunctionEquationsToRemove := make([]string, 0)
 	relationEquationsToRemove := make([]string, 0)
@@ -795,7 +1075,7 @@ func (startingSchema *SchemaGraph) removeFunctionEdge(toRemove string) (int, int
 		}
 	}
 	for _, eqName := range functionEquationsToRemove {
-		functionEquationsRemoved = functionEquationsRemoved + startingSchema.removeFunctionEquation(eqName)
+		functionEquationsRemoved = functionEquationsRemoved + startingSchema.RemoveFunctionEquation(eqName)
 	}
 	for _, eq := range startingSchema.partialFunctionEquations {
 		if eq.Contains(toRemove) {
@@ -803,7 +1083,7 @@ func (startingSchema *SchemaGraph) removeFunctionEdge(toRemove string) (int, int
 		}
 	}
 	for _, eqName := range partialfunctionEquationsToRemove {
-		partialFunctionEquationsRemoved = partialFunctionEquationsRemoved + startingSchema.removePartialFunctionEquation(eqName)
+		partialFunctionEquationsRemoved = partialFunctionEquationsRemoved + startingSchema.RemovePartialFunctionEquation(eqName)
 	}
 	for _, eq := range startingSchema.relationEquations {
 		if eq.Contains(toRemove) {
@@ -811,8 +1091,9 @@ func (startingSchema *SchemaGraph) removeFunctionEdge(toRemove string) (int, int
 		}
 	}
 	for _, eqName := range relationEquationsToRemove {
-		relationEquationsRemoved = relationEquationsRemoved + startingSchema.removeRelationEquation(eqName)
+		relationEquationsRemoved = relationEquationsRemoved + startingSchema.RemoveRelationEquation(eqName)
 	}
+	attributeEquationsRemoved := startingSchema.removeAttributeEquationsContaining(toRemove)
 	indexRemove := make([]int, 0)
 	for i, currentEdge := range startingSchema.functionEdges {
 		if currentEdge.GetIdentifier() == toRemove {
@@ -822,10 +1103,12 @@ func (startingSchema *SchemaGraph) removeFunctionEdge(toRemove string) (int, int
 	for _, i := range indexRemove {
 		startingSchema.functionEdges = append(startingSchema.functionEdges[:i], startingSchema.functionEdges[i+1:]...)
 	}
-	return functionEquationsRemoved, partialFunctionEquationsRemoved, relationEquationsRemoved, len(indexRemove)
+	delete(startingSchema.deleteActions, toRemove)
+	return RemovalCounts{FunctionEdges: len(indexRemove), FunctionEquations: functionEquationsRemoved,
+		PartialFunctionEquations: partialFunctionEquationsRemoved, RelationEquations: relationEquationsRemoved, AttributeEquations: attributeEquationsRemoved}
 }
 
-func (startingSchema *SchemaGraph) removePartialFunctionEdge(toRemove string) (int, int, int) {
+func (startingSchema *SchemaGraph) RemovePartialFunctionEdge(toRemove string) RemovalCounts {
 	partialfunctionEquationsToRemove := make([]string, 0)
 	relationEquationsToRemove := make([]string, 0)
 	partialFunctionEquationsRemoved := 0
@@ -836,7 +1119,7 @@ func (startingSchema *SchemaGraph) removePartialFunctionEdge(toRemove string) (i
 		}
 	}
 	for _, eqName := range partialfunctionEquationsToRemove {
-		partialFunctionEquationsRemoved = partialFunctionEquationsRemoved + startingSchema.removePartialFunctionEquation(eqName)
+		partialFunctionEquationsRemoved = partialFunctionEquationsRemoved + startingSchema.RemovePartialFunctionEquation(eqName)
 	}
 	for _, eq := range startingSchema.relationEquations {
 		if eq.Contains(toRemove) {
@@ -844,7 +1127,7 @@ func (startingSchema *SchemaGraph) removePartialFunctionEdge(toRemove string) (i
 		}
 	}
 	for _, eqName := range relationEquationsToRemove {
-		relationEquationsRemoved = relationEquationsRemoved + startingSchema.removeRelationEquation(eqName)
+		relationEquationsRemoved = relationEquationsRemoved + startingSchema.RemoveRelationEquation(eqName)
 	}
 	indexRemove := make([]int, 0)
 	for i, currentEdge := range startingSchema.partialFunctionEdges {
@@ -855,10 +1138,12 @@ func (startingSchema *SchemaGraph) removePartialFunctionEdge(toRemove string) (i
 	for _, i := range indexRemove {
 		startingSchema.partialFunctionEdges = append(startingSchema.partialFunctionEdges[:i], startingSchema.partialFunctionEdges[i+1:]...)
 	}
-	return partialFunctionEquationsRemoved, relationEquationsRemoved, len(indexRemove)
+	delete(startingSchema.deleteActions, toRemove)
+	return RemovalCounts{PartialFunctionEdges: len(indexRemove), PartialFunctionEquations: partialFunctionEquationsRemoved,
+		RelationEquations: relationEquationsRemoved}
 }
 
-func (startingSchema *SchemaGraph) removeRelationEdge(toRemove string) (int, int) {
+func (startingSchema *SchemaGraph) RemoveRelationEdge(toRemove string) RemovalCounts {
 	relationEquationsToRemove := make([]string, 0)
 	relationEquationsRemoved := 0
 	for _, eq := range startingSchema.relationEquations {
@@ -867,50 +1152,42 @@ func (startingSchema *SchemaGraph) removeRelationEdge(toRemove string) (int, int
 		}
 	}
 	for _, eqName := range relationEquationsToRemove {
-		relationEquationsRemoved = relationEquationsRemoved + startingSchema.removeRelationEquation(eqName)
+		relationEquationsRemoved = relationEquationsRemoved + startingSchema.RemoveRelationEquation(eqName)
 	}
 	indexRemove := make([]int, 0)
-	for i, currentEdge := range startingSchema.partialFunctionEdges {
+	for i, currentEdge := range startingSchema.relationEdges {
 		if currentEdge.GetIdentifier() == toRemove {
 			indexRemove = append(indexRemove, i)
 		}
 	}
 	for _, i := range indexRemove {
-		startingSchema.partialFunctionEdges = append(startingSchema.partialFunctionEdges[:i], startingSchema.partialFunctionEdges[i+1:]...)
+		startingSchema.relationEdges = append(startingSchema.relationEdges[:i], startingSchema.relationEdges[i+1:]...)
 	}
-	return relationEquationsRemoved, len(indexRemove)
+	return RemovalCounts{RelationEdges: len(indexRemove), RelationEquations: relationEquationsRemoved}
 }
 
 //every edge incident on this must be removed
-func (startingSchema *SchemaGraph) deleteVertex(toRemove string) (int, int, int, int, int, int, int) {
-	functionEdgesRemoved := 0
-	partialFunctionEdgesRemoved := 0
-	relationEdgesRemoved := 0
-	functionEqsRemoved := 0
-	partialFunctionEqsRemoved := 0
-	relationEqsRemoved := 0
-	for _, currentEdge := range startingSchema.functionEdges {
+// the loops go over copies of the edge lists since removing an edge shifts the rest of its list down
+func (startingSchema *SchemaGraph) DeleteVertex(toRemove string) RemovalCounts {
+	var toReturn RemovalCounts
+	for _, currentEdge := range append([]FunctionEdge{}, startingSchema.functionEdges...) {
 		if currentEdge.Contains(toRemove) {
-			a, b, c, d := startingSchema.removeFunctionEdge(currentEdge.GetIdentifier())
-			functionEqsRemoved = functionEqsRemoved + a
-			partialFunctionEqsRemoved = partialFunctionEqsRemoved + b
-			relationEqsRemoved = relationEqsRemoved + c
-			functionEdgesRemoved = functionEdgesRemoved + d
+			toReturn.add(startingSchema.RemoveFunctionEdge(currentEdge.GetIdentifier()))
 		}
 	}
-	for _, currentEdge := range startingSchema.partialFunctionEdges {
+	for _, currentEdge := range append([]PartialFunctionEdge{}, startingSchema.partialFunctionEdges...) {
 		if currentEdge.Contains(toRemove) {
-			b, c, d := startingSchema.removePartialFunctionEdge(currentEdge.GetIdentifier())
-			partialFunctionEqsRemoved = partialFunctionEqsRemoved + b
-			relationEqsRemoved = relationEqsRemoved + c
-			partialFunctionEdgesRemoved = partialFunctionEdgesRemoved + d
+			toReturn.add(startingSchema.RemovePartialFunctionEdge(currentEdge.GetIdentifier()))
 		}
 	}
-	for _, currentEdge := range startingSchema.relationEdges {
+	for _, currentEdge := range append([]RelationEdge{}, startingSchema.relationEdges...) {
+		if currentEdge.Contains(toRemove) {
+			toReturn.add(startingSchema.RemoveRelationEdge(currentEdge.GetIdentifier()))
+		}
+	}
+	for _, currentEdge := range append([]AttributeEdge{}, startingSchema.attributeEdges...) {
 		if currentEdge.Contains(toRemove) {
-			c, d := startingSchema.removeRelationEdge(currentEdge.GetIdentifier())
-			relationEqsRemoved = relationEqsRemoved + c
-			relationEdgesRemoved = relationEdgesRemoved + d
+			toReturn.add(startingSchema.RemoveAttributeEdge(currentEdge.GetIdentifier()))
 		}
 	}
 	indexRemove := make([]int, 0)
@@ -922,33 +1199,34 @@ func (startingSchema *SchemaGraph) deleteVertex(toRemove string) (int, int, int,
 	for _, i := range indexRemove {
 		startingSchema.vertices = append(startingSchema.vertices[:i], startingSchema.vertices[i+1:]...)
 	}
-	return functionEqsRemoved, partialFunctionEqsRemoved, relationEqsRemoved, functionEdgesRemoved, partialFunctionEdgesRemoved, relationEdgesRemoved, len(indexRemove)
+	toReturn.Vertices = len(indexRemove)
+	return toReturn
 }
 
 func testCase() {
-	var exampleGraph SchemaGraph = emptySchemaGraph()
-	//exampleGraph.addVertex(Vertex{identifier: "Employee"})
-	//exampleGraph.addVertex(Vertex{identifier: "Department"})
-	//exampleGraph.addVertex(Vertex{identifier: "PeopleNames"})
-	//exampleGraph.addVertex(Vertex{identifier: "DeptNames"})
-	exampleGraph.addVertex2("Employee")
-	exampleGraph.addVertex2("Department")
-	exampleGraph.addVertex2("PeopleNames")
-	exampleGraph.addVertex2("DeptNames")
-	//exampleGraph.addFunctionEdge(Vertex{identifier: "Employee"}, Vertex{identifier: "Employee"}, "manager")
-	//exampleGraph.addPartialFunctionEdge(Vertex{identifier: "Employee"}, Vertex{identifier: "Department"}, "worksIn")
-	//exampleGraph.addFunctionEdge(Vertex{identifier: "Department"}, Vertex{identifier: "Employee"}, "secretary")
-	//exampleGraph.addFunctionEdge(Vertex{identifier: "Department"}, Vertex{identifier: "DeptNames"}, "dept name")
-	//exampleGraph.addFunctionEdge(Vertex{identifier: "Employee"}, Vertex{identifier: "PeopleNames"}, "first name")
-	//exampleGraph.addFunctionEdge(Vertex{identifier: "Employee"}, Vertex{identifier: "PeopleNames"}, "last name")
-	exampleGraph.addFunctionEdge2("Employee", "Employee", "manager")
-	exampleGraph.addPartialFunctionEdge2("Employee", "Department", "worksIn")
-	exampleGraph.addFunctionEdge2("Department", "Employee", "secretary")
-	exampleGraph.addFunctionEdge2("Department", "DeptNames", "dept name")
-	exampleGraph.addFunctionEdge2("Employee", "PeopleNames", "first name")
-	exampleGraph.addFunctionEdge2("Employee", "PeopleNames", "last name")
+	var exampleGraph SchemaGraph = EmptySchemaGraph()
+	//exampleGraph.AddVertex(Vertex{identifier: "Employee"})
+	//exampleGraph.AddVertex(Vertex{identifier: "Department"})
+	//exampleGraph.AddVertex(Vertex{identifier: "PeopleNames"})
+	//exampleGraph.AddVertex(Vertex{identifier: "DeptNames"})
+	exampleGraph.AddVertex2("Employee")
+	exampleGraph.AddVertex2("Department")
+	//exampleGraph.AddFunctionEdge(Vertex{identifier: "Employee"}, Vertex{identifier: "Employee"}, "manager")
+	//exampleGraph.AddPartialFunctionEdge(Vertex{identifier: "Employee"}, Vertex{identifier: "Department"}, "worksIn")
+	//exampleGraph.AddFunctionEdge(Vertex{identifier: "Department"}, Vertex{identifier: "Employee"}, "secretary")
+	//exampleGraph.AddFunctionEdge(Vertex{identifier: "Department"}, Vertex{identifier: "DeptNames"}, "dept name")
+	//exampleGraph.AddFunctionEdge(Vertex{identifier: "Employee"}, Vertex{identifier: "PeopleNames"}, "first name")
+	//exampleGraph.AddFunctionEdge(Vertex{identifier: "Employee"}, Vertex{identifier: "PeopleNames"}, "last name")
+	exampleGraph.AddFunctionEdge2("Employee", "Employee", "manager")
+	exampleGraph.AddPartialFunctionEdge2("Employee", "Department", "worksIn")
+	exampleGraph.AddFunctionEdge2("Department", "Employee", "secretary")
+	exampleGraph.AddAttributeEdge2("Department", StringSort, "dept name")
+	exampleGraph.AddAttributeEdge2("Employee", StringSort, "first name")
+	exampleGraph.AddAttributeEdge2("Employee", StringSort, "last name")
+	exampleGraph.AddAttributeEquation2([]string{"manager"}, "last name", []string{}, "last name", "managers are family")
 	lhsToBe := []string{"secretary", "worksIn"}
 	rhsToBe := []string{}
-	exampleGraph.addPartialFunctionEquation2(lhsToBe, rhsToBe, "secretaries work in the correct department")
-	exampleGraph.displayInfo()
+	// strict so that every secretary has to work in some department, namely their own
+	exampleGraph.AddPartialFunctionEquation2(lhsToBe, rhsToBe, "secretaries work in the correct department")
+	exampleGraph.DisplayInfo()
 }
@@ -0,0 +1,33 @@
+package coloredGraphSchema
+
+import "testing"
+
+// people in departments with heads who work there, named after their department
+func headedSchema(t *testing.T) SchemaGraph {
+	t.Helper()
+	schema := peopleSchema(t)
+	if !schema.AddFunctionEdge2("Department", "Person", "head") ||
+		!schema.AddFunctionEquation2([]string{"head", "worksIn"}, []string{}, "heads work there") ||
+		!schema.AddAttributeEquation2([]string{"worksIn"}, "title", []string{}, "name", "named after the department") {
+		t.Fatal("could not add the heads")
+	}
+	return schema
+}
+
+func TestRemovalCounts(t *testing.T) {
+	schema := headedSchema(t)
+	if got, want := schema.RemoveFunctionEdge("worksIn"), (RemovalCounts{FunctionEdges: 1, FunctionEquations: 1, AttributeEquations: 1}); got != want {
+		t.Errorf("removing worksIn took out %+v, want %+v", got, want)
+	}
+	if got, want := schema.RemoveFunctionEdge("worksIn"), (RemovalCounts{}); got != want {
+		t.Errorf("removing worksIn again took out %+v", got)
+	}
+	schema = headedSchema(t)
+	want := RemovalCounts{Vertices: 1, FunctionEdges: 2, AttributeEdges: 2, FunctionEquations: 1, AttributeEquations: 1}
+	if got := schema.DeleteVertex("Department"); got != want {
+		t.Errorf("deleting Department took out %+v, want %+v", got, want)
+	}
+	if len(schema.GetVertices()) != 1 || len(schema.GetAttributeEdges()) != 2 {
+		t.Errorf("left %v and attributes %v, want Person with name and age", schema.GetVertices(), schema.GetAttributeEdges())
+	}
+}
@@ -0,0 +1,69 @@
+package coloredGraphSchema
+
+// what happens to an element x of the source of a function or partial function edge
+// when the element f(x) of the target is deleted, like the ON DELETE of a SQL foreign key
+// relation edges need no action, pairs involving a deleted element are just dropped
+type DeleteAction int
+
+const (
+	// the delete is refused while anything still points at the element
+	Restrict DeleteAction = iota
+	// x is deleted as well
+	Cascade
+	// x is kept but f becomes undefined on it, only for partial function edges
+	SetUndefined
+)
+
+func (action DeleteAction) String() string {
+	switch action {
+	case Restrict:
+		return "restrict"
+	case Cascade:
+		return "cascade"
+	case SetUndefined:
+		return "set_undefined"
+	}
+	return "unknown action"
+}
+
+// second return is false if name is not one of the strings given by DeleteAction.String
+func DeleteActionByName(name string) (DeleteAction, bool) {
+	for _, action := range []DeleteAction{Restrict, Cascade, SetUndefined} {
+		if action.String() == name {
+			return action, true
+		}
+	}
+	return Restrict, false
+}
+
+func copyDeleteActions(actions map[string]DeleteAction) map[string]DeleteAction {
+	toReturn := make(map[string]DeleteAction, len(actions))
+	for k, v := range actions {
+		toReturn[k] = v
+	}
+	return toReturn
+}
+
+// false if there is no function or partial function edge with that name
+// or if the action is SetUndefined and the edge is a function edge
+func (startingSchema *SchemaGraph) SetDeleteAction(edgeName string, action DeleteAction) bool {
+	_, isFunction := startingSchema.GetFunctionEdgeByName(edgeName)
+	_, isPartial := startingSchema.GetDefPartialFunctionEdgeByName(edgeName)
+	if !(isFunction || isPartial) || (isFunction && action == SetUndefined) {
+		return false
+	}
+	if startingSchema.deleteActions == nil {
+		startingSchema.deleteActions = make(map[string]DeleteAction)
+	}
+	if action == Restrict {
+		delete(startingSchema.deleteActions, edgeName)
+	} else {
+		startingSchema.deleteActions[edgeName] = action
+	}
+	return true
+}
+
+// Restrict unless something else was set for that edge
+func (potentialSchema *SchemaGraph) GetDeleteAction(edgeName string) DeleteAction {
+	return potentialSchema.deleteActions[edgeName]
+}
@@ -0,0 +1,414 @@
+package coloredGraphSchema
+
+import (
+	"fmt"
+	"maps"
+)
+
+// a mapping F from the schema source to the schema target
+// every vertex goes to a vertex and every edge to a path of the same kind between the images of its ends
+//   - a function edge to a path of function edges
+//   - a partial function edge to a path of function and partial function edges
+//   - a relation edge to a path of any edges but attributes
+//   - an attribute to a path of function edges followed by an attribute of the same sort
+//
+// and every equation of source is sent to one that follows from the equations of target
+// an empty path is the identity, so an edge may be sent to it when both its ends go to the same vertex
+type Mapping struct {
+	source           SchemaGraph
+	target           SchemaGraph
+	vertexImages     map[string]Vertex
+	functionImages   map[string][]FunctionEdge
+	partialImages    map[string][]PossiblyPartialFunctionEdge
+	relationImages   map[string][]PossiblyRelationEdge
+	attributeImages  map[string][]FunctionEdge
+	attributeTargets map[string]AttributeEdge
+}
+
+// vertices and edges are by name, for an attribute the last name is the attribute of target it ends in
+// the error is a SchemaProblems naming the vertices, edges and equations of source that were not mapped properly
+func NewMapping(source SchemaGraph, target SchemaGraph, vertices map[string]string, edges map[string][]string) (Mapping, error) {
+	toReturn := emptyMapping(source, target)
+	problems := make(SchemaProblems, 0)
+	for _, v := range source.vertices {
+		name, present := vertices[v.identifier]
+		if !present {
+			problems = append(problems, SchemaProblem{Vertex: v.identifier, Reason: "not mapped to anything"})
+			continue
+		}
+		if !vertexInVertices2(name, target.vertices) {
+			problems = append(problems, SchemaProblem{Vertex: v.identifier, Reason: "mapped to " + name + " which is not a vertex of the target"})
+			continue
+		}
+		toReturn.vertexImages[v.identifier] = Vertex{identifier: name}
+	}
+	for name := range vertices {
+		if !vertexInVertices2(name, source.vertices) {
+			problems = append(problems, SchemaProblem{Vertex: name, Reason: "not a vertex of the source"})
+		}
+	}
+	if len(problems) > 0 {
+		// without every vertex image the edges cannot be checked
+		return Mapping{}, problems
+	}
+	for _, edge := range source.functionEdges {
+		path, edgeProblems := mappedPath(edges, edge, toReturn.vertexImages, "function edges", target.GetFunctionEdgeByName)
+		toReturn.functionImages[edge.identifier] = path
+		problems = append(problems, edgeProblems...)
+	}
+	for _, edge := range source.partialFunctionEdges {
+		path, edgeProblems := mappedPath(edges, edge, toReturn.vertexImages, "function and partial function edges", target.GetPartialFunctionEdgeByName)
+		toReturn.partialImages[edge.identifier] = path
+		problems = append(problems, edgeProblems...)
+	}
+	for _, edge := range source.relationEdges {
+		path, edgeProblems := mappedPath(edges, edge, toReturn.vertexImages, "edges other than attributes", target.GetRelationEdgeByName)
+		toReturn.relationImages[edge.identifier] = path
+		problems = append(problems, edgeProblems...)
+	}
+	for _, attribute := range source.attributeEdges {
+		problems = append(problems, toReturn.mapAttribute(attribute, edges[attribute.identifier])...)
+	}
+	for name := range edges {
+		if !source.HasEdgeNamed(name) {
+			problems = append(problems, SchemaProblem{Edge: name, Reason: "not an edge of the source"})
+		}
+	}
+	if len(problems) > 0 {
+		return Mapping{}, problems
+	}
+	problems = append(problems, toReturn.unprovedEquations()...)
+	if len(problems) > 0 {
+		return Mapping{}, problems
+	}
+	return toReturn, nil
+}
+
+// a mapping from source to target with no images yet
+func emptyMapping(source SchemaGraph, target SchemaGraph) Mapping {
+	return Mapping{source: source.Copy(), target: target.Copy(), vertexImages: make(map[string]Vertex),
+		functionImages: make(map[string][]FunctionEdge), partialImages: make(map[string][]PossiblyPartialFunctionEdge),
+		relationImages: make(map[string][]PossiblyRelationEdge), attributeImages: make(map[string][]FunctionEdge),
+		attributeTargets: make(map[string]AttributeEdge)}
+}
+
+// looks up the image of edge in target and checks it runs between the images of its ends
+func mappedPath[E UnspecifiedEdge](edges map[string][]string, edge UnspecifiedEdge, vertexImages map[string]Vertex, allowed string, lookup func(string) (E, bool)) ([]E, []SchemaProblem) {
+	names, present := edges[edge.GetIdentifier()]
+	if !present {
+		return nil, []SchemaProblem{{Edge: edge.GetIdentifier(), Reason: "not mapped to anything"}}
+	}
+	toReturn := make([]E, 0, len(names))
+	for _, name := range names {
+		image, found := lookup(name)
+		if !found {
+			return nil, []SchemaProblem{{Edge: edge.GetIdentifier(), Reason: "mapped through " + name + " but may only be mapped to a path of " + allowed}}
+		}
+		toReturn = append(toReturn, image)
+	}
+	if !connects(toReturn, vertexImages[edge.GetSource().identifier], vertexImages[edge.GetTarget().identifier]) {
+		return nil, []SchemaProblem{{Edge: edge.GetIdentifier(), Reason: "mapped to a path that does not run between the images of its source and target"}}
+	}
+	return toReturn, nil
+}
+
+// true when path goes from start to end, the empty path only goes from a vertex to itself
+func connects[E UnspecifiedEdge](path []E, start Vertex, end Vertex) bool {
+	current := start
+	for _, edge := range path {
+		if edge.GetSource() != current {
+			return false
+		}
+		current = edge.GetTarget()
+	}
+	return current == end
+}
+
+func (potentialMapping *Mapping) mapAttribute(attribute AttributeEdge, names []string) []SchemaProblem {
+	if len(names) == 0 {
+		return []SchemaProblem{{Edge: attribute.identifier, Reason: "must be mapped to a path ending in an attribute"}}
+	}
+	path := make([]FunctionEdge, 0, len(names)-1)
+	for _, name := range names[:len(names)-1] {
+		edge, found := potentialMapping.target.GetFunctionEdgeByName(name)
+		if !found {
+			return []SchemaProblem{{Edge: attribute.identifier, Reason: "mapped through " + name + " but may only be mapped to a path of function edges before the attribute"}}
+		}
+		path = append(path, edge)
+	}
+	image, found := potentialMapping.target.GetAttributeEdgeByName(names[len(names)-1])
+	if !found {
+		return []SchemaProblem{{Edge: attribute.identifier, Reason: "mapped to a path ending in " + names[len(names)-1] + " which is not an attribute of the target"}}
+	}
+	if image.sort != attribute.sort {
+		return []SchemaProblem{{Edge: attribute.identifier, Reason: "mapped to " + image.identifier + " which has sort " + image.sort.String() + " not " + attribute.sort.String()}}
+	}
+	if !connects(path, potentialMapping.vertexImages[attribute.source.identifier], image.source) {
+		return []SchemaProblem{{Edge: attribute.identifier, Reason: "mapped to a path that does not run from the image of its source to the attribute"}}
+	}
+	potentialMapping.attributeImages[attribute.identifier] = path
+	potentialMapping.attributeTargets[attribute.identifier] = image
+	return nil
+}
+
+// the names of the path in target that a path of source is sent to
+func (potentialMapping *Mapping) imageNames(path []string) []string {
+	toReturn := make([]string, 0)
+	for _, name := range path {
+		if image, present := potentialMapping.functionImages[name]; present {
+			toReturn = append(toReturn, edgeNames(image)...)
+		} else if image, present := potentialMapping.partialImages[name]; present {
+			toReturn = append(toReturn, edgeNames(image)...)
+		} else if image, present := potentialMapping.relationImages[name]; present {
+			toReturn = append(toReturn, edgeNames(image)...)
+		} else {
+			toReturn = append(toReturn, edgeNames(potentialMapping.attributeImages[name])...)
+			toReturn = append(toReturn, potentialMapping.attributeTargets[name].identifier)
+		}
+	}
+	return toReturn
+}
+
+// where the image of an equation with these sides starts
+func (potentialMapping *Mapping) equationStart(lhs []PossiblyRelationEdge, rhs []PossiblyRelationEdge) Vertex {
+	if len(lhs) > 0 {
+		return potentialMapping.vertexImages[lhs[0].GetSource().identifier]
+	}
+	if len(rhs) > 0 {
+		return potentialMapping.vertexImages[rhs[0].GetSource().identifier]
+	}
+	return Vertex{}
+}
+
+func unproved(equationName string) SchemaProblem {
+	return SchemaProblem{Equation: equationName, Reason: "could not be proved from the equations of the target"}
+}
+
+func (potentialMapping *Mapping) unprovedEquations() []SchemaProblem {
+	toReturn := make([]SchemaProblem, 0)
+	target := &potentialMapping.target
+	strict := newPathProver(target).useStrictEquations(target)
+	for _, eq := range potentialMapping.source.functionEquations {
+		start := potentialMapping.equationStart(eq.GetLHS(), eq.GetRHS())
+		if !strict.proves(start, potentialMapping.imageNames(edgeNames(eq.lhs)), potentialMapping.imageNames(edgeNames(eq.rhs))) {
+			toReturn = append(toReturn, unproved(eq.identifier))
+		}
+	}
+	kleene := make([]rewriteRule, 0)
+	for _, eq := range target.partialFunctionEquations {
+		if eq.equality == KleeneEquality {
+			kleene = append(kleene, equationRule(eq.lhs, eq.rhs))
+		}
+	}
+	for _, eq := range potentialMapping.source.partialFunctionEquations {
+		start := potentialMapping.equationStart(eq.GetLHS(), eq.GetRHS())
+		lhs, rhs := potentialMapping.imageNames(edgeNames(eq.lhs)), potentialMapping.imageNames(edgeNames(eq.rhs))
+		proved := false
+		if eq.equality == KleeneEquality {
+			proved = strict.provesKleene(start, lhs, rhs, kleene)
+		} else {
+			proved = strict.proves(start, lhs, rhs)
+		}
+		if !proved {
+			toReturn = append(toReturn, unproved(eq.identifier))
+		}
+	}
+	relational := newPathProver(target).useStrictEquations(target).useRelationEquations(target)
+	for _, eq := range potentialMapping.source.relationEquations {
+		start := potentialMapping.equationStart(eq.lhs, eq.rhs)
+		lhs, rhs := potentialMapping.imageNames(edgeNames(eq.lhs)), potentialMapping.imageNames(edgeNames(eq.rhs))
+		proved := relational.proves(start, lhs, rhs)
+		if eq.comparison == SetEquality {
+			proved = proved && relational.proves(start, rhs, lhs)
+		}
+		if !proved {
+			toReturn = append(toReturn, unproved(eq.identifier))
+		}
+	}
+	withAttributes := newPathProver(target).useStrictEquations(target).useAttributeEquations(target)
+	for _, eq := range potentialMapping.source.attributeEquations {
+		start := potentialMapping.vertexImages[eq.GetSource().identifier]
+		lhs := potentialMapping.imageNames(append(edgeNames(eq.lhsPath), eq.lhsAttribute.identifier))
+		rhs := potentialMapping.imageNames(append(edgeNames(eq.rhsPath), eq.rhsAttribute.identifier))
+		if !withAttributes.proves(start, lhs, rhs) {
+			toReturn = append(toReturn, unproved(eq.identifier))
+		}
+	}
+	return toReturn
+}
+
+// sends every vertex and edge of schema to itself
+// built directly rather than through NewMapping, as every equation is sent to itself and so holds
+func IdentityMapping(schema SchemaGraph) Mapping {
+	toReturn := emptyMapping(schema, schema)
+	for _, v := range schema.vertices {
+		toReturn.vertexImages[v.identifier] = v
+	}
+	for _, edge := range schema.functionEdges {
+		toReturn.functionImages[edge.identifier] = []FunctionEdge{edge}
+	}
+	for _, edge := range schema.partialFunctionEdges {
+		toReturn.partialImages[edge.identifier] = []PossiblyPartialFunctionEdge{edge}
+	}
+	for _, edge := range schema.relationEdges {
+		toReturn.relationImages[edge.identifier] = []PossiblyRelationEdge{edge}
+	}
+	for _, attribute := range schema.attributeEdges {
+		toReturn.attributeImages[attribute.identifier] = []FunctionEdge{}
+		toReturn.attributeTargets[attribute.identifier] = attribute
+	}
+	return toReturn
+}
+
+// first this mapping then next, the target of this mapping must be the source of next
+// second return is false if it is not
+// the images are composed directly, an equation sent by this mapping to one that holds in the source of next
+// is sent by next to one that holds in its target, so nothing has to be proved again
+func (currentMapping Mapping) Compose(next Mapping) (Mapping, bool) {
+	if !SameSchema(currentMapping.target, next.source) {
+		return Mapping{}, false
+	}
+	target := next.target.Copy()
+	toReturn := Mapping{source: currentMapping.source.Copy(), target: target, vertexImages: make(map[string]Vertex),
+		functionImages: make(map[string][]FunctionEdge), partialImages: make(map[string][]PossiblyPartialFunctionEdge),
+		relationImages: make(map[string][]PossiblyRelationEdge), attributeImages: make(map[string][]FunctionEdge),
+		attributeTargets: make(map[string]AttributeEdge)}
+	for name, image := range currentMapping.vertexImages {
+		toReturn.vertexImages[name] = next.vertexImages[image.identifier]
+	}
+	composite := func(name string) []string {
+		return next.imageNames(currentMapping.imageNames([]string{name}))
+	}
+	for _, edge := range currentMapping.source.functionEdges {
+		toReturn.functionImages[edge.identifier] = lookupPath(composite(edge.identifier), target.GetFunctionEdgeByName)
+	}
+	for _, edge := range currentMapping.source.partialFunctionEdges {
+		toReturn.partialImages[edge.identifier] = lookupPath(composite(edge.identifier), target.GetPartialFunctionEdgeByName)
+	}
+	for _, edge := range currentMapping.source.relationEdges {
+		toReturn.relationImages[edge.identifier] = lookupPath(composite(edge.identifier), target.GetRelationEdgeByName)
+	}
+	for _, attribute := range currentMapping.source.attributeEdges {
+		names := composite(attribute.identifier)
+		toReturn.attributeImages[attribute.identifier] = lookupPath(names[:len(names)-1], target.GetFunctionEdgeByName)
+		toReturn.attributeTargets[attribute.identifier], _ = target.GetAttributeEdgeByName(names[len(names)-1])
+	}
+	return toReturn, true
+}
+
+// the edges called names, which are all known to be found by lookup
+func lookupPath[E UnspecifiedEdge](names []string, lookup func(string) (E, bool)) []E {
+	toReturn := make([]E, len(names))
+	for i, name := range names {
+		toReturn[i], _ = lookup(name)
+	}
+	return toReturn
+}
+
+// true when the two schemas have the same vertices, edges, equations and delete actions, in whatever order
+func SameSchema(this SchemaGraph, that SchemaGraph) bool {
+	return sameElements(this.vertices, that.vertices) && sameElements(this.functionEdges, that.functionEdges) &&
+		sameElements(this.partialFunctionEdges, that.partialFunctionEdges) && sameElements(this.relationEdges, that.relationEdges) &&
+		sameElements(this.attributeEdges, that.attributeEdges) && sameElements(this.equationKeys(), that.equationKeys()) &&
+		maps.Equal(this.deleteActions, that.deleteActions)
+}
+
+// the same elements as often in both, in whatever order
+func sameElements[T comparable](these []T, those []T) bool {
+	if len(these) != len(those) {
+		return false
+	}
+	counts := make(map[T]int, len(these))
+	for _, x := range these {
+		counts[x] = counts[x] + 1
+	}
+	for _, x := range those {
+		if counts[x] == 0 {
+			return false
+		}
+		counts[x] = counts[x] - 1
+	}
+	return true
+}
+
+// an equation by name, kind and sides, as edge names are unique that is all there is to it once the edges agree
+type equationKey struct {
+	identifier string
+	kind       string
+	lhs        string
+	rhs        string
+}
+
+func (potentialSchema *SchemaGraph) equationKeys() []equationKey {
+	toReturn := make([]equationKey, 0)
+	for _, eq := range potentialSchema.functionEquations {
+		toReturn = append(toReturn, equationKey{eq.identifier, "function", pathKey(edgeNames(eq.lhs)), pathKey(edgeNames(eq.rhs))})
+	}
+	for _, eq := range potentialSchema.partialFunctionEquations {
+		toReturn = append(toReturn, equationKey{eq.identifier, fmt.Sprint("partial ", eq.equality), pathKey(edgeNames(eq.lhs)), pathKey(edgeNames(eq.rhs))})
+	}
+	for _, eq := range potentialSchema.relationEquations {
+		toReturn = append(toReturn, equationKey{eq.identifier, fmt.Sprint("relation ", eq.comparison), pathKey(edgeNames(eq.lhs)), pathKey(edgeNames(eq.rhs))})
+	}
+	for _, eq := range potentialSchema.attributeEquations {
+		toReturn = append(toReturn, equationKey{eq.identifier, "attribute", pathKey(append(edgeNames(eq.lhsPath), eq.lhsAttribute.identifier)),
+			pathKey(append(edgeNames(eq.rhsPath), eq.rhsAttribute.identifier))})
+	}
+	return toReturn
+}
+
+// the names of the equations of these that those does not have, in schema order
+// an equation those has under the same name but with other sides, kind or equality counts as missing
+func EquationsMissing(these SchemaGraph, those SchemaGraph) []string {
+	kept := make(map[equationKey]bool)
+	for _, key := range those.equationKeys() {
+		kept[key] = true
+	}
+	toReturn := make([]string, 0)
+	for _, key := range these.equationKeys() {
+		if !kept[key] {
+			toReturn = append(toReturn, key.identifier)
+		}
+	}
+	return toReturn
+}
+
+func (currentMapping Mapping) GetSource() SchemaGraph {
+	return currentMapping.source.Copy()
+}
+
+func (currentMapping Mapping) GetTarget() SchemaGraph {
+	return currentMapping.target.Copy()
+}
+
+// second return is false if vertex is not in the source
+func (currentMapping Mapping) VertexImage(vertex Vertex) (Vertex, bool) {
+	toReturn, present := currentMapping.vertexImages[vertex.identifier]
+	return toReturn, present
+}
+
+func (currentMapping Mapping) FunctionEdgeImage(edge FunctionEdge) ([]FunctionEdge, bool) {
+	toReturn, present := currentMapping.functionImages[edge.identifier]
+	return append([]FunctionEdge{}, toReturn...), present
+}
+
+func (currentMapping Mapping) PartialFunctionEdgeImage(edge PartialFunctionEdge) ([]PossiblyPartialFunctionEdge, bool) {
+	toReturn, present := currentMapping.partialImages[edge.identifier]
+	return append([]PossiblyPartialFunctionEdge{}, toReturn...), present
+}
+
+func (currentMapping Mapping) RelationEdgeImage(edge RelationEdge) ([]PossiblyRelationEdge, bool) {
+	toReturn, present := currentMapping.relationImages[edge.identifier]
+	return append([]PossiblyRelationEdge{}, toReturn...), present
+}
+
+// the path of function edges and then the attribute of the target that edge is sent to
+func (currentMapping Mapping) AttributeEdgeImage(edge AttributeEdge) ([]FunctionEdge, AttributeEdge, bool) {
+	path, present := currentMapping.attributeImages[edge.identifier]
+	return append([]FunctionEdge{}, path...), currentMapping.attributeTargets[edge.identifier], present
+}
+
+// the names of the path in the target that a path of the source, given by edge names, is sent to
+func (currentMapping Mapping) PathImage(path []string) []string {
+	return currentMapping.imageNames(path)
+}
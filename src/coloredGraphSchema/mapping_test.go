@@ -0,0 +1,173 @@
+package coloredGraphSchema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func expectProblem(t *testing.T, err error, want SchemaProblem) {
+	t.Helper()
+	problems, isSchema := err.(SchemaProblems)
+	if !isSchema {
+		t.Fatalf("got %v, want %v", err, want)
+	}
+	for _, problem := range problems {
+		if problem == want {
+			return
+		}
+	}
+	t.Errorf("got %v, want %v", problems, want)
+}
+
+func TestNewMapping(t *testing.T) {
+	source := loops(t, "p q", "p.p = p")
+	target := loops(t, "f g", "f.f = f", "g.g = g")
+	// p.p = p is sent to f.g.f.g = f.g, which needs both equations of the target
+	mapping, err := NewMapping(source, target, map[string]string{"A": "A"}, map[string][]string{"p": {"f", "g"}, "q": {}})
+	if err == nil {
+		t.Fatal("f.g.f.g = f.g was proved but does not follow")
+	}
+	expectProblem(t, err, unproved("p.p = p"))
+	mapping, err = NewMapping(source, target, map[string]string{"A": "A"}, map[string][]string{"p": {"f", "f"}, "q": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image := mapping.PathImage([]string{"p", "q", "p"}); !reflect.DeepEqual(image, []string{"f", "f", "f", "f"}) {
+		t.Errorf("p.q.p is sent to %v, want f.f.f.f", image)
+	}
+}
+
+func TestNewMappingRejected(t *testing.T) {
+	source := EmptySchemaGraph()
+	target := EmptySchemaGraph()
+	if !source.AddVertex2("X") || !source.AddVertex2("Y") || !source.AddFunctionEdge2("X", "Y", "p") ||
+		!source.AddAttributeEdge2("X", IntSort, "size") {
+		t.Fatal("could not build the source")
+	}
+	if !target.AddVertex2("A") || !target.AddVertex2("B") || !target.AddFunctionEdge2("A", "B", "f") ||
+		!target.AddRelationEdge2("A", "B", "r") || !target.AddAttributeEdge2("A", StringSort, "label") {
+		t.Fatal("could not build the target")
+	}
+	vertices := map[string]string{"X": "A", "Y": "B"}
+	for _, example := range []struct {
+		vertices map[string]string
+		edges    map[string][]string
+		want     SchemaProblem
+	}{
+		{map[string]string{"X": "A"}, nil, SchemaProblem{Vertex: "Y", Reason: "not mapped to anything"}},
+		{map[string]string{"X": "A", "Y": "C"}, nil, SchemaProblem{Vertex: "Y", Reason: "mapped to C which is not a vertex of the target"}},
+		{vertices, map[string][]string{"size": {"label"}}, SchemaProblem{Edge: "p", Reason: "not mapped to anything"}},
+		{vertices, map[string][]string{"p": {"r"}, "size": {"label"}},
+			SchemaProblem{Edge: "p", Reason: "mapped through r but may only be mapped to a path of function edges"}},
+		{map[string]string{"X": "A", "Y": "A"}, map[string][]string{"p": {"f"}, "size": {"label"}},
+			SchemaProblem{Edge: "p", Reason: "mapped to a path that does not run between the images of its source and target"}},
+		{vertices, map[string][]string{"p": {"f"}, "size": {"label"}},
+			SchemaProblem{Edge: "size", Reason: "mapped to label which has sort string not int"}},
+		{vertices, map[string][]string{"p": {"f"}, "size": {"label"}, "q": {"f"}}, SchemaProblem{Edge: "q", Reason: "not an edge of the source"}},
+	} {
+		_, err := NewMapping(source, target, example.vertices, example.edges)
+		expectProblem(t, err, example.want)
+	}
+}
+
+// f = j does follow from the equations of the target, but only through a path longer than the search allows
+// so the mapping is rejected although it is one, see TestProvesLengthBound
+func TestNewMappingSearchBound(t *testing.T) {
+	target := loops(t, "f a b c d e g h i j", "f = a.b", "a = c.d", "b = e.g", "d.e = h", "c.h = i", "i.g = j")
+	_, err := NewMapping(loops(t, "p q", "p = q"), target, map[string]string{"A": "A"}, map[string][]string{"p": {"f"}, "q": {"j"}})
+	expectProblem(t, err, unproved("p = q"))
+}
+
+// the composite sends p = q to f = j, which NewMapping could not prove, but both halves are mappings so it is one
+func TestCompose(t *testing.T) {
+	middle := loops(t, "x y z", "x = y", "y = z")
+	first, err := NewMapping(loops(t, "p q", "p = q"), middle, map[string]string{"A": "A"}, map[string][]string{"p": {"x"}, "q": {"z"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := loops(t, "f a b c d e g h i j", "f = a.b", "a = c.d", "b = e.g", "d.e = h", "c.h = i", "i.g = j")
+	second, err := NewMapping(middle, target, map[string]string{"A": "A"}, map[string][]string{"x": {"f"}, "y": {"a", "b"}, "z": {"j"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	composite, composable := first.Compose(second)
+	if !composable {
+		t.Fatal("the mappings did not compose")
+	}
+	if image := composite.PathImage([]string{"p", "q"}); !reflect.DeepEqual(image, []string{"f", "j"}) {
+		t.Errorf("p.q is sent to %v, want f.j", image)
+	}
+	source := composite.GetSource()
+	edge, _ := source.GetFunctionEdgeByName("q")
+	if image, _ := composite.FunctionEdgeImage(edge); len(image) != 1 || image[0].GetIdentifier() != "j" {
+		t.Errorf("q is sent to %v, want j", image)
+	}
+	if _, composable := second.Compose(first); composable {
+		t.Error("composed mappings whose schemas do not meet")
+	}
+}
+
+func TestSameSchema(t *testing.T) {
+	this := loops(t, "f g", "f.f = f", "g.g = g")
+	that := loops(t, "g f", "g.g = g", "f.f = f")
+	thisJSON, _ := this.MarshalJSON()
+	thatJSON, _ := that.MarshalJSON()
+	if string(thisJSON) == string(thatJSON) {
+		t.Fatal("the order of the schemas does not show in their JSON")
+	}
+	if !SameSchema(this, that) {
+		t.Error("the same schema built in another order is not the same")
+	}
+	for _, other := range []SchemaGraph{loops(t, "f g", "f.f = f"), loops(t, "f g h", "f.f = f", "g.g = g"), loops(t, "f g", "f.f = f", "g.g.g = g")} {
+		if SameSchema(this, other) {
+			t.Errorf("%s is the same as %s", thisJSON, mustJSON(t, other))
+		}
+	}
+	withAction := this.Copy()
+	withAction.SetDeleteAction("f", Cascade)
+	if SameSchema(this, withAction) {
+		t.Error("a different delete action does not count")
+	}
+}
+
+func mustJSON(t *testing.T, schema SchemaGraph) string {
+	t.Helper()
+	text, err := schema.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(text)
+}
+
+// every kind of edge and equation is sent to itself without searching for proofs
+func TestIdentityMapping(t *testing.T) {
+	schema := peopleSchema(t)
+	for _, ok := range []bool{
+		schema.AddPartialFunctionEdge2("Person", "Person", "mentor"),
+		schema.AddRelationEdge2("Person", "Person", "knows"),
+		schema.AddKleenePartialFunctionEquation2([]string{"mentor", "worksIn"}, []string{"worksIn"}, "mentors work alongside"),
+		schema.AddRelationInclusion2([]string{"mentor"}, []string{"knows"}, "mentors are known"),
+		schema.AddAttributeEquation2([]string{"worksIn"}, "title", []string{}, "name", "named after the department"),
+	} {
+		if !ok {
+			t.Fatal("could not build the schema")
+		}
+	}
+	identity := IdentityMapping(schema)
+	vertices := map[string]string{"Person": "Person", "Department": "Department"}
+	edges := make(map[string][]string)
+	for _, name := range []string{"worksIn", "mentor", "knows", "name", "age", "title", "budget"} {
+		edges[name] = []string{name}
+	}
+	if proved, err := NewMapping(schema, schema, vertices, edges); err != nil || !reflect.DeepEqual(identity, proved) {
+		t.Errorf("the identity differs from the one NewMapping proves, %v", err)
+	}
+	if !SameSchema(identity.GetSource(), schema) || !SameSchema(identity.GetTarget(), schema) {
+		t.Error("the identity is not from the schema to itself")
+	}
+	for _, name := range []string{"worksIn", "mentor", "knows", "name"} {
+		if image := identity.PathImage([]string{name}); !reflect.DeepEqual(image, []string{name}) {
+			t.Errorf("%s is sent to %v", name, image)
+		}
+	}
+}
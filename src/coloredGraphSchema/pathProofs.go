@@ -0,0 +1,199 @@
+package coloredGraphSchema
+
+import "strings"
+
+// deciding whether two paths of a schema are equal by its equations is undecidable in general
+// so this searches for a proof by rewriting, replacing one side of an equation by the other
+// anywhere it occurs in a path, and gives up after maxProofStates paths
+// paths are lists of edge names, an attribute term is its path followed by the name of the attribute
+const maxProofStates = 5000
+
+type rewriteRule struct {
+	from []string
+	to   []string
+	// where an empty from may be matched, since the identity is everywhere but only typed at one vertex
+	source Vertex
+}
+
+type pathProver struct {
+	// the target of every edge by name, attributes have none
+	targets map[string]Vertex
+	// rules that may be used in both directions
+	equalities []rewriteRule
+	// rules that may only be used from lhs to rhs, for relation inclusions
+	inclusions []rewriteRule
+}
+
+func newPathProver(schema *SchemaGraph) *pathProver {
+	toReturn := &pathProver{targets: make(map[string]Vertex)}
+	for _, edge := range schema.functionEdges {
+		toReturn.targets[edge.identifier] = edge.target
+	}
+	for _, edge := range schema.partialFunctionEdges {
+		toReturn.targets[edge.identifier] = edge.target
+	}
+	for _, edge := range schema.relationEdges {
+		toReturn.targets[edge.identifier] = edge.target
+	}
+	return toReturn
+}
+
+func equationRule[E UnspecifiedEdge](lhs []E, rhs []E) rewriteRule {
+	var source Vertex
+	if len(lhs) > 0 {
+		source = lhs[0].GetSource()
+	} else if len(rhs) > 0 {
+		source = rhs[0].GetSource()
+	}
+	return rewriteRule{from: edgeNames(lhs), to: edgeNames(rhs), source: source}
+}
+
+// the equations that hold strictly, so may be used to prove any kind of equality
+func (prover *pathProver) useStrictEquations(schema *SchemaGraph) *pathProver {
+	for _, eq := range schema.functionEquations {
+		prover.equalities = append(prover.equalities, equationRule(eq.lhs, eq.rhs))
+	}
+	for _, eq := range schema.partialFunctionEquations {
+		if eq.equality == StrictEquality {
+			prover.equalities = append(prover.equalities, equationRule(eq.lhs, eq.rhs))
+		}
+	}
+	return prover
+}
+
+func (prover *pathProver) useRelationEquations(schema *SchemaGraph) *pathProver {
+	for _, eq := range schema.relationEquations {
+		if eq.comparison == SetInclusion {
+			prover.inclusions = append(prover.inclusions, equationRule(eq.lhs, eq.rhs))
+		} else {
+			prover.equalities = append(prover.equalities, equationRule(eq.lhs, eq.rhs))
+		}
+	}
+	return prover
+}
+
+func (prover *pathProver) useAttributeEquations(schema *SchemaGraph) *pathProver {
+	for _, eq := range schema.attributeEquations {
+		prover.equalities = append(prover.equalities, rewriteRule{from: append(edgeNames(eq.lhsPath), eq.lhsAttribute.identifier),
+			to: append(edgeNames(eq.rhsPath), eq.rhsAttribute.identifier), source: eq.GetSource()})
+	}
+	return prover
+}
+
+func pathKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+// the vertex reached after the first i edges of a path from start
+func (prover *pathProver) vertexAt(start Vertex, path []string, i int) Vertex {
+	if i == 0 {
+		return start
+	}
+	return prover.targets[path[i-1]]
+}
+
+// every path got by one use of rule on path
+func (prover *pathProver) rewrites(start Vertex, path []string, rule rewriteRule) [][]string {
+	toReturn := make([][]string, 0)
+	for i := 0; i+len(rule.from) <= len(path); i++ {
+		if len(rule.from) == 0 && prover.vertexAt(start, path, i) != rule.source {
+			continue
+		}
+		matches := true
+		for j, name := range rule.from {
+			if path[i+j] != name {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			rewritten := append(append(append(make([]string, 0, len(path)-len(rule.from)+len(rule.to)), path[:i]...), rule.to...), path[i+len(rule.from):]...)
+			toReturn = append(toReturn, rewritten)
+		}
+	}
+	return toReturn
+}
+
+func reverseRule(rule rewriteRule) rewriteRule {
+	return rewriteRule{from: rule.to, to: rule.from, source: rule.source}
+}
+
+// every path that can be reached from path by rewriting, as far as the search goes
+// no path longer than maxLength is looked at
+func (prover *pathProver) reachable(start Vertex, path []string, maxLength int) map[string]bool {
+	rules := make([]rewriteRule, 0, 2*len(prover.equalities)+len(prover.inclusions))
+	for _, rule := range prover.equalities {
+		rules = append(rules, rule, reverseRule(rule))
+	}
+	rules = append(rules, prover.inclusions...)
+	toReturn := map[string]bool{pathKey(path): true}
+	queue := [][]string{path}
+	for len(queue) > 0 && len(toReturn) < maxProofStates {
+		current := queue[0]
+		queue = queue[1:]
+		for _, rule := range rules {
+			for _, next := range prover.rewrites(start, current, rule) {
+				key := pathKey(next)
+				if len(next) > maxLength || toReturn[key] {
+					continue
+				}
+				toReturn[key] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return toReturn
+}
+
+// how long the paths in a search may get, enough room to apply the longest rule once more than the sides need
+func (prover *pathProver) lengthBound(lhs []string, rhs []string) int {
+	toReturn := len(lhs)
+	if len(rhs) > toReturn {
+		toReturn = len(rhs)
+	}
+	longest := 0
+	for _, rule := range append(append([]rewriteRule{}, prover.equalities...), prover.inclusions...) {
+		if len(rule.from) > longest {
+			longest = len(rule.from)
+		}
+		if len(rule.to) > longest {
+			longest = len(rule.to)
+		}
+	}
+	return toReturn + longest
+}
+
+// true when lhs can be rewritten into rhs, with inclusions only used from left to right
+// so with inclusions in play this proves lhs is contained in rhs
+func (prover *pathProver) proves(start Vertex, lhs []string, rhs []string) bool {
+	return prover.reachable(start, lhs, prover.lengthBound(lhs, rhs))[pathKey(rhs)]
+}
+
+// Kleene equality is not transitive, so a proof is strict rewriting on each side
+// meeting in the middle, possibly with one use of a Kleene equation in between
+func (prover *pathProver) provesKleene(start Vertex, lhs []string, rhs []string, kleene []rewriteRule) bool {
+	bound := prover.lengthBound(lhs, rhs)
+	fromLHS := prover.reachable(start, lhs, bound)
+	fromRHS := prover.reachable(start, rhs, bound)
+	for key := range fromLHS {
+		if fromRHS[key] {
+			return true
+		}
+	}
+	for key := range fromLHS {
+		var path []string
+		if key != "" {
+			path = strings.Split(key, "\x00")
+		}
+		for _, rule := range kleene {
+			for _, direction := range []rewriteRule{rule, reverseRule(rule)} {
+				for _, next := range prover.rewrites(start, path, direction) {
+					if fromRHS[pathKey(next)] {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package coloredGraphSchema
+
+import (
+	"strings"
+	"testing"
+)
+
+// a schema with one vertex A and a function edge from A to itself for each name
+// equations are given as "lhs = rhs" with the edges of each side separated by dots
+func loops(t *testing.T, names string, equations ...string) SchemaGraph {
+	t.Helper()
+	schema := EmptySchemaGraph()
+	if !schema.AddVertex2("A") {
+		t.Fatal("could not add A")
+	}
+	for _, name := range strings.Fields(names) {
+		if !schema.AddFunctionEdge2("A", "A", name) {
+			t.Fatal("could not add " + name)
+		}
+	}
+	for _, equation := range equations {
+		lhs, rhs, _ := strings.Cut(equation, " = ")
+		if !schema.AddFunctionEquation2(path(lhs), path(rhs), equation) {
+			t.Fatal("could not add " + equation)
+		}
+	}
+	return schema
+}
+
+func path(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(text, ".")
+}
+
+func TestProves(t *testing.T) {
+	schema := loops(t, "f g h", "f.f = f", "g.h = ")
+	prover := newPathProver(&schema).useStrictEquations(&schema)
+	for _, example := range []struct {
+		lhs    string
+		rhs    string
+		proved bool
+	}{
+		{"f", "f", true},
+		{"f.f.f", "f", true},
+		{"f", "f.f.f", true},
+		// g.h is the identity wherever it occurs
+		{"f.g.h.f", "f", true},
+		{"g.h.g", "g", true},
+		{"", "g.h", true},
+		// h.g is not
+		{"h.g", "", false},
+		{"f.g", "g.f", false},
+		{"g", "h", false},
+	} {
+		if got := prover.proves(NewVertex("A"), path(example.lhs), path(example.rhs)); got != example.proved {
+			t.Errorf("%s = %s proved %v, want %v", example.lhs, example.rhs, got, example.proved)
+		}
+	}
+}
+
+// inclusions may only be used from left to right
+func TestProvesInclusion(t *testing.T) {
+	schema := EmptySchemaGraph()
+	if !schema.AddVertex2("A") || !schema.AddRelationEdge2("A", "A", "r") || !schema.AddRelationEdge2("A", "A", "s") ||
+		!schema.AddRelationInclusion2([]string{"r"}, []string{"s"}, "r in s") {
+		t.Fatal("could not build the schema")
+	}
+	prover := newPathProver(&schema).useRelationEquations(&schema)
+	if !prover.proves(NewVertex("A"), []string{"r", "r"}, []string{"s", "s"}) {
+		t.Error("r.r is not in s.s")
+	}
+	if prover.proves(NewVertex("A"), []string{"s"}, []string{"r"}) {
+		t.Error("s is in r")
+	}
+}
+
+// f = j holds, but every proof goes through c.d.e.g, longer than the search lets paths get
+func TestProvesLengthBound(t *testing.T) {
+	schema := loops(t, "f a b c d e g h i j", "f = a.b", "a = c.d", "b = e.g", "d.e = h", "c.h = i", "i.g = j")
+	prover := newPathProver(&schema).useStrictEquations(&schema)
+	if bound := prover.lengthBound([]string{"f"}, []string{"j"}); bound != 3 {
+		t.Fatalf("bound %d, want 3", bound)
+	}
+	if prover.proves(NewVertex("A"), []string{"f"}, []string{"j"}) {
+		t.Error("f = j was proved without going through a path of length 4")
+	}
+	if !prover.proves(NewVertex("A"), []string{"c", "d", "e", "g"}, []string{"j"}) {
+		t.Error("c.d.e.g = j was not proved")
+	}
+}
+
+// with every edge able to double there is no end to the paths, the search stops after maxProofStates
+func TestReachableStateBound(t *testing.T) {
+	schema := loops(t, "a b c", "a = a.a", "b = b.b", "c = c.c")
+	prover := newPathProver(&schema).useStrictEquations(&schema)
+	reached := prover.reachable(NewVertex("A"), []string{"a", "b", "c"}, 1000)
+	if len(reached) < maxProofStates || len(reached) > 2*maxProofStates {
+		t.Errorf("reached %d paths, want about %d", len(reached), maxProofStates)
+	}
+}
@@ -0,0 +1,266 @@
+package coloredGraphSchema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// the JSON form of a schema, everything refers to vertices and edges by name
+// and lists keep the order things were added in so the output is stable
+//
+//	{"vertices": ["Employee", "Department"],
+//	 "edges": [{"name": "manager", "kind": "function", "source": "Employee", "target": "Employee"},
+//	           {"name": "last name", "kind": "attribute", "source": "Employee", "sort": "string"}],
+//	 "equations": [{"name": "managers are family", "kind": "attribute", "lhs": ["manager", "last name"], "rhs": ["last name"]}]}
+//
+// the lhs and rhs of an attribute equation end with the attribute
+const (
+	functionKind        = "function"
+	partialFunctionKind = "partial_function"
+	relationKind        = "relation"
+	attributeKind       = "attribute"
+)
+
+type edgeJSON struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Source string `json:"source"`
+	Target string `json:"target,omitempty"`
+	Sort   string `json:"sort,omitempty"`
+	// only for function and partial function edges, left out when it is restrict
+	OnDelete string `json:"on_delete,omitempty"`
+}
+
+type equationJSON struct {
+	Name string   `json:"name"`
+	Kind string   `json:"kind"`
+	LHS  []string `json:"lhs"`
+	RHS  []string `json:"rhs"`
+	// only for partial function equations
+	Kleene bool `json:"kleene,omitempty"`
+	// only for relation equations
+	Inclusion bool `json:"inclusion,omitempty"`
+}
+
+type schemaJSON struct {
+	Vertices  []string       `json:"vertices"`
+	Edges     []edgeJSON     `json:"edges"`
+	Equations []equationJSON `json:"equations"`
+}
+
+func (problem SchemaProblem) String() string {
+	parts := make([]string, 0, 4)
+	if problem.Vertex != "" {
+		parts = append(parts, "vertex "+problem.Vertex)
+	}
+	if problem.Edge != "" {
+		parts = append(parts, "edge "+problem.Edge)
+	}
+	if problem.Equation != "" {
+		parts = append(parts, "equation "+problem.Equation)
+	}
+	return strings.Join(append(parts, problem.Reason), ", ")
+}
+
+// what UnmarshalJSON returns when the schema it read is not valid
+type SchemaProblems []SchemaProblem
+
+func (problems SchemaProblems) Error() string {
+	messages := make([]string, len(problems))
+	for i, problem := range problems {
+		messages[i] = problem.String()
+	}
+	return fmt.Sprintf("%d schema problem(s): %s", len(problems), strings.Join(messages, "; "))
+}
+
+func edgeNames[E UnspecifiedEdge](path []E) []string {
+	toReturn := make([]string, len(path))
+	for i, edge := range path {
+		toReturn[i] = edge.GetIdentifier()
+	}
+	return toReturn
+}
+
+// empty for Restrict so that it is left out
+func (potentialSchema SchemaGraph) onDeleteJSON(edgeName string) string {
+	if action := potentialSchema.GetDeleteAction(edgeName); action != Restrict {
+		return action.String()
+	}
+	return ""
+}
+
+func (potentialSchema SchemaGraph) MarshalJSON() ([]byte, error) {
+	result := schemaJSON{Vertices: make([]string, 0, len(potentialSchema.vertices)), Edges: make([]edgeJSON, 0), Equations: make([]equationJSON, 0)}
+	for _, v := range potentialSchema.vertices {
+		result.Vertices = append(result.Vertices, v.identifier)
+	}
+	for _, edge := range potentialSchema.functionEdges {
+		result.Edges = append(result.Edges, edgeJSON{Name: edge.identifier, Kind: functionKind, Source: edge.source.identifier, Target: edge.target.identifier,
+			OnDelete: potentialSchema.onDeleteJSON(edge.identifier)})
+	}
+	for _, edge := range potentialSchema.partialFunctionEdges {
+		result.Edges = append(result.Edges, edgeJSON{Name: edge.identifier, Kind: partialFunctionKind, Source: edge.source.identifier, Target: edge.target.identifier,
+			OnDelete: potentialSchema.onDeleteJSON(edge.identifier)})
+	}
+	for _, edge := range potentialSchema.relationEdges {
+		result.Edges = append(result.Edges, edgeJSON{Name: edge.identifier, Kind: relationKind, Source: edge.source.identifier, Target: edge.target.identifier})
+	}
+	for _, edge := range potentialSchema.attributeEdges {
+		result.Edges = append(result.Edges, edgeJSON{Name: edge.identifier, Kind: attributeKind, Source: edge.source.identifier, Sort: edge.sort.String()})
+	}
+	for _, eq := range potentialSchema.functionEquations {
+		result.Equations = append(result.Equations, equationJSON{Name: eq.identifier, Kind: functionKind, LHS: edgeNames(eq.lhs), RHS: edgeNames(eq.rhs)})
+	}
+	for _, eq := range potentialSchema.partialFunctionEquations {
+		result.Equations = append(result.Equations, equationJSON{Name: eq.identifier, Kind: partialFunctionKind, LHS: edgeNames(eq.lhs), RHS: edgeNames(eq.rhs),
+			Kleene: eq.equality == KleeneEquality})
+	}
+	for _, eq := range potentialSchema.relationEquations {
+		result.Equations = append(result.Equations, equationJSON{Name: eq.identifier, Kind: relationKind, LHS: edgeNames(eq.lhs), RHS: edgeNames(eq.rhs),
+			Inclusion: eq.comparison == SetInclusion})
+	}
+	for _, eq := range potentialSchema.attributeEquations {
+		result.Equations = append(result.Equations, equationJSON{Name: eq.identifier, Kind: attributeKind,
+			LHS: append(edgeNames(eq.lhsPath), eq.lhsAttribute.identifier), RHS: append(edgeNames(eq.rhsPath), eq.rhsAttribute.identifier)})
+	}
+	return json.Marshal(result)
+}
+
+// looks up every name in path with lookup, the problems say which names were not found
+func resolvePath[E any](names []string, equationName string, lookup func(string) (E, bool)) ([]E, []SchemaProblem) {
+	toReturn := make([]E, 0, len(names))
+	problems := make([]SchemaProblem, 0)
+	for _, name := range names {
+		edge, found := lookup(name)
+		if !found {
+			problems = append(problems, SchemaProblem{Edge: name, Equation: equationName, Reason: "not an edge of a kind this equation can use"})
+			continue
+		}
+		toReturn = append(toReturn, edge)
+	}
+	return toReturn, problems
+}
+
+// splits off the attribute at the end of one side of an attribute equation
+func (potentialSchema *SchemaGraph) resolveAttributeTerm(names []string, equationName string) ([]FunctionEdge, AttributeEdge, []SchemaProblem) {
+	if len(names) == 0 {
+		return nil, AttributeEdge{}, []SchemaProblem{{Equation: equationName, Reason: "each side must end in an attribute"}}
+	}
+	path, problems := resolvePath(names[:len(names)-1], equationName, potentialSchema.GetFunctionEdgeByName)
+	attribute, found := potentialSchema.GetAttributeEdgeByName(names[len(names)-1])
+	if !found {
+		problems = append(problems, SchemaProblem{Edge: names[len(names)-1], Equation: equationName, Reason: "not an attribute of the schema"})
+	}
+	return path, attribute, problems
+}
+
+// the edges are put in as given, even if their endpoints are missing,
+// so that FindSchemaProblems can say what is wrong with them
+// the schema is only replaced when there are no problems at all
+func (startingSchema *SchemaGraph) UnmarshalJSON(data []byte) error {
+	var input schemaJSON
+	if err := json.Unmarshal(data, &input); err != nil {
+		return err
+	}
+	result := EmptySchemaGraph()
+	problems := make(SchemaProblems, 0)
+	for _, name := range input.Vertices {
+		if vertexInVertices2(name, result.vertices) {
+			problems = append(problems, SchemaProblem{Vertex: name, Reason: "vertex is listed more than once"})
+			continue
+		}
+		result.vertices = append(result.vertices, Vertex{identifier: name})
+	}
+	for _, edge := range input.Edges {
+		if result.HasEdgeNamed(edge.Name) {
+			problems = append(problems, SchemaProblem{Edge: edge.Name, Reason: "edge name is used more than once"})
+			continue
+		}
+		source, target := Vertex{identifier: edge.Source}, Vertex{identifier: edge.Target}
+		switch edge.Kind {
+		case functionKind:
+			result.functionEdges = append(result.functionEdges, FunctionEdge{source: source, target: target, identifier: edge.Name})
+		case partialFunctionKind:
+			result.partialFunctionEdges = append(result.partialFunctionEdges, PartialFunctionEdge{source: source, target: target, identifier: edge.Name})
+		case relationKind:
+			result.relationEdges = append(result.relationEdges, RelationEdge{source: source, target: target, identifier: edge.Name})
+		case attributeKind:
+			sort, isSort := SortByName(edge.Sort)
+			if !isSort {
+				problems = append(problems, SchemaProblem{Edge: edge.Name, Reason: "unknown sort " + edge.Sort})
+				continue
+			}
+			result.attributeEdges = append(result.attributeEdges, AttributeEdge{source: source, sort: sort, identifier: edge.Name})
+		default:
+			problems = append(problems, SchemaProblem{Edge: edge.Name, Reason: "unknown edge kind " + edge.Kind})
+			continue
+		}
+		if edge.OnDelete == "" {
+			continue
+		}
+		action, isAction := DeleteActionByName(edge.OnDelete)
+		if !isAction {
+			problems = append(problems, SchemaProblem{Edge: edge.Name, Reason: "unknown on_delete action " + edge.OnDelete})
+			continue
+		}
+		if !result.SetDeleteAction(edge.Name, action) {
+			problems = append(problems, SchemaProblem{Edge: edge.Name, Reason: "on_delete " + edge.OnDelete + " can not be used on this edge"})
+		}
+	}
+	equationNames := make(map[string]bool, len(input.Equations))
+	for _, eq := range input.Equations {
+		if equationNames[eq.Name] {
+			problems = append(problems, SchemaProblem{Equation: eq.Name, Reason: "equation name is used more than once"})
+			continue
+		}
+		equationNames[eq.Name] = true
+		var lhsProblems, rhsProblems []SchemaProblem
+		switch eq.Kind {
+		case functionKind:
+			var newEquation FunctionEquation
+			newEquation.identifier = eq.Name
+			newEquation.lhs, lhsProblems = resolvePath(eq.LHS, eq.Name, result.GetFunctionEdgeByName)
+			newEquation.rhs, rhsProblems = resolvePath(eq.RHS, eq.Name, result.GetFunctionEdgeByName)
+			result.functionEquations = append(result.functionEquations, newEquation)
+		case partialFunctionKind:
+			var newEquation PossiblyPartialFunctionEquation
+			newEquation.identifier = eq.Name
+			newEquation.lhs, lhsProblems = resolvePath(eq.LHS, eq.Name, result.GetPartialFunctionEdgeByName)
+			newEquation.rhs, rhsProblems = resolvePath(eq.RHS, eq.Name, result.GetPartialFunctionEdgeByName)
+			if eq.Kleene {
+				newEquation.equality = KleeneEquality
+			}
+			result.partialFunctionEquations = append(result.partialFunctionEquations, newEquation)
+		case relationKind:
+			var newEquation PossiblyRelationEquation
+			newEquation.identifier = eq.Name
+			newEquation.lhs, lhsProblems = resolvePath(eq.LHS, eq.Name, result.GetRelationEdgeByName)
+			newEquation.rhs, rhsProblems = resolvePath(eq.RHS, eq.Name, result.GetRelationEdgeByName)
+			if eq.Inclusion {
+				newEquation.comparison = SetInclusion
+			}
+			result.relationEquations = append(result.relationEquations, newEquation)
+		case attributeKind:
+			var newEquation AttributeEquation
+			newEquation.identifier = eq.Name
+			newEquation.lhsPath, newEquation.lhsAttribute, lhsProblems = result.resolveAttributeTerm(eq.LHS, eq.Name)
+			newEquation.rhsPath, newEquation.rhsAttribute, rhsProblems = result.resolveAttributeTerm(eq.RHS, eq.Name)
+			result.attributeEquations = append(result.attributeEquations, newEquation)
+		default:
+			problems = append(problems, SchemaProblem{Equation: eq.Name, Reason: "unknown equation kind " + eq.Kind})
+		}
+		problems = append(append(problems, lhsProblems...), rhsProblems...)
+	}
+	if len(problems) == 0 {
+		problems = append(problems, FindSchemaProblems(result)...)
+	}
+	if len(problems) == 0 && !ValidateGraph(result) {
+		problems = append(problems, SchemaProblem{Reason: "schema does not pass ValidateGraph"})
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	*startingSchema = result
+	return nil
+}
@@ -0,0 +1,73 @@
+package coloredGraphSchema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// every kind of edge and equation, with the delete actions and equalities that are not the default
+func TestSchemaJSONRoundTrip(t *testing.T) {
+	schema := peopleSchema(t)
+	for _, ok := range []bool{
+		schema.AddFunctionEdge2("Department", "Person", "head"),
+		schema.AddPartialFunctionEdge2("Person", "Person", "mentor"),
+		schema.AddRelationEdge2("Person", "Person", "knows"),
+		schema.SetDeleteAction("worksIn", Cascade),
+		schema.SetDeleteAction("mentor", SetUndefined),
+		schema.AddFunctionEquation2([]string{"head", "worksIn"}, []string{}, "heads work there"),
+		schema.AddKleenePartialFunctionEquation2([]string{"mentor", "worksIn"}, []string{"worksIn"}, "mentors work alongside"),
+		schema.AddRelationInclusion2([]string{"mentor"}, []string{"knows"}, "mentors are known"),
+		schema.AddAttributeEquation2([]string{"worksIn"}, "title", []string{}, "name", "named after the department"),
+	} {
+		if !ok {
+			t.Fatal("could not build the schema")
+		}
+	}
+	text := mustJSON(t, schema)
+	var read SchemaGraph
+	if err := json.Unmarshal([]byte(text), &read); err != nil {
+		t.Fatal(err)
+	}
+	if !SameSchema(schema, read) {
+		t.Errorf("%s came back as a different schema", text)
+	}
+	if again := mustJSON(t, read); again != text {
+		t.Errorf("%s came back as %s", text, again)
+	}
+}
+
+func TestSchemaJSONRejected(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want SchemaProblem
+	}{
+		{"edge to a missing vertex", `{"vertices": ["A"], "edges": [{"name": "f", "kind": "function", "source": "A", "target": "B"}], "equations": []}`,
+			SchemaProblem{Vertex: "B", Edge: "f", Reason: "target is not a vertex of the schema"}},
+		{"attribute on a missing vertex", `{"vertices": ["A"], "edges": [{"name": "n", "kind": "attribute", "source": "B", "sort": "int"}], "equations": []}`,
+			SchemaProblem{Vertex: "B", Edge: "n", Reason: "source is not a vertex of the schema"}},
+		{"equation with a missing edge", `{"vertices": ["A"], "edges": [{"name": "f", "kind": "function", "source": "A", "target": "A"}],
+			"equations": [{"name": "e", "kind": "function", "lhs": ["f", "g"], "rhs": ["f"]}]}`,
+			SchemaProblem{Edge: "g", Equation: "e", Reason: "not an edge of a kind this equation can use"}},
+		{"attribute equation with a missing attribute", `{"vertices": ["A"], "edges": [{"name": "n", "kind": "attribute", "source": "A", "sort": "int"}],
+			"equations": [{"name": "e", "kind": "attribute", "lhs": ["n"], "rhs": ["m"]}]}`,
+			SchemaProblem{Edge: "m", Equation: "e", Reason: "not an attribute of the schema"}},
+		{"equation name used twice", `{"vertices": ["A"], "edges": [{"name": "f", "kind": "function", "source": "A", "target": "A"},
+			{"name": "r", "kind": "relation", "source": "A", "target": "A"}],
+			"equations": [{"name": "e", "kind": "function", "lhs": ["f", "f"], "rhs": ["f"]}, {"name": "e", "kind": "relation", "lhs": ["r"], "rhs": ["f"]}]}`,
+			SchemaProblem{Equation: "e", Reason: "equation name is used more than once"}},
+	}
+	for _, c := range cases {
+		schema := loops(t, "p")
+		before := mustJSON(t, schema)
+		err := json.Unmarshal([]byte(c.text), &schema)
+		if err == nil {
+			t.Errorf("%s: read without problems", c.name)
+			continue
+		}
+		expectProblem(t, err, c.want)
+		if after := mustJSON(t, schema); after != before {
+			t.Errorf("%s: the schema was replaced by %s", c.name, after)
+		}
+	}
+}
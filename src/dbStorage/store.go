@@ -0,0 +1,363 @@
+package dbStorage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+	rgdb "RelationalGraphDB/src/relationalGraphDB"
+)
+
+// a directory holds one database as
+// snapshot.json, the whole instance as of some log sequence number
+// wal.log, every change made after that snapshot
+// opening reads the snapshot and replays the log, Checkpoint folds the log into a new snapshot
+const (
+	snapshotFileName = "snapshot.json"
+	logFileName      = "wal.log"
+)
+
+type snapshotJSON struct {
+	Sequence int                 `json:"sequence"`
+	Instance rgdb.InstantiatedDB `json:"instance"`
+}
+
+type Store struct {
+	directory string
+	currentDB rgdb.InstantiatedDB
+	// the sequence number of the last change applied to currentDB
+	sequence int
+	log      logFile
+}
+
+// what the store needs from its log, an *os.File
+type logFile interface {
+	io.WriteSeeker
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+var ErrClosed = errors.New("store is closed")
+
+// opens the database kept in directory, creating an empty one if there is nothing there yet
+func Open(directory string) (*Store, error) {
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, err
+	}
+	toReturn := &Store{directory: directory, currentDB: rgdb.EmptyInstantiatedDB()}
+	snapshotData, err := os.ReadFile(toReturn.snapshotPath())
+	if err == nil {
+		var snapshot snapshotJSON
+		if err := json.Unmarshal(snapshotData, &snapshot); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", toReturn.snapshotPath(), err)
+		}
+		toReturn.currentDB = snapshot.Instance
+		toReturn.sequence = snapshot.Sequence
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	records, intactLength, err := readLog(toReturn.logPath())
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		// already in the snapshot, the crash came between writing it and emptying the log
+		if record.Sequence <= toReturn.sequence {
+			continue
+		}
+		if err := record.apply(&toReturn.currentDB); err != nil {
+			return nil, fmt.Errorf("replaying %s: %w", toReturn.logPath(), err)
+		}
+		toReturn.sequence = record.Sequence
+	}
+	log, err := os.OpenFile(toReturn.logPath(), os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	toReturn.log = log
+	// drop any torn write at the end so new records follow the last intact one
+	if err := toReturn.truncateLog(intactLength); err != nil {
+		log.Close()
+		return nil, err
+	}
+	return toReturn, nil
+}
+
+func (store *Store) snapshotPath() string {
+	return filepath.Join(store.directory, snapshotFileName)
+}
+
+func (store *Store) logPath() string {
+	return filepath.Join(store.directory, logFileName)
+}
+
+// cuts the log back to length bytes and writes from there on
+func (store *Store) truncateLog(length int64) error {
+	if err := store.log.Truncate(length); err != nil {
+		return err
+	}
+	_, err := store.log.Seek(length, io.SeekStart)
+	return err
+}
+
+// a copy of the database, changing it does not change what is stored
+func (store *Store) DB() rgdb.InstantiatedDB {
+	return store.currentDB.Clone()
+}
+
+// the sequence number of the last change, 0 for a new store
+func (store *Store) Sequence() int {
+	return store.sequence
+}
+
+// makes the change on a copy, logs what change returns and only then swaps the copy in
+// so a change that fails validation or can not be logged leaves the store as it was
+func (store *Store) commit(change func(*rgdb.InstantiatedDB) (walRecord, error)) error {
+	if store.log == nil {
+		return ErrClosed
+	}
+	potentialDB := store.currentDB.Clone()
+	record, err := change(&potentialDB)
+	if err != nil {
+		return err
+	}
+	record.Sequence = store.sequence + 1
+	line, err := encodeRecord(record)
+	if err != nil {
+		return err
+	}
+	offset, err := store.log.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	_, err = store.log.Write(line)
+	if err == nil {
+		err = store.log.Sync()
+	}
+	if err != nil {
+		// the caller is told the change failed, so it must not be replayed on the next Open
+		// and the next record must not follow a partial one
+		if truncateErr := store.truncateLog(offset); truncateErr != nil {
+			return errors.Join(err, truncateErr)
+		}
+		return err
+	}
+	store.currentDB = potentialDB
+	store.sequence = record.Sequence
+	return nil
+}
+
+func (store *Store) AddVertex(newVertex string, underlyingSet []homs.Element) error {
+	return store.commit(func(potentialDB *rgdb.InstantiatedDB) (walRecord, error) {
+		mySet := append([]homs.Element{}, underlyingSet...)
+		return walRecord{Operation: addVertexOperation, Vertex: newVertex, Elements: mySet}, potentialDB.AddVertex(newVertex, mySet)
+	})
+}
+
+func (store *Store) AddFunctionEdge(newSource string, newTarget string, description string, content homs.MyFunction) error {
+	return store.commit(func(potentialDB *rgdb.InstantiatedDB) (walRecord, error) {
+		if err := potentialDB.AddFunctionEdge(newSource, newTarget, description, content); err != nil {
+			return walRecord{}, err
+		}
+		schema := potentialDB.GetSchema()
+		thisEdge, _ := schema.GetFunctionEdgeByName(description)
+		table, _ := potentialDB.GetFunctionTable(thisEdge)
+		return walRecord{Operation: addFunctionEdgeOperation, Source: newSource, Target: newTarget, Edge: description, Function: &table}, nil
+	})
+}
+
+func (store *Store) AddPartialFunctionEdge(newSource string, newTarget string, description string, content homs.MyPartialFunction) error {
+	return store.commit(func(potentialDB *rgdb.InstantiatedDB) (walRecord, error) {
+		if err := potentialDB.AddPartialFunctionEdge(newSource, newTarget, description, content); err != nil {
+			return walRecord{}, err
+		}
+		schema := potentialDB.GetSchema()
+		thisEdge, _ := schema.GetDefPartialFunctionEdgeByName(description)
+		table, _ := potentialDB.GetPartialFunctionTable(thisEdge)
+		return walRecord{Operation: addPartialFunctionEdgeOperation, Source: newSource, Target: newTarget, Edge: description, PartialFunction: &table}, nil
+	})
+}
+
+func (store *Store) AddRelationEdge(newSource string, newTarget string, description string, content homs.MyRelation) error {
+	return store.commit(func(potentialDB *rgdb.InstantiatedDB) (walRecord, error) {
+		if err := potentialDB.AddRelationEdge(newSource, newTarget, description, content); err != nil {
+			return walRecord{}, err
+		}
+		schema := potentialDB.GetSchema()
+		thisEdge, _ := schema.GetDefRelationEdgeByName(description)
+		table, _ := potentialDB.GetRelationTable(thisEdge)
+		return walRecord{Operation: addRelationEdgeOperation, Source: newSource, Target: newTarget, Edge: description, Relation: &table}, nil
+	})
+}
+
+func (store *Store) AddAttributeEdge(newSource string, sort cgs.Sort, description string, content homs.MyFunction) error {
+	return store.commit(func(potentialDB *rgdb.InstantiatedDB) (walRecord, error) {
+		if err := potentialDB.AddAttributeEdge(newSource, sort, description, content); err != nil {
+			return walRecord{}, err
+		}
+		schema := potentialDB.GetSchema()
+		thisEdge, _ := schema.GetAttributeEdgeByName(description)
+		table, _ := potentialDB.GetAttributeTable(thisEdge)
+		return walRecord{Operation: addAttributeEdgeOperation, Source: newSource, Sort: sort.String(), Edge: description, Function: &table}, nil
+	})
+}
+
+// the insertion is logged as given, the defaults it relies on give the same pairs when it is replayed
+func (store *Store) AddElementToSet(modifiedVertex string, addedItem homs.Element, insertion rgdb.ElementInsertion) error {
+	return store.commit(func(potentialDB *rgdb.InstantiatedDB) (walRecord, error) {
+		return walRecord{Operation: addElementOperation, Vertex: modifiedVertex, Element: &addedItem, Insertion: &insertion},
+			potentialDB.AddElementToSet(modifiedVertex, addedItem, insertion)
+	})
+}
+
+func (store *Store) SetDeleteAction(edgeName string, action cgs.DeleteAction) error {
+	return store.commit(func(potentialDB *rgdb.InstantiatedDB) (walRecord, error) {
+		return walRecord{Operation: setDeleteActionOperation, Edge: edgeName, DeleteAction: action.String()}, potentialDB.SetDeleteAction(edgeName, action)
+	})
+}
+
+// only the element asked for is logged, the delete actions in the schema cascade the same way when it is replayed
+func (store *Store) DeleteElementFromSet(modifiedVertex string, deletedItem homs.Element) (rgdb.DeletionReport, error) {
+	var report rgdb.DeletionReport
+	err := store.commit(func(potentialDB *rgdb.InstantiatedDB) (walRecord, error) {
+		var err error
+		report, err = potentialDB.DeleteElementFromSet(modifiedVertex, deletedItem)
+		return walRecord{Operation: deleteElementOperation, Vertex: modifiedVertex, Element: &deletedItem}, err
+	})
+	if err != nil {
+		return rgdb.DeletionReport{}, err
+	}
+	return report, nil
+}
+
+// removes the edge of whatever kind called description, along with the equations using it and its data
+func (store *Store) RemoveEdge(description string) (rgdb.RemovalReport, error) {
+	var report rgdb.RemovalReport
+	err := store.commit(func(potentialDB *rgdb.InstantiatedDB) (walRecord, error) {
+		var err error
+		report, err = potentialDB.RemoveEdge(description)
+		return walRecord{Operation: removeEdgeOperation, Edge: description}, err
+	})
+	if err != nil {
+		return rgdb.RemovalReport{}, err
+	}
+	return report, nil
+}
+
+func (store *Store) DeleteVertex(badVertex string) (rgdb.RemovalReport, error) {
+	var report rgdb.RemovalReport
+	err := store.commit(func(potentialDB *rgdb.InstantiatedDB) (walRecord, error) {
+		var err error
+		report, err = potentialDB.DeleteVertex(badVertex)
+		return walRecord{Operation: deleteVertexOperation, Vertex: badVertex}, err
+	})
+	if err != nil {
+		return rgdb.RemovalReport{}, err
+	}
+	return report, nil
+}
+
+func (store *Store) addEquation(identifier string, eq equationRecord) error {
+	return store.commit(func(potentialDB *rgdb.InstantiatedDB) (walRecord, error) {
+		return walRecord{Operation: addEquationOperation, EquationName: identifier, Equation: &eq}, eq.addTo(potentialDB, identifier)
+	})
+}
+
+func (store *Store) AddFunctionEquation(lhs []string, rhs []string, identifier string) error {
+	return store.addEquation(identifier, equationRecord{Kind: functionEquationKind, LHS: lhs, RHS: rhs})
+}
+
+func (store *Store) AddPartialFunctionEquation(lhs []string, rhs []string, identifier string) error {
+	return store.addEquation(identifier, equationRecord{Kind: partialFunctionEquationKind, LHS: lhs, RHS: rhs})
+}
+
+func (store *Store) AddKleenePartialFunctionEquation(lhs []string, rhs []string, identifier string) error {
+	return store.addEquation(identifier, equationRecord{Kind: kleenePartialFunctionEquationKind, LHS: lhs, RHS: rhs})
+}
+
+func (store *Store) AddRelationEquation(lhs []string, rhs []string, identifier string) error {
+	return store.addEquation(identifier, equationRecord{Kind: relationEquationKind, LHS: lhs, RHS: rhs})
+}
+
+func (store *Store) AddRelationInclusion(lhs []string, rhs []string, identifier string) error {
+	return store.addEquation(identifier, equationRecord{Kind: relationInclusionKind, LHS: lhs, RHS: rhs})
+}
+
+func (store *Store) AddAttributeEquation(lhsPath []string, lhsAttribute string, rhsPath []string, rhsAttribute string, identifier string) error {
+	return store.addEquation(identifier, equationRecord{Kind: attributeEquationKind,
+		LHS: append(append([]string{}, lhsPath...), lhsAttribute), RHS: append(append([]string{}, rhsPath...), rhsAttribute)})
+}
+
+// removes the equation of whatever kind called identifier
+func (store *Store) RemoveEquation(identifier string) error {
+	return store.commit(func(potentialDB *rgdb.InstantiatedDB) (walRecord, error) {
+		return walRecord{Operation: removeEquationOperation, EquationName: identifier}, potentialDB.RemoveEquation(identifier)
+	})
+}
+
+// replaces the whole database with newDB, which has to pass ValidateDB
+// this is logged as a snapshot so it is for loading data in bulk, not for small changes
+// it counts as one change, so the sequence number goes up by one
+// once the new snapshot is written the replacement has happened, an error from emptying the log after that
+// is still returned but the store keeps newDB, the records left in the log are older than the snapshot and Open skips them
+func (store *Store) Replace(newDB rgdb.InstantiatedDB) error {
+	if store.log == nil {
+		return ErrClosed
+	}
+	if problems := rgdb.ValidateDB(newDB); len(problems) > 0 {
+		return problems
+	}
+	if err := store.writeSnapshot(newDB, store.sequence+1); err != nil {
+		return err
+	}
+	store.currentDB, store.sequence = newDB.Clone(), store.sequence+1
+	return store.emptyLog()
+}
+
+// writes the whole database as the new snapshot and empties the log
+// the snapshot is replaced atomically, so a crash part way through leaves the old snapshot and the full log
+func (store *Store) Checkpoint() error {
+	if store.log == nil {
+		return ErrClosed
+	}
+	if err := store.writeSnapshot(store.currentDB, store.sequence); err != nil {
+		return err
+	}
+	return store.emptyLog()
+}
+
+func (store *Store) writeSnapshot(instance rgdb.InstantiatedDB, sequence int) error {
+	data, err := json.Marshal(snapshotJSON{Sequence: sequence, Instance: instance})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(store.snapshotPath(), data)
+}
+
+// only once everything in the log is in the snapshot
+func (store *Store) emptyLog() error {
+	if err := store.truncateLog(0); err != nil {
+		return err
+	}
+	return store.log.Sync()
+}
+
+// checkpoints so the next Open has no log to replay
+func (store *Store) Close() error {
+	if store.log == nil {
+		return ErrClosed
+	}
+	err := store.Checkpoint()
+	closeErr := store.log.Close()
+	store.log = nil
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,228 @@
+package dbStorage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+	rgdb "RelationalGraphDB/src/relationalGraphDB"
+)
+
+func mustOpen(t *testing.T, directory string) *Store {
+	t.Helper()
+	store, err := Open(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// the vertex A over 1 and 2 with f sending both to 1
+func addCounting(t *testing.T, store *Store) {
+	t.Helper()
+	if err := store.AddVertex("A", homs.NewIntElements([]int{1, 2})); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddFunctionEdge("A", "A", "f", homs.NewFunction(func(x homs.Element) homs.Element { return homs.NewIntElement(1) })); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expectCounting(t *testing.T, store *Store) {
+	t.Helper()
+	db := store.DB()
+	elements, present := db.GetUnderlyingSet(cgs.NewVertex("A"))
+	if !present || len(elements) != 2 {
+		t.Fatalf("A is %v, want 1 and 2", elements)
+	}
+	schema := db.GetSchema()
+	edge, present := schema.GetFunctionEdgeByName("f")
+	if !present {
+		t.Fatal("f is missing")
+	}
+	f, _ := db.GetFunction(edge)
+	if got := f.Evaluate(homs.NewIntElement(2)); got != homs.NewIntElement(1) {
+		t.Errorf("f(2) is %v, want 1", got)
+	}
+}
+
+func elementsOf(store *Store, vertex string) ([]homs.Element, bool) {
+	db := store.DB()
+	return db.GetUnderlyingSet(cgs.NewVertex(vertex))
+}
+
+func logSize(t *testing.T, directory string) int64 {
+	t.Helper()
+	info, err := os.Stat(filepath.Join(directory, logFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+// the first store is never closed, as if the process had died, so everything comes back from the log
+func TestReopen(t *testing.T) {
+	directory := t.TempDir()
+	addCounting(t, mustOpen(t, directory))
+	reopened := mustOpen(t, directory)
+	expectCounting(t, reopened)
+	if got := reopened.Sequence(); got != 2 {
+		t.Errorf("sequence %d, want 2", got)
+	}
+}
+
+func TestTornTail(t *testing.T) {
+	directory := t.TempDir()
+	addCounting(t, mustOpen(t, directory))
+	intact := logSize(t, directory)
+	log, err := os.OpenFile(filepath.Join(directory, logFileName), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := log.WriteString(`1234abcd {"sequence":3,"operation":"add_ver`); err != nil {
+		t.Fatal(err)
+	}
+	log.Close()
+	reopened := mustOpen(t, directory)
+	expectCounting(t, reopened)
+	if got := logSize(t, directory); got != intact {
+		t.Errorf("log is %d bytes after reopening, want the %d intact ones", got, intact)
+	}
+	if err := reopened.AddVertex("B", nil); err != nil {
+		t.Fatal(err)
+	}
+	again := mustOpen(t, directory)
+	expectCounting(t, again)
+	if _, present := elementsOf(again, "B"); !present || again.Sequence() != 3 {
+		t.Errorf("the change after the torn record was lost, sequence %d", again.Sequence())
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	directory := t.TempDir()
+	store := mustOpen(t, directory)
+	addCounting(t, store)
+	if err := store.Checkpoint(); err != nil {
+		t.Fatal(err)
+	}
+	if got := logSize(t, directory); got != 0 {
+		t.Errorf("log is %d bytes after a checkpoint, want 0", got)
+	}
+	reopened := mustOpen(t, directory)
+	expectCounting(t, reopened)
+	if got := reopened.Sequence(); got != 2 {
+		t.Errorf("sequence %d, want 2", got)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddVertex("B", nil); err != ErrClosed {
+		t.Errorf("got %v after closing, want ErrClosed", err)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	directory := t.TempDir()
+	store := mustOpen(t, directory)
+	addCounting(t, store)
+	replacement := rgdb.EmptyInstantiatedDB()
+	if err := replacement.AddVertex("C", homs.NewStringElements([]string{"c"})); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Replace(replacement); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.Sequence(); got != 3 {
+		t.Errorf("sequence %d after replacing, want 3", got)
+	}
+	reopened := mustOpen(t, directory)
+	if _, present := elementsOf(reopened, "A"); present {
+		t.Error("A survived the replacement")
+	}
+	if elements, _ := elementsOf(reopened, "C"); len(elements) != 1 || reopened.Sequence() != 3 {
+		t.Errorf("C is %v at sequence %d, want c at 3", elements, reopened.Sequence())
+	}
+}
+
+// a log whose Sync always fails, as when the disk goes away after the write
+type failingSync struct {
+	*os.File
+}
+
+var errSync = errors.New("sync failed")
+
+func (log failingSync) Sync() error {
+	return errSync
+}
+
+// a log that can not be cut back, so a replacement can not empty it
+type failingTruncate struct {
+	*os.File
+}
+
+var errTruncate = errors.New("truncate failed")
+
+func (log failingTruncate) Truncate(size int64) error {
+	return errTruncate
+}
+
+// the snapshot is written before the log fails to empty, so the replacement stands
+func TestReplaceLogNotEmptied(t *testing.T) {
+	directory := t.TempDir()
+	store := mustOpen(t, directory)
+	addCounting(t, store)
+	replacement := rgdb.EmptyInstantiatedDB()
+	if err := replacement.AddVertex("C", homs.NewStringElements([]string{"c"})); err != nil {
+		t.Fatal(err)
+	}
+	working := store.log
+	store.log = failingTruncate{working.(*os.File)}
+	if err := store.Replace(replacement); !errors.Is(err, errTruncate) {
+		t.Fatalf("got %v, want the truncate to fail", err)
+	}
+	store.log = working
+	if _, present := elementsOf(store, "C"); !present || store.Sequence() != 3 {
+		t.Errorf("the store went back to before the replacement, sequence %d", store.Sequence())
+	}
+	if err := store.AddVertex("D", nil); err != nil {
+		t.Fatal(err)
+	}
+	reopened := mustOpen(t, directory)
+	if _, present := elementsOf(reopened, "A"); present {
+		t.Error("the log from before the replacement was replayed")
+	}
+	_, hasC := elementsOf(reopened, "C")
+	_, hasD := elementsOf(reopened, "D")
+	if !hasC || !hasD || reopened.Sequence() != 4 {
+		t.Errorf("C present %v and D present %v at sequence %d, want both at 4", hasC, hasD, reopened.Sequence())
+	}
+}
+
+func TestFailedWriteIsNotReplayed(t *testing.T) {
+	directory := t.TempDir()
+	store := mustOpen(t, directory)
+	addCounting(t, store)
+	before := logSize(t, directory)
+	working := store.log
+	store.log = failingSync{working.(*os.File)}
+	if err := store.AddVertex("B", nil); !errors.Is(err, errSync) {
+		t.Fatalf("got %v, want the sync to fail", err)
+	}
+	if got := logSize(t, directory); got != before {
+		t.Errorf("log is %d bytes after the failed change, want %d", got, before)
+	}
+	store.log = working
+	if err := store.AddVertex("C", nil); err != nil {
+		t.Fatal(err)
+	}
+	reopened := mustOpen(t, directory)
+	if _, present := elementsOf(reopened, "B"); present {
+		t.Error("the failed change was replayed")
+	}
+	if _, present := elementsOf(reopened, "C"); !present || reopened.Sequence() != 3 {
+		t.Errorf("the change after the failed one was lost, sequence %d", reopened.Sequence())
+	}
+}
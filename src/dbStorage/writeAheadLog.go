@@ -0,0 +1,254 @@
+package dbStorage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+	rgdb "RelationalGraphDB/src/relationalGraphDB"
+)
+
+// every change is written to the log and synced before it becomes visible
+// a line of the log is the crc32 of the record in hex, a space and then the record as JSON
+// a line that is cut short or fails its checksum is where a crash happened
+// so it and everything after it is thrown away when the log is read back
+
+const (
+	addVertexOperation              = "add_vertex"
+	addFunctionEdgeOperation        = "add_function_edge"
+	addPartialFunctionEdgeOperation = "add_partial_function_edge"
+	addRelationEdgeOperation        = "add_relation_edge"
+	addAttributeEdgeOperation       = "add_attribute_edge"
+	addElementOperation             = "add_element"
+	deleteElementOperation          = "delete_element"
+	setDeleteActionOperation        = "set_delete_action"
+	removeEdgeOperation             = "remove_edge"
+	deleteVertexOperation           = "delete_vertex"
+	addEquationOperation            = "add_equation"
+	removeEquationOperation         = "remove_equation"
+)
+
+// the kinds of equation an add_equation record can hold
+const (
+	functionEquationKind              = "function"
+	partialFunctionEquationKind       = "partial_function"
+	kleenePartialFunctionEquationKind = "kleene_partial_function"
+	relationEquationKind              = "relation"
+	relationInclusionKind             = "relation_inclusion"
+	attributeEquationKind             = "attribute"
+)
+
+// the sides are edge names, for an attribute equation each side ends with its attribute
+type equationRecord struct {
+	Kind string   `json:"kind"`
+	LHS  []string `json:"lhs"`
+	RHS  []string `json:"rhs"`
+}
+
+func (eq equationRecord) addTo(currentDB *rgdb.InstantiatedDB, identifier string) error {
+	switch eq.Kind {
+	case functionEquationKind:
+		return currentDB.AddFunctionEquation(eq.LHS, eq.RHS, identifier)
+	case partialFunctionEquationKind:
+		return currentDB.AddPartialFunctionEquation(eq.LHS, eq.RHS, identifier)
+	case kleenePartialFunctionEquationKind:
+		return currentDB.AddKleenePartialFunctionEquation(eq.LHS, eq.RHS, identifier)
+	case relationEquationKind:
+		return currentDB.AddRelationEquation(eq.LHS, eq.RHS, identifier)
+	case relationInclusionKind:
+		return currentDB.AddRelationInclusion(eq.LHS, eq.RHS, identifier)
+	case attributeEquationKind:
+		if len(eq.LHS) == 0 || len(eq.RHS) == 0 {
+			break
+		}
+		return currentDB.AddAttributeEquation(eq.LHS[:len(eq.LHS)-1], eq.LHS[len(eq.LHS)-1], eq.RHS[:len(eq.RHS)-1], eq.RHS[len(eq.RHS)-1], identifier)
+	}
+	return fmt.Errorf("malformed %q equation %q", eq.Kind, identifier)
+}
+
+// one change to the database, the data on a new edge is kept as a table
+// so replaying it does not need whatever closure it was first given with
+type walRecord struct {
+	Sequence        int                        `json:"sequence"`
+	Operation       string                     `json:"operation"`
+	Vertex          string                     `json:"vertex,omitempty"`
+	Elements        []homs.Element             `json:"elements,omitempty"`
+	Source          string                     `json:"source,omitempty"`
+	Target          string                     `json:"target,omitempty"`
+	Edge            string                     `json:"edge,omitempty"`
+	Sort            string                     `json:"sort,omitempty"`
+	Function        *homs.FunctionTable        `json:"function,omitempty"`
+	PartialFunction *homs.PartialFunctionTable `json:"partial_function,omitempty"`
+	Relation        *homs.RelationTable        `json:"relation,omitempty"`
+	Element         *homs.Element              `json:"element,omitempty"`
+	Insertion       *rgdb.ElementInsertion     `json:"insertion,omitempty"`
+	DeleteAction    string                     `json:"delete_action,omitempty"`
+	EquationName    string                     `json:"equation_name,omitempty"`
+	Equation        *equationRecord            `json:"equation,omitempty"`
+}
+
+// does to currentDB what was done when the record was written
+func (record walRecord) apply(currentDB *rgdb.InstantiatedDB) error {
+	switch record.Operation {
+	case addVertexOperation:
+		return currentDB.AddVertex(record.Vertex, record.Elements)
+	case addFunctionEdgeOperation:
+		if record.Function == nil {
+			break
+		}
+		return currentDB.AddFunctionEdge(record.Source, record.Target, record.Edge, record.Function.AsFunction())
+	case addPartialFunctionEdgeOperation:
+		if record.PartialFunction == nil {
+			break
+		}
+		return currentDB.AddPartialFunctionEdge(record.Source, record.Target, record.Edge, record.PartialFunction.AsPartialFunction())
+	case addRelationEdgeOperation:
+		if record.Relation == nil {
+			break
+		}
+		return currentDB.AddRelationEdge(record.Source, record.Target, record.Edge, record.Relation.AsRelation())
+	case addAttributeEdgeOperation:
+		sort, isSort := cgs.SortByName(record.Sort)
+		if !isSort || record.Function == nil {
+			break
+		}
+		return currentDB.AddAttributeEdge(record.Source, sort, record.Edge, record.Function.AsFunction())
+	case addElementOperation:
+		if record.Element == nil || record.Insertion == nil {
+			break
+		}
+		return currentDB.AddElementToSet(record.Vertex, *record.Element, *record.Insertion)
+	case deleteElementOperation:
+		if record.Element == nil {
+			break
+		}
+		_, err := currentDB.DeleteElementFromSet(record.Vertex, *record.Element)
+		return err
+	case setDeleteActionOperation:
+		action, isAction := cgs.DeleteActionByName(record.DeleteAction)
+		if !isAction {
+			break
+		}
+		return currentDB.SetDeleteAction(record.Edge, action)
+	case removeEdgeOperation:
+		_, err := currentDB.RemoveEdge(record.Edge)
+		return err
+	case deleteVertexOperation:
+		_, err := currentDB.DeleteVertex(record.Vertex)
+		return err
+	case addEquationOperation:
+		if record.Equation == nil {
+			break
+		}
+		return record.Equation.addTo(currentDB, record.EquationName)
+	case removeEquationOperation:
+		return currentDB.RemoveEquation(record.EquationName)
+	}
+	return fmt.Errorf("log record %d: malformed %q operation", record.Sequence, record.Operation)
+}
+
+func encodeRecord(record walRecord) ([]byte, error) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(payload), payload)), nil
+}
+
+// second return is false when line is torn or corrupted
+func decodeRecord(line []byte) (walRecord, bool) {
+	var record walRecord
+	if len(line) < 10 || line[8] != ' ' || line[len(line)-1] != '\n' {
+		return record, false
+	}
+	var checksum uint32
+	if _, err := fmt.Sscanf(string(line[:8]), "%08x", &checksum); err != nil {
+		return record, false
+	}
+	payload := bytes.TrimSuffix(line[9:], []byte("\n"))
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return record, false
+	}
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return record, false
+	}
+	return record, true
+}
+
+// the intact records at the start of the log at path
+// along with how many bytes they take up, anything after that is a torn write
+func readLog(path string) ([]walRecord, int64, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+	toReturn := make([]walRecord, 0)
+	var intactLength int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+		if len(line) == 0 {
+			break
+		}
+		record, intact := decodeRecord(line)
+		if !intact {
+			break
+		}
+		toReturn = append(toReturn, record)
+		intactLength = intactLength + int64(len(line))
+		if err == io.EOF {
+			break
+		}
+	}
+	return toReturn, intactLength, nil
+}
+
+// writes data to a temporary file next to path, syncs it and renames it over path
+// so readers see either the old contents or the new ones and never a mix
+func writeFileAtomically(path string, data []byte) error {
+	directory := filepath.Dir(path)
+	temporary, err := os.CreateTemp(directory, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporary.Name())
+	if _, err := temporary.Write(data); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Sync(); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(temporary.Name(), path); err != nil {
+		return err
+	}
+	return syncDirectory(directory)
+}
+
+// so that a rename or a newly created file survives a crash
+func syncDirectory(directory string) error {
+	handle, err := os.Open(directory)
+	if err != nil {
+		return err
+	}
+	defer handle.Close()
+	return handle.Sync()
+}
@@ -0,0 +1,308 @@
+package morphismTypes
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ElementKind int
+
+const (
+	IntKind ElementKind = iota
+	StringKind
+	// an ordered list of other elements, for composite keys
+	TupleKind
+	FloatKind
+	BoolKind
+	// a calendar day, no time of day or zone
+	DateKind
+)
+
+const dateLayout = "2006-01-02"
+
+// a member of the set sitting over some vertex
+// comparable, so it can be used as a map key and compared with ==
+// elements of different kinds are never equal, so 3 and "3" are different elements
+type Element struct {
+	kind        ElementKind
+	intValue    int
+	floatValue  float64
+	stringValue string
+}
+
+func NewIntElement(value int) Element {
+	return Element{kind: IntKind, intValue: value}
+}
+
+func NewStringElement(value string) Element {
+	return Element{kind: StringKind, stringValue: value}
+}
+
+// the components are encoded into a single string so the tuple stays comparable
+func NewTupleElement(components ...Element) Element {
+	var builder strings.Builder
+	for _, component := range components {
+		component.encode(&builder)
+	}
+	return Element{kind: TupleKind, intValue: len(components), stringValue: builder.String()}
+}
+
+// -0 is made 0, as the two are == but would be encoded differently
+// second return is false when value is NaN, which is not == to itself, or infinite, which JSON can not carry
+func NewFloatElement(value float64) (Element, bool) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return Element{}, false
+	}
+	if value == 0 {
+		value = 0
+	}
+	return Element{kind: FloatKind, floatValue: value}, true
+}
+
+func NewBoolElement(value bool) Element {
+	if value {
+		return Element{kind: BoolKind, intValue: 1}
+	}
+	return Element{kind: BoolKind}
+}
+
+// only the year, month and day of value in its own location are kept
+func NewDateElement(value time.Time) Element {
+	return Element{kind: DateKind, stringValue: value.Format(dateLayout)}
+}
+
+// second return is false when value is not of the form 2006-01-02
+func ParseDateElement(value string) (Element, bool) {
+	parsed, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return Element{}, false
+	}
+	return NewDateElement(parsed), true
+}
+
+func NewIntElements(values []int) []Element {
+	toReturn := make([]Element, len(values))
+	for i, value := range values {
+		toReturn[i] = NewIntElement(value)
+	}
+	return toReturn
+}
+
+func NewStringElements(values []string) []Element {
+	toReturn := make([]Element, len(values))
+	for i, value := range values {
+		toReturn[i] = NewStringElement(value)
+	}
+	return toReturn
+}
+
+func (e Element) GetKind() ElementKind {
+	return e.kind
+}
+
+// second return is false when e is not an IntKind
+func (e Element) IntValue() (int, bool) {
+	return e.intValue, e.kind == IntKind
+}
+
+// second return is false when e is not a StringKind
+func (e Element) StringValue() (string, bool) {
+	return e.stringValue, e.kind == StringKind
+}
+
+// second return is false when e is not a FloatKind
+func (e Element) FloatValue() (float64, bool) {
+	return e.floatValue, e.kind == FloatKind
+}
+
+// second return is false when e is not a BoolKind
+func (e Element) BoolValue() (bool, bool) {
+	return e.intValue == 1, e.kind == BoolKind
+}
+
+// midnight UTC of that day, second return is false when e is not a DateKind
+func (e Element) DateValue() (time.Time, bool) {
+	if e.kind != DateKind {
+		return time.Time{}, false
+	}
+	parsed, _ := time.Parse(dateLayout, e.stringValue)
+	return parsed, true
+}
+
+// second return is false when e is not a TupleKind
+func (e Element) Components() ([]Element, bool) {
+	if e.kind != TupleKind {
+		return nil, false
+	}
+	toReturn := make([]Element, 0, e.intValue)
+	rest := e.stringValue
+	for len(rest) > 0 {
+		var component Element
+		component, rest = decode(rest)
+		toReturn = append(toReturn, component)
+	}
+	return toReturn, true
+}
+
+func (e Element) String() string {
+	switch e.kind {
+	case StringKind:
+		return strconv.Quote(e.stringValue)
+	case TupleKind:
+		components, _ := e.Components()
+		parts := make([]string, len(components))
+		for i, component := range components {
+			parts[i] = component.String()
+		}
+		return "(" + strings.Join(parts, ", ") + ")"
+	case FloatKind:
+		return strconv.FormatFloat(e.floatValue, 'g', -1, 64)
+	case BoolKind:
+		return strconv.FormatBool(e.intValue == 1)
+	case DateKind:
+		return e.stringValue
+	}
+	return strconv.Itoa(e.intValue)
+}
+
+// kind, then the length of the payload, then the payload
+// so that decoding never has to look for separators inside strings
+func (e Element) encode(builder *strings.Builder) {
+	var payload string
+	switch e.kind {
+	case IntKind:
+		payload = strconv.Itoa(e.intValue)
+	case StringKind, TupleKind, DateKind:
+		payload = e.stringValue
+	case FloatKind:
+		payload = strconv.FormatFloat(e.floatValue, 'g', -1, 64)
+	case BoolKind:
+		payload = strconv.FormatBool(e.intValue == 1)
+	}
+	builder.WriteString(strconv.Itoa(int(e.kind)))
+	builder.WriteByte(':')
+	builder.WriteString(strconv.Itoa(len(payload)))
+	builder.WriteByte(':')
+	builder.WriteString(payload)
+}
+
+// inverse of encode, returns the first element and whatever follows it
+// only ever called on strings made by encode
+func decode(encoded string) (Element, string) {
+	kindEnd := strings.IndexByte(encoded, ':')
+	kind, _ := strconv.Atoi(encoded[:kindEnd])
+	encoded = encoded[kindEnd+1:]
+	lengthEnd := strings.IndexByte(encoded, ':')
+	length, _ := strconv.Atoi(encoded[:lengthEnd])
+	payload := encoded[lengthEnd+1 : lengthEnd+1+length]
+	rest := encoded[lengthEnd+1+length:]
+	switch ElementKind(kind) {
+	case StringKind:
+		return NewStringElement(payload), rest
+	case TupleKind:
+		toReturn := Element{kind: TupleKind, stringValue: payload}
+		components, _ := toReturn.Components()
+		toReturn.intValue = len(components)
+		return toReturn, rest
+	case FloatKind:
+		value, _ := strconv.ParseFloat(payload, 64)
+		toReturn, _ := NewFloatElement(value)
+		return toReturn, rest
+	case BoolKind:
+		return NewBoolElement(payload == "true"), rest
+	case DateKind:
+		return Element{kind: DateKind, stringValue: payload}, rest
+	}
+	value, _ := strconv.Atoi(payload)
+	return NewIntElement(value), rest
+}
+
+func (e Element) encoded() string {
+	var builder strings.Builder
+	e.encode(&builder)
+	return builder.String()
+}
+
+// any fixed order will do, this one sorts by encoding
+func sortElements(elements []Element) {
+	sort.Slice(elements, func(i, j int) bool { return elements[i].encoded() < elements[j].encoded() })
+}
+
+// ints are JSON numbers, strings are JSON strings, bools are JSON bools and tuples are JSON arrays
+// floats and dates are {"float": 1.5} and {"date": "2006-01-02"} so they are not mistaken for the others
+type taggedElementJSON struct {
+	Float *float64 `json:"float,omitempty"`
+	Date  *string  `json:"date,omitempty"`
+}
+
+func (e Element) MarshalJSON() ([]byte, error) {
+	switch e.kind {
+	case StringKind:
+		return json.Marshal(e.stringValue)
+	case TupleKind:
+		components, _ := e.Components()
+		return json.Marshal(components)
+	case FloatKind:
+		return json.Marshal(taggedElementJSON{Float: &e.floatValue})
+	case BoolKind:
+		return json.Marshal(e.intValue == 1)
+	case DateKind:
+		return json.Marshal(taggedElementJSON{Date: &e.stringValue})
+	}
+	return json.Marshal(e.intValue)
+}
+
+func (e *Element) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	switch value := raw.(type) {
+	case string:
+		*e = NewStringElement(value)
+		return nil
+	case bool:
+		*e = NewBoolElement(value)
+		return nil
+	case float64:
+		intValue, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			return errors.New("element numbers must be ints, floats are written as {\"float\": x}")
+		}
+		*e = NewIntElement(intValue)
+		return nil
+	case []interface{}:
+		var components []Element
+		if err := json.Unmarshal(data, &components); err != nil {
+			return err
+		}
+		*e = NewTupleElement(components...)
+		return nil
+	}
+	var tagged taggedElementJSON
+	if err := json.Unmarshal(data, &tagged); err != nil {
+		return err
+	}
+	if tagged.Float != nil {
+		parsed, valid := NewFloatElement(*tagged.Float)
+		if !valid {
+			return errors.New("not a finite float: " + string(data))
+		}
+		*e = parsed
+		return nil
+	}
+	if tagged.Date != nil {
+		parsed, valid := ParseDateElement(*tagged.Date)
+		if !valid {
+			return errors.New("dates must be of the form 2006-01-02, not " + *tagged.Date)
+		}
+		*e = parsed
+		return nil
+	}
+	return errors.New("not an element: " + string(data))
+}
@@ -0,0 +1,91 @@
+package morphismTypes
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func mustFloat(t *testing.T, value float64) Element {
+	t.Helper()
+	toReturn, valid := NewFloatElement(value)
+	if !valid {
+		t.Fatalf("%v is not a float element", value)
+	}
+	return toReturn
+}
+
+func TestElementKindsDistinct(t *testing.T) {
+	day := NewDateElement(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+	elements := []Element{NewIntElement(3), NewStringElement("3"), mustFloat(t, 3), NewTupleElement(NewIntElement(3)),
+		NewIntElement(1), NewBoolElement(true), day, NewStringElement("2024-03-01")}
+	for i, x := range elements {
+		for j, y := range elements {
+			if i != j && x == y {
+				t.Errorf("%v of kind %d equals %v of kind %d", x, x.GetKind(), y, y.GetKind())
+			}
+		}
+	}
+}
+
+func TestFloatElementNaNAndNegativeZero(t *testing.T) {
+	for _, value := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, valid := NewFloatElement(value); valid {
+			t.Errorf("%v was made into an element", value)
+		}
+	}
+	zero, negativeZero := mustFloat(t, 0), mustFloat(t, math.Copysign(0, -1))
+	if zero != negativeZero || zero.encoded() != negativeZero.encoded() || negativeZero.String() != "0" {
+		t.Errorf("-0 is %v encoded %s, want it the same as 0 encoded %s", negativeZero, negativeZero.encoded(), zero.encoded())
+	}
+}
+
+// the separators of the encoding turn up inside the strings
+func TestNestedTupleComponents(t *testing.T) {
+	inner := NewTupleElement(NewStringElement("1:2:"), mustFloat(t, -2.5), NewTupleElement())
+	outer := NewTupleElement(NewIntElement(7), inner, NewBoolElement(false), NewStringElement(""))
+	components, isTuple := outer.Components()
+	if !isTuple || len(components) != 4 || components[0] != NewIntElement(7) || components[1] != inner ||
+		components[2] != NewBoolElement(false) || components[3] != NewStringElement("") {
+		t.Fatalf("components %v, want 7, %v, false and the empty string", components, inner)
+	}
+	innerComponents, _ := components[1].Components()
+	if len(innerComponents) != 3 || innerComponents[0] != NewStringElement("1:2:") || innerComponents[1] != mustFloat(t, -2.5) {
+		t.Errorf("inner components %v", innerComponents)
+	}
+	if empty, _ := innerComponents[2].Components(); len(empty) != 0 {
+		t.Errorf("the empty tuple has components %v", empty)
+	}
+	if _, isTuple := NewIntElement(7).Components(); isTuple {
+		t.Error("7 has components")
+	}
+}
+
+func TestElementJSONRoundTrip(t *testing.T) {
+	day, _ := ParseDateElement("2024-02-29")
+	elements := []Element{NewIntElement(-4), NewStringElement("x"), mustFloat(t, 1.5), mustFloat(t, 2), NewBoolElement(true), day,
+		NewTupleElement(NewIntElement(1), NewTupleElement(NewStringElement("a"), day))}
+	for _, x := range elements {
+		data, err := json.Marshal(x)
+		if err != nil {
+			t.Fatalf("%v: %v", x, err)
+		}
+		var y Element
+		if err := json.Unmarshal(data, &y); err != nil {
+			t.Fatalf("%v from %s: %v", x, data, err)
+		}
+		if x != y {
+			t.Errorf("%v went to %s and came back as %v", x, data, y)
+		}
+	}
+}
+
+func TestElementJSONRejected(t *testing.T) {
+	for _, data := range []string{`1.5`, `1e3`, `{"date": "2023-02-29"}`, `{"date": "1 March"}`, `{}`, `null`, `[1, 2.5]`} {
+		var e Element
+		if err := json.Unmarshal([]byte(data), &e); err == nil {
+			t.Errorf("%s was read as %v", data, e)
+		}
+	}
+}
@@ -2,141 +2,191 @@ package morphismTypes
 
 import "reflect"
 
-func presentInts(myInts []int) map[int]bool {
-	var myIntMap map[int]bool
-	for _, currentInt := range myInts {
-		myIntMap[currentInt] = true
+func presentElements(myElements []Element) map[Element]bool {
+	myElementMap := make(map[Element]bool, len(myElements))
+	for _, currentElement := range myElements {
+		myElementMap[currentElement] = true
 	}
-	return myIntMap
+	return myElementMap
 }
 
-type myFunction struct {
-	myUnderlyingFunction func(int) int
+type MyFunction struct {
+	myUnderlyingFunction func(Element) Element
 }
 
-type myFunctionParameterized struct {
+// a total function, defined on every element of whatever source set it is used with
+func NewFunction(underlyingFunction func(Element) Element) MyFunction {
+	return MyFunction{myUnderlyingFunction: underlyingFunction}
+}
+
+func (f MyFunction) Evaluate(x Element) Element {
+	return f.myUnderlyingFunction(x)
+}
+
+type MyFunctionParameterized struct {
 	myParams             []string
-	myUnderlyingFunction func(int, []interface{}) int
+	myUnderlyingFunction func(Element, []interface{}) Element
 }
 
-func (parameterized *myFunctionParameterized) specializeParams(args []interface{}) (myFunction, bool) {
+// params are the type names (as given by reflect) of the extra arguments
+func NewFunctionParameterized(params []string, underlyingFunction func(Element, []interface{}) Element) MyFunctionParameterized {
+	return MyFunctionParameterized{myParams: params, myUnderlyingFunction: underlyingFunction}
+}
+
+func (parameterized *MyFunctionParameterized) SpecializeParams(args []interface{}) (MyFunction, bool) {
 	if len(parameterized.myParams) != len(args) {
-		return myFunction{}, false
+		return MyFunction{}, false
 	}
 	success := true
 	for i, v := range parameterized.myParams {
 		success = reflect.TypeOf(args[i]).String() == v
 		if !success {
-			return myFunction{}, false
+			return MyFunction{}, false
 		}
 	}
-	return myFunction{myUnderlyingFunction: func(x int) int { return parameterized.myUnderlyingFunction(x, args) }}, true
+	return MyFunction{myUnderlyingFunction: func(x Element) Element { return parameterized.myUnderlyingFunction(x, args) }}, true
 }
 
-type myPartialFunction struct {
+type MyPartialFunction struct {
 	// if do i, ok = myDomain[x]: this can be true, true for things in the source and domain
 	// false, true for things in source but not defined for this map, false, false for things not even in source
-	myDomain             map[int]bool
-	myUnderlyingFunction func(int) int
+	myDomain             map[Element]bool
+	myUnderlyingFunction func(Element) Element
+	// defined on every element whatever myDomain says, only for the identity of an empty composite
+	everywhere bool
+}
+
+// only the elements of domain are considered defined
+func NewPartialFunction(domain []Element, underlyingFunction func(Element) Element) MyPartialFunction {
+	return MyPartialFunction{myDomain: presentElements(domain), myUnderlyingFunction: underlyingFunction}
 }
 
-type myPartialFunctionParameterized struct {
+// second return is false when x is outside the domain of definition
+func (f MyPartialFunction) Evaluate(x Element) (Element, bool) {
+	if !f.IsDefined(x) {
+		return Element{}, false
+	}
+	return f.myUnderlyingFunction(x), true
+}
+
+func (f MyPartialFunction) IsDefined(x Element) bool {
+	return f.everywhere || f.myDomain[x]
+}
+
+type MyPartialFunctionParameterized struct {
 	myParams             []string
-	myDomain             map[int]bool
-	myUnderlyingFunction func(int, []interface{}) int
+	myDomain             map[Element]bool
+	myUnderlyingFunction func(Element, []interface{}) Element
+}
+
+func NewPartialFunctionParameterized(params []string, domain []Element, underlyingFunction func(Element, []interface{}) Element) MyPartialFunctionParameterized {
+	return MyPartialFunctionParameterized{myParams: params, myDomain: presentElements(domain), myUnderlyingFunction: underlyingFunction}
 }
 
-func (parameterized *myPartialFunctionParameterized) specializeParams(args []interface{}) (myPartialFunction, bool) {
+func (parameterized *MyPartialFunctionParameterized) SpecializeParams(args []interface{}) (MyPartialFunction, bool) {
 	if len(parameterized.myParams) != len(args) {
-		return myPartialFunction{}, false
+		return MyPartialFunction{}, false
 	}
 	success := true
 	for i, v := range parameterized.myParams {
 		success = reflect.TypeOf(args[i]).String() == v
 		if !success {
-			return myPartialFunction{}, false
+			return MyPartialFunction{}, false
 		}
 	}
-	return myPartialFunction{myDomain: parameterized.myDomain, myUnderlyingFunction: func(x int) int { return parameterized.myUnderlyingFunction(x, args) }}, true
+	return MyPartialFunction{myDomain: parameterized.myDomain, myUnderlyingFunction: func(x Element) Element { return parameterized.myUnderlyingFunction(x, args) }}, true
+}
+
+type MyRelation struct {
+	myUnderlyingFunction func(Element) []Element
+}
+
+// x is related to every element of underlyingFunction(x)
+func NewRelation(underlyingFunction func(Element) []Element) MyRelation {
+	return MyRelation{myUnderlyingFunction: underlyingFunction}
 }
 
-type myRelation struct {
-	myUnderlyingFunction func(int) []int
+func (f MyRelation) Evaluate(x Element) []Element {
+	return f.myUnderlyingFunction(x)
 }
 
-type myRelationParameterized struct {
+type MyRelationParameterized struct {
 	myParams             []string
-	myUnderlyingFunction func(int, []interface{}) []int
+	myUnderlyingFunction func(Element, []interface{}) []Element
+}
+
+func NewRelationParameterized(params []string, underlyingFunction func(Element, []interface{}) []Element) MyRelationParameterized {
+	return MyRelationParameterized{myParams: params, myUnderlyingFunction: underlyingFunction}
 }
 
-func (parameterized *myRelationParameterized) specializeParams(args []interface{}) (myRelation, bool) {
+func (parameterized *MyRelationParameterized) SpecializeParams(args []interface{}) (MyRelation, bool) {
 	if len(parameterized.myParams) != len(args) {
-		return myRelation{}, false
+		return MyRelation{}, false
 	}
 	success := true
 	for i, v := range parameterized.myParams {
 		success = reflect.TypeOf(args[i]).String() == v
 		if !success {
-			return myRelation{}, false
+			return MyRelation{}, false
 		}
 	}
-	return myRelation{myUnderlyingFunction: func(x int) []int { return parameterized.myUnderlyingFunction(x, args) }}, true
+	return MyRelation{myUnderlyingFunction: func(x Element) []Element { return parameterized.myUnderlyingFunction(x, args) }}, true
 }
 
-type possiblyRelation interface {
-	CastToRelation(domain []int) myRelation
+type PossiblyRelation interface {
+	CastToRelation(domain []Element) MyRelation
 }
 
-type possiblyPartialFunction interface {
-	CastToPartialFunction(domain []int) myPartialFunction
-	possiblyRelation
+type PossiblyPartialFunction interface {
+	CastToPartialFunction(domain []Element) MyPartialFunction
+	PossiblyRelation
 }
 
-func castFToPF(f myFunction, domain []int) myPartialFunction {
-	myDomain2 := presentInts(domain)
-	return myPartialFunction{myDomain: myDomain2, myUnderlyingFunction: f.myUnderlyingFunction}
+func castFToPF(f MyFunction, domain []Element) MyPartialFunction {
+	myDomain2 := presentElements(domain)
+	return MyPartialFunction{myDomain: myDomain2, myUnderlyingFunction: f.myUnderlyingFunction}
 }
 
-func castFToR(f myFunction, domain []int) myRelation {
-	var toReturn map[int]([]int)
-	for _, currentInt := range domain {
-		toReturn[currentInt] = append(toReturn[currentInt], f.myUnderlyingFunction(currentInt))
+func castFToR(f MyFunction, domain []Element) MyRelation {
+	toReturn := make(map[Element]([]Element))
+	for _, currentElement := range domain {
+		toReturn[currentElement] = append(toReturn[currentElement], f.myUnderlyingFunction(currentElement))
 	}
-	return myRelation{myUnderlyingFunction: func(x int) []int { return toReturn[x] }}
+	return MyRelation{myUnderlyingFunction: func(x Element) []Element { return toReturn[x] }}
 }
-func castPFToR(f myPartialFunction, domain []int) myRelation {
-	toReturn := make(map[int]([]int))
-	for _, currentInt := range domain {
-		if f.myDomain[currentInt] {
-			toReturn[currentInt] = append(toReturn[currentInt], f.myUnderlyingFunction(currentInt))
+func castPFToR(f MyPartialFunction, domain []Element) MyRelation {
+	toReturn := make(map[Element]([]Element))
+	for _, currentElement := range domain {
+		if f.IsDefined(currentElement) {
+			toReturn[currentElement] = append(toReturn[currentElement], f.myUnderlyingFunction(currentElement))
 		}
 	}
-	return myRelation{myUnderlyingFunction: func(x int) []int { return toReturn[x] }}
+	return MyRelation{myUnderlyingFunction: func(x Element) []Element { return toReturn[x] }}
 }
 
-func (f myFunction) CastToPartialFunction(domain []int) myPartialFunction {
+func (f MyFunction) CastToPartialFunction(domain []Element) MyPartialFunction {
 	return castFToPF(f, domain)
 }
 
-func (f myFunction) CastToRelation(domain []int) myRelation {
+func (f MyFunction) CastToRelation(domain []Element) MyRelation {
 	return castFToR(f, domain)
 }
 
-func (f myPartialFunction) CastToPartialFunction(domain []int) myPartialFunction {
+func (f MyPartialFunction) CastToPartialFunction(domain []Element) MyPartialFunction {
 	return f
 }
 
-func (f myPartialFunction) CastToRelation(domain []int) myRelation {
+func (f MyPartialFunction) CastToRelation(domain []Element) MyRelation {
 	return castPFToR(f, domain)
 }
 
-func (f myRelation) CastToRelation(domain []int) myRelation {
+func (f MyRelation) CastToRelation(domain []Element) MyRelation {
 	return f
 }
 
-func (f myRelation) ReverseRelation(source []int, target []int) myRelation {
-	var gottenI []int
-	toReturn := make(map[int]([]int))
+func (f MyRelation) ReverseRelation(source []Element, target []Element) MyRelation {
+	var gottenI []Element
+	toReturn := make(map[Element]([]Element))
 	for _, i := range source {
 		gottenI = f.myUnderlyingFunction(i)
 		for _, j := range gottenI {
@@ -144,81 +194,110 @@ func (f myRelation) ReverseRelation(source []int, target []int) myRelation {
 			toReturn[j] = append(toReturn[j], i)
 		}
 	}
-	return myRelation{myUnderlyingFunction: func(x int) []int { return toReturn[x] }}
+	return MyRelation{myUnderlyingFunction: func(x Element) []Element { return toReturn[x] }}
+}
+
+func composeFunctions(f1, f2 MyFunction, domain1, domain2 []Element) MyFunction {
+	return MyFunction{myUnderlyingFunction: func(x Element) Element { return f2.myUnderlyingFunction(f1.myUnderlyingFunction(x)) }}
 }
 
-func composeFunctions(f1, f2 myFunction, domain1, domain2 []int) myFunction {
-	return myFunction{myUnderlyingFunction: func(x int) int { return f2.myUnderlyingFunction(f1.myUnderlyingFunction(x)) }}
+func IdentityFunction() MyFunction {
+	return MyFunction{myUnderlyingFunction: func(x Element) Element { return x }}
 }
 
-func composeManyFunctions(fList []myFunction, domainList [][]int) (myFunction, bool) {
+// the empty path composes to the identity
+func ComposeManyFunctions(fList []MyFunction, domainList [][]Element) (MyFunction, bool) {
 	fListLength := len(fList)
 	domainListLength := len(domainList)
 	if fListLength != domainListLength {
-		return myFunction{}, false
+		return MyFunction{}, false
+	}
+	if fListLength == 0 {
+		return IdentityFunction(), true
 	}
 	if fListLength == 1 {
 		return fList[0], true
 	} else {
 		f1 := fList[0]
 		domain1 := domainList[0]
-		f2, _ := composeManyFunctions(fList[1:], domainList[1:])
+		f2, _ := ComposeManyFunctions(fList[1:], domainList[1:])
 		domain2 := domainList[1]
 		return composeFunctions(f1, f2, domain1, domain2), true
 	}
 }
 
-func composePartials(f1, f2 possiblyPartialFunction, domain1, domain2 []int) myPartialFunction {
+func composePartials(f1, f2 PossiblyPartialFunction, domain1, domain2 []Element) MyPartialFunction {
 	f1Partialized := f1.CastToPartialFunction(domain1)
 	f2Partialized := f2.CastToPartialFunction(domain2)
-	var afterf1 int
+	var afterf1 Element
 	//combine f1Partialized and f2Partialized
-	modifiedDomain := make(map[int]bool, len(f1Partialized.myDomain))
-	for key, value := range f1Partialized.myDomain {
-		if value {
-			afterf1 = f1Partialized.myUnderlyingFunction(key)
-			if f2Partialized.myDomain[afterf1] {
-				modifiedDomain[key] = true
-			}
+	modifiedDomain := make(map[Element]bool, len(f1Partialized.myDomain))
+	for _, key := range f1Partialized.definedOn(domain1) {
+		afterf1 = f1Partialized.myUnderlyingFunction(key)
+		if f2Partialized.IsDefined(afterf1) {
+			modifiedDomain[key] = true
+		}
+	}
+	return MyPartialFunction{myDomain: modifiedDomain, myUnderlyingFunction: func(x Element) Element { return f2Partialized.myUnderlyingFunction(f1Partialized.myUnderlyingFunction(x)) }}
+}
+
+// the elements f is defined on, those of domain when it is defined everywhere
+func (f MyPartialFunction) definedOn(domain []Element) []Element {
+	if f.everywhere {
+		return domain
+	}
+	toReturn := make([]Element, 0, len(f.myDomain))
+	for x, defined := range f.myDomain {
+		if defined {
+			toReturn = append(toReturn, x)
 		}
 	}
-	return myPartialFunction{myDomain: modifiedDomain, myUnderlyingFunction: func(x int) int { return f2Partialized.myUnderlyingFunction(f1Partialized.myUnderlyingFunction(x)) }}
+	return toReturn
+}
+
+// defined on exactly domain
+func IdentityPartialFunction(domain []Element) MyPartialFunction {
+	return MyPartialFunction{myDomain: presentElements(domain), myUnderlyingFunction: func(x Element) Element { return x }}
 }
 
-func composeManyPartials(fList []possiblyPartialFunction, domainList [][]int) (myPartialFunction, bool) {
+// the empty path composes to the identity, defined on every element
+func ComposeManyPartials(fList []PossiblyPartialFunction, domainList [][]Element) (MyPartialFunction, bool) {
 	fListLength := len(fList)
 	domainListLength := len(domainList)
 	if fListLength != domainListLength {
-		return myPartialFunction{}, false
+		return MyPartialFunction{}, false
+	}
+	if fListLength == 0 {
+		return MyPartialFunction{everywhere: true, myUnderlyingFunction: func(x Element) Element { return x }}, true
 	}
 	if fListLength == 1 {
 		return fList[0].CastToPartialFunction(domainList[0]), true
 	} else {
 		f1 := fList[0]
 		domain1 := domainList[0]
-		f2, _ := composeManyPartials(fList[1:], domainList[1:])
+		f2, _ := ComposeManyPartials(fList[1:], domainList[1:])
 		domain2 := domainList[1]
 		return composePartials(f1, f2, domain1, domain2), true
 	}
 }
 
-func composeRelations(f1, f2 possiblyRelation, domain1, domain2 []int) myRelation {
+func composeRelations(f1, f2 PossiblyRelation, domain1, domain2 []Element) MyRelation {
 	f1Relationalized := f1.CastToRelation(domain1)
 	f2Relationalized := f2.CastToRelation(domain2)
 	//combine f1Partialized and f2Partialized
-	return myRelation{myUnderlyingFunction: func(x int) []int {
-		toReturn := make([]int, 0)
-		for y := range f1Relationalized.myUnderlyingFunction(x) {
+	return MyRelation{myUnderlyingFunction: func(x Element) []Element {
+		toReturn := make([]Element, 0)
+		for _, y := range f1Relationalized.myUnderlyingFunction(x) {
 			toReturn = append(toReturn, f2Relationalized.myUnderlyingFunction(y)...)
 		}
 		return removeDuplicates(toReturn)
 	}}
 }
 
-func removeDuplicates(elements []int) []int {
+func removeDuplicates(elements []Element) []Element {
 	// Use map to record duplicates as we find them.
-	encountered := map[int]bool{}
-	result := []int{}
+	encountered := map[Element]bool{}
+	result := []Element{}
 
 	for v := range elements {
 		if encountered[elements[v]] == true {
@@ -234,19 +313,108 @@ func removeDuplicates(elements []int) []int {
 	return result
 }
 
-func composeManyRelations(fList []possiblyRelation, domainList [][]int) (myRelation, bool) {
+// every element is related to itself and nothing else
+func IdentityRelation() MyRelation {
+	return MyRelation{myUnderlyingFunction: func(x Element) []Element { return []Element{x} }}
+}
+
+// the images as sets, so duplicates and order do not matter
+// returns the elements of lhs not in rhs and those of rhs not in lhs
+func CompareImages(lhs []Element, rhs []Element) ([]Element, []Element) {
+	lhsMap := presentElements(lhs)
+	rhsMap := presentElements(rhs)
+	onlyLHS := make([]Element, 0)
+	onlyRHS := make([]Element, 0)
+	for _, x := range removeDuplicates(lhs) {
+		if !rhsMap[x] {
+			onlyLHS = append(onlyLHS, x)
+		}
+	}
+	for _, x := range removeDuplicates(rhs) {
+		if !lhsMap[x] {
+			onlyRHS = append(onlyRHS, x)
+		}
+	}
+	return onlyLHS, onlyRHS
+}
+
+// the empty path composes to the identity
+func ComposeManyRelations(fList []PossiblyRelation, domainList [][]Element) (MyRelation, bool) {
 	fListLength := len(fList)
 	domainListLength := len(domainList)
 	if fListLength != domainListLength {
-		return myRelation{}, false
+		return MyRelation{}, false
+	}
+	if fListLength == 0 {
+		return IdentityRelation(), true
 	}
 	if fListLength == 1 {
 		return fList[0].CastToRelation(domainList[0]), true
 	} else {
 		f1 := fList[0]
 		domain1 := domainList[0]
-		f2, _ := composeManyRelations(fList[1:], domainList[1:])
+		f2, _ := ComposeManyRelations(fList[1:], domainList[1:])
 		domain2 := domainList[1]
 		return composeRelations(f1, f2, domain1, domain2), true
 	}
 }
+
+// an element of the source sent to Value which is not in the target
+type OutOfTarget struct {
+	Element Element
+	Value   Element
+}
+
+func FunctionOutOfTarget(sourceSet []Element, targetSet []Element, content MyFunction) []OutOfTarget {
+	targetSetMap := presentElements(targetSet)
+	toReturn := make([]OutOfTarget, 0)
+	for _, k := range sourceSet {
+		putativeT := content.myUnderlyingFunction(k)
+		if !targetSetMap[putativeT] {
+			toReturn = append(toReturn, OutOfTarget{Element: k, Value: putativeT})
+		}
+	}
+	return toReturn
+}
+
+func ValidateFunction(sourceSet []Element, targetSet []Element, content MyFunction) bool {
+	return len(FunctionOutOfTarget(sourceSet, targetSet, content)) == 0
+}
+
+// only elements in the domain of definition are checked
+func PartialFunctionOutOfTarget(sourceSet []Element, targetSet []Element, content MyPartialFunction) []OutOfTarget {
+	targetSetMap := presentElements(targetSet)
+	toReturn := make([]OutOfTarget, 0)
+	for _, k := range sourceSet {
+		if content.IsDefined(k) {
+			putativeT := content.myUnderlyingFunction(k)
+			if !targetSetMap[putativeT] {
+				toReturn = append(toReturn, OutOfTarget{Element: k, Value: putativeT})
+			}
+		}
+	}
+	return toReturn
+}
+
+func ValidatePartialFunction(sourceSet []Element, targetSet []Element, content MyPartialFunction) bool {
+	return len(PartialFunctionOutOfTarget(sourceSet, targetSet, content)) == 0
+}
+
+// one entry per related pair whose second element is not in the target
+func RelationOutOfTarget(sourceSet []Element, targetSet []Element, content MyRelation) []OutOfTarget {
+	targetSetMap := presentElements(targetSet)
+	toReturn := make([]OutOfTarget, 0)
+	for _, sourceItem := range sourceSet {
+		allTargets := content.myUnderlyingFunction(sourceItem)
+		for _, x := range allTargets {
+			if !targetSetMap[x] {
+				toReturn = append(toReturn, OutOfTarget{Element: sourceItem, Value: x})
+			}
+		}
+	}
+	return toReturn
+}
+
+func ValidateRelation(sourceSet []Element, targetSet []Element, content MyRelation) bool {
+	return len(RelationOutOfTarget(sourceSet, targetSet, content)) == 0
+}
@@ -0,0 +1,32 @@
+package morphismTypes
+
+import "testing"
+
+func TestComposeManyPartialsEmpty(t *testing.T) {
+	identity, ok := ComposeManyPartials(nil, nil)
+	if !ok {
+		t.Fatal("could not compose no partial functions")
+	}
+	x := NewStringElement("x")
+	if got, defined := identity.Evaluate(x); !defined || got != x {
+		t.Errorf("identity at x is %v defined %v, want x", got, defined)
+	}
+}
+
+func TestComposeManyPartials(t *testing.T) {
+	domain := NewIntElements([]int{1, 2, 3})
+	half := NewPartialFunction(NewIntElements([]int{2}), func(x Element) Element {
+		i, _ := x.IntValue()
+		return NewIntElement(i / 2)
+	})
+	composite, ok := ComposeManyPartials([]PossiblyPartialFunction{IdentityPartialFunction(domain), half}, [][]Element{domain, domain})
+	if !ok {
+		t.Fatal("could not compose")
+	}
+	if got, defined := composite.Evaluate(NewIntElement(2)); !defined || got != NewIntElement(1) {
+		t.Errorf("composite at 2 is %v defined %v, want 1", got, defined)
+	}
+	if composite.IsDefined(NewIntElement(3)) {
+		t.Error("composite is defined at 3")
+	}
+}
@@ -0,0 +1,259 @@
+package morphismTypes
+
+import "encoding/json"
+
+// morphisms stored as explicit data rather than closures, so they can be saved, compared and inspected
+// keys remember the order they were first given in so that listing and JSON output are stable
+
+type FunctionTable struct {
+	keys   []Element
+	values map[Element]Element
+}
+
+// the function defined on exactly the keys of values
+func NewFunctionTable(values map[Element]Element) FunctionTable {
+	toReturn := FunctionTable{keys: make([]Element, 0, len(values)), values: make(map[Element]Element, len(values))}
+	for _, x := range sortedKeys(values) {
+		toReturn.Set(x, values[x])
+	}
+	return toReturn
+}
+
+// evaluates f on each element of domain and remembers the answers
+func TabulateFunction(f MyFunction, domain []Element) FunctionTable {
+	toReturn := FunctionTable{keys: make([]Element, 0, len(domain)), values: make(map[Element]Element, len(domain))}
+	for _, x := range domain {
+		toReturn.Set(x, f.myUnderlyingFunction(x))
+	}
+	return toReturn
+}
+
+// replaces the value at x if there already was one
+func (table *FunctionTable) Set(x Element, y Element) {
+	if table.values == nil {
+		table.values = make(map[Element]Element)
+	}
+	if _, present := table.values[x]; !present {
+		table.keys = append(table.keys, x)
+	}
+	table.values[x] = y
+}
+
+// second return is false when x is not a key of the table
+func (table FunctionTable) Evaluate(x Element) (Element, bool) {
+	y, present := table.values[x]
+	return y, present
+}
+
+// the keys in the order they were first set
+func (table FunctionTable) Keys() []Element {
+	return append([]Element{}, table.keys...)
+}
+
+func (table FunctionTable) Len() int {
+	return len(table.keys)
+}
+
+// a closure over a copy of the table, elements which are not keys go to the zero Element
+func (table FunctionTable) AsFunction() MyFunction {
+	myValues := make(map[Element]Element, len(table.values))
+	for x, y := range table.values {
+		myValues[x] = y
+	}
+	return MyFunction{myUnderlyingFunction: func(x Element) Element { return myValues[x] }}
+}
+
+func (table FunctionTable) CastToPartialFunction(domain []Element) MyPartialFunction {
+	return tableDomain(table.values, domain).AsPartialFunction()
+}
+
+func (table FunctionTable) CastToRelation(domain []Element) MyRelation {
+	return tableDomain(table.values, domain).CastToRelation(domain)
+}
+
+// the entries of values whose key is in domain
+func tableDomain(values map[Element]Element, domain []Element) PartialFunctionTable {
+	toReturn := PartialFunctionTable{}
+	for _, x := range domain {
+		if y, present := values[x]; present {
+			toReturn.Set(x, y)
+		}
+	}
+	return toReturn
+}
+
+// same as a FunctionTable but the keys are read as the domain of definition
+// rather than the whole source
+type PartialFunctionTable struct {
+	FunctionTable
+}
+
+func NewPartialFunctionTable(values map[Element]Element) PartialFunctionTable {
+	return PartialFunctionTable{FunctionTable: NewFunctionTable(values)}
+}
+
+// only the elements of domain on which f is defined become keys
+func TabulatePartialFunction(f MyPartialFunction, domain []Element) PartialFunctionTable {
+	toReturn := PartialFunctionTable{}
+	for _, x := range domain {
+		if f.IsDefined(x) {
+			toReturn.Set(x, f.myUnderlyingFunction(x))
+		}
+	}
+	return toReturn
+}
+
+func (table PartialFunctionTable) IsDefined(x Element) bool {
+	_, present := table.values[x]
+	return present
+}
+
+func (table PartialFunctionTable) AsPartialFunction() MyPartialFunction {
+	return MyPartialFunction{myDomain: presentElements(table.keys), myUnderlyingFunction: table.AsFunction().myUnderlyingFunction}
+}
+
+func (table PartialFunctionTable) CastToPartialFunction(domain []Element) MyPartialFunction {
+	return table.AsPartialFunction()
+}
+
+func (table PartialFunctionTable) CastToRelation(domain []Element) MyRelation {
+	return castPFToR(table.AsPartialFunction(), domain)
+}
+
+type RelationTable struct {
+	keys    []Element
+	related map[Element]([]Element)
+}
+
+func NewRelationTable(related map[Element]([]Element)) RelationTable {
+	toReturn := RelationTable{}
+	for _, x := range sortedKeys(related) {
+		for _, y := range related[x] {
+			toReturn.Add(x, y)
+		}
+	}
+	return toReturn
+}
+
+// the pairs (x, y) with x in domain and y in f(x)
+func TabulateRelation(f MyRelation, domain []Element) RelationTable {
+	toReturn := RelationTable{}
+	for _, x := range domain {
+		for _, y := range f.myUnderlyingFunction(x) {
+			toReturn.Add(x, y)
+		}
+	}
+	return toReturn
+}
+
+// relates x to y, nothing changes if they already were
+func (table *RelationTable) Add(x Element, y Element) {
+	if table.related == nil {
+		table.related = make(map[Element]([]Element))
+	}
+	existing, present := table.related[x]
+	if !present {
+		table.keys = append(table.keys, x)
+	}
+	for _, z := range existing {
+		if z == y {
+			return
+		}
+	}
+	table.related[x] = append(existing, y)
+}
+
+// a copy of everything x is related to, in the order the pairs were added
+func (table RelationTable) Evaluate(x Element) []Element {
+	return append([]Element{}, table.related[x]...)
+}
+
+// the elements which are related to something, in the order they were first added
+func (table RelationTable) Keys() []Element {
+	return append([]Element{}, table.keys...)
+}
+
+// the number of related pairs
+func (table RelationTable) Len() int {
+	toReturn := 0
+	for _, x := range table.keys {
+		toReturn = toReturn + len(table.related[x])
+	}
+	return toReturn
+}
+
+func (table RelationTable) AsRelation() MyRelation {
+	myRelated := make(map[Element]([]Element), len(table.related))
+	for x, ys := range table.related {
+		myRelated[x] = append([]Element{}, ys...)
+	}
+	return MyRelation{myUnderlyingFunction: func(x Element) []Element { return myRelated[x] }}
+}
+
+func (table RelationTable) CastToRelation(domain []Element) MyRelation {
+	return table.AsRelation()
+}
+
+// map keys in order of their encoding, so building a table from a map does not depend on map iteration order
+func sortedKeys[V any](m map[Element]V) []Element {
+	toReturn := make([]Element, 0, len(m))
+	for x := range m {
+		toReturn = append(toReturn, x)
+	}
+	sortElements(toReturn)
+	return toReturn
+}
+
+// in JSON a table is a list of entries in key order
+
+type functionEntryJSON struct {
+	From Element `json:"from"`
+	To   Element `json:"to"`
+}
+
+type relationEntryJSON struct {
+	From Element   `json:"from"`
+	To   []Element `json:"to"`
+}
+
+func (table FunctionTable) MarshalJSON() ([]byte, error) {
+	entries := make([]functionEntryJSON, len(table.keys))
+	for i, x := range table.keys {
+		entries[i] = functionEntryJSON{From: x, To: table.values[x]}
+	}
+	return json.Marshal(entries)
+}
+
+func (table *FunctionTable) UnmarshalJSON(data []byte) error {
+	var entries []functionEntryJSON
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	*table = FunctionTable{}
+	for _, entry := range entries {
+		table.Set(entry.From, entry.To)
+	}
+	return nil
+}
+
+func (table RelationTable) MarshalJSON() ([]byte, error) {
+	entries := make([]relationEntryJSON, len(table.keys))
+	for i, x := range table.keys {
+		entries[i] = relationEntryJSON{From: x, To: table.related[x]}
+	}
+	return json.Marshal(entries)
+}
+
+func (table *RelationTable) UnmarshalJSON(data []byte) error {
+	var entries []relationEntryJSON
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	*table = RelationTable{}
+	for _, entry := range entries {
+		for _, y := range entry.To {
+			table.Add(entry.From, y)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package relationalGraphDB
+
+import (
+	"fmt"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// which kind of element a value of the sort has to be
+func SortKind(sort cgs.Sort) homs.ElementKind {
+	switch sort {
+	case cgs.IntSort:
+		return homs.IntKind
+	case cgs.FloatSort:
+		return homs.FloatKind
+	case cgs.BoolSort:
+		return homs.BoolKind
+	case cgs.DateSort:
+		return homs.DateKind
+	}
+	return homs.StringKind
+}
+
+func (currentDB *InstantiatedDB) GetAttribute(edge cgs.AttributeEdge) (homs.MyFunction, bool) {
+	toReturn, present := currentDB.underlyingAttributes[edge]
+	return toReturn, present
+}
+
+func sortMismatchErrors(edge cgs.AttributeEdge, sourceSet []homs.Element, content homs.MyFunction) ValidationErrors {
+	toReturn := make(ValidationErrors, 0)
+	expectedKind := SortKind(edge.GetSort())
+	for _, x := range sourceSet {
+		value := content.Evaluate(x)
+		if value.GetKind() != expectedKind {
+			toReturn = append(toReturn, ValidationError{Kind: AttributeSortMismatch, Vertex: edge.GetSource().GetIdentifier(), Edge: edge.GetIdentifier(),
+				Element: x, HasElement: true, Detail: fmt.Sprintf("value %v is not a %s", value, edge.GetSort())})
+		}
+	}
+	return toReturn
+}
+
+// every element of the source must get a value of the declared sort
+func validateAttributes(potentialDB InstantiatedDB) ValidationErrors {
+	toReturn := make(ValidationErrors, 0)
+	for _, edge := range potentialDB.underlyingGraph.GetAttributeEdges() {
+		content, present := potentialDB.underlyingAttributes[edge]
+		if !present {
+			toReturn = append(toReturn, ValidationError{Kind: MissingMorphism, Edge: edge.GetIdentifier(), Detail: "no data given for this attribute"})
+			continue
+		}
+		toReturn = append(toReturn, sortMismatchErrors(edge, potentialDB.underlyingSets[edge.GetSource()], content)...)
+	}
+	return toReturn
+}
+
+// adding an attribute column to an existing vertex, content gives the value on each element
+func (currentDB *InstantiatedDB) AddAttributeEdge(newSource string, sort cgs.Sort, description string, content homs.MyFunction) error {
+	if err := currentDB.beginChange(); err != nil {
+		return err
+	}
+	if currentDB.underlyingGraph.HasEdgeNamed(description) {
+		return ValidationErrors{duplicateEdge(description)}
+	}
+	mySchema := currentDB.underlyingGraph.Copy()
+	result := mySchema.AddAttributeEdge2(newSource, sort, description)
+	if !result {
+		return ValidationErrors{{Kind: InvalidSchema, Vertex: newSource, Edge: description, Detail: "source is not a vertex of the schema"}}
+	}
+	thisEdge, _ := mySchema.GetAttributeEdgeByName(description)
+	if !currentDB.deferValidation {
+		problems := sortMismatchErrors(thisEdge, currentDB.underlyingSets[thisEdge.GetSource()], content)
+		if len(problems) > 0 {
+			return problems
+		}
+	}
+	currentDB.underlyingGraph = mySchema
+	currentDB.underlyingAttributes[thisEdge] = content
+	return nil
+}
+
+type AttributeEquationReport struct {
+	Equation        cgs.AttributeEquation
+	Counterexamples []Counterexample
+}
+
+func (report AttributeEquationReport) Holds() bool {
+	return len(report.Counterexamples) == 0
+}
+
+func (potentialDB *InstantiatedDB) attributeTerm(path []cgs.FunctionEdge, attribute cgs.AttributeEdge) (homs.MyFunction, bool) {
+	pathFunction, valid := potentialDB.functionPath(path)
+	if !valid {
+		return homs.MyFunction{}, false
+	}
+	attributeFunction, present := potentialDB.underlyingAttributes[attribute]
+	if !present {
+		return homs.MyFunction{}, false
+	}
+	return homs.ComposeManyFunctions([]homs.MyFunction{pathFunction, attributeFunction}, [][]homs.Element{
+		potentialDB.underlyingSets[attribute.GetSource()], potentialDB.underlyingSets[attribute.GetSource()]})
+}
+
+// evaluate both attribute terms on every element of the common source
+// equations which mention an edge with no data are skipped, validateFunctions and validateAttributes report those
+func CheckAttributeEquations(potentialDB InstantiatedDB) []AttributeEquationReport {
+	myEquations := potentialDB.underlyingGraph.GetAttributeEquations()
+	toReturn := make([]AttributeEquationReport, 0, len(myEquations))
+	for _, eq := range myEquations {
+		toReturn = append(toReturn, checkAttributeEquation(&potentialDB, eq))
+	}
+	return toReturn
+}
+
+func checkAttributeEquation(potentialDB *InstantiatedDB, eq cgs.AttributeEquation) AttributeEquationReport {
+	return checkAttributeEquationOn(potentialDB, eq, potentialDB.underlyingSets[eq.GetSource()])
+}
+
+func checkAttributeEquationOn(potentialDB *InstantiatedDB, eq cgs.AttributeEquation, elements []homs.Element) AttributeEquationReport {
+	report := AttributeEquationReport{Equation: eq, Counterexamples: make([]Counterexample, 0)}
+	lhs, validLHS := potentialDB.attributeTerm(eq.GetLHSPath(), eq.GetLHSAttribute())
+	rhs, validRHS := potentialDB.attributeTerm(eq.GetRHSPath(), eq.GetRHSAttribute())
+	if !validLHS || !validRHS {
+		return report
+	}
+	for _, x := range elements {
+		lhsValue := lhs.Evaluate(x)
+		rhsValue := rhs.Evaluate(x)
+		if lhsValue != rhsValue {
+			report.Counterexamples = append(report.Counterexamples, Counterexample{Element: x, LHSValue: lhsValue, RHSValue: rhsValue})
+		}
+	}
+	return report
+}
+
+func (report AttributeEquationReport) asValidationErrors() ValidationErrors {
+	toReturn := make(ValidationErrors, 0, len(report.Counterexamples))
+	for _, counterexample := range report.Counterexamples {
+		toReturn = append(toReturn, ValidationError{Kind: AttributeEquationViolated, Vertex: report.Equation.GetSource().GetIdentifier(),
+			Equation: report.Equation.GetIdentifier(), Element: counterexample.Element, HasElement: true,
+			Detail: fmt.Sprintf("lhs gives %v but rhs gives %v", counterexample.LHSValue, counterexample.RHSValue)})
+	}
+	return toReturn
+}
+
+func validateAttributeEquations(potentialDB InstantiatedDB) ValidationErrors {
+	toReturn := make(ValidationErrors, 0)
+	for _, report := range CheckAttributeEquations(potentialDB) {
+		toReturn = append(toReturn, report.asValidationErrors()...)
+	}
+	return toReturn
+}
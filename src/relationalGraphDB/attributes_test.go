@@ -0,0 +1,57 @@
+package relationalGraphDB
+
+import (
+	"testing"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// names are strings, so a number is refused for a new column, a new element and the data as a whole
+func TestAttributeSortMismatch(t *testing.T) {
+	db := companyDB(t)
+	s := homs.NewStringElement
+	ages := homs.NewFunctionTable(map[homs.Element]homs.Element{s("al"): homs.NewIntElement(30), s("bo"): s("forty"), s("cy"): homs.NewIntElement(50)})
+	mismatch := expectKind(t, db.AddAttributeEdge("Person", cgs.IntSort, "age", ages.AsFunction()), AttributeSortMismatch)
+	if mismatch.Edge != "age" || mismatch.Element != s("bo") {
+		t.Errorf("mismatch on %s at %v, want age at bo", mismatch.Edge, mismatch.Element)
+	}
+	if db.underlyingGraph.HasEdgeNamed("age") {
+		t.Error("age was added although bo's age is not an int")
+	}
+
+	err := db.AddElementToSet("Person", s("di"), ElementInsertion{FunctionValues: map[string]homs.Element{"worksIn": s("ops")},
+		AttributeValues: map[string]homs.Element{"name": homs.NewIntElement(4)}})
+	mismatch = expectKind(t, err, AttributeSortMismatch)
+	if mismatch.Edge != "name" || mismatch.Element != s("di") {
+		t.Errorf("mismatch on %s at %v, want name at di", mismatch.Edge, mismatch.Element)
+	}
+
+	name := attributeEdge(t, db.underlyingGraph, "name")
+	db.underlyingAttributes[name] = homs.NewFunctionTable(map[homs.Element]homs.Element{s("al"): s("Al"), s("bo"): homs.NewBoolElement(true), s("cy"): s("Cy")}).AsFunction()
+	problems := ValidateDB(db)
+	if len(problems) != 1 || problems[0].Kind != AttributeSortMismatch || problems[0].Element != s("bo") {
+		t.Errorf("violations %v, want a sort mismatch at bo", problems)
+	}
+}
+
+// bo is named Bo but works in sales, whose head is Al
+func TestCheckAttributeEquations(t *testing.T) {
+	db := companyDB(t)
+	mustSucceed(t, db.underlyingGraph.AddAttributeEquation2([]string{"worksIn", "head"}, "name", []string{}, "name", "named after the head"),
+		"add named after the head")
+	s := homs.NewStringElement
+	reports := CheckAttributeEquations(db)
+	if len(reports) != 1 || reports[0].Equation.GetIdentifier() != "named after the head" {
+		t.Fatalf("reports %v", reports)
+	}
+	want := Counterexample{Element: s("bo"), LHSValue: s("Al"), RHSValue: s("Bo")}
+	if counterexamples := reports[0].Counterexamples; len(counterexamples) != 1 || counterexamples[0] != want {
+		t.Errorf("counterexamples %v, want %v", counterexamples, want)
+	}
+	problems := ValidateDB(db)
+	if len(problems) != 1 || problems[0].Kind != AttributeEquationViolated || problems[0].Equation != "named after the head" ||
+		problems[0].Vertex != "Person" || problems[0].Element != s("bo") {
+		t.Errorf("violations %v, want named after the head at bo", problems)
+	}
+}
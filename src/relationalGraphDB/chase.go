@@ -0,0 +1,518 @@
+package relationalGraphDB
+
+import (
+	"fmt"
+	"sort"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// the saturation of some starting facts under the rules of a schema
+// elements are numbered in the order they are made and kept in a union find whose roots are the smallest number in each class
+// so the classes, their numbering and the names given to them at the end do not depend on map iteration order
+//
+// a round first applies every rule to the current classes, collecting the elements to merge,
+// and then merges them along with everything congruence forces to be merged
+// the chase is finished after a round that changes nothing
+type chase struct {
+	schema *cgs.SchemaGraph
+	edges  map[string]chaseEdge
+	parent []int
+	vertex []cgs.Vertex
+	origin []chaseOrigin
+	// by edge name, function and partial function edges share values and relation edges share related
+	values     map[string]map[int]int
+	related    map[string]map[int]map[int]bool
+	attributes map[string]map[int]homs.Element
+	pending    [][2]int
+	conflicts  ValidationErrors
+	changed    bool
+}
+
+type chaseEdgeKind int
+
+const (
+	chaseFunction chaseEdgeKind = iota
+	chasePartialFunction
+	chaseRelation
+)
+
+type chaseEdge struct {
+	name   string
+	kind   chaseEdgeKind
+	source cgs.Vertex
+	target cgs.Vertex
+}
+
+// where an element came from, either a starting element or one made to be the value of edge on from
+// witness is set for the elements made to relate from to something along a relation edge
+type chaseOrigin struct {
+	starting bool
+	element  homs.Element
+	edge     string
+	from     int
+	witness  bool
+}
+
+func newChase(schema *cgs.SchemaGraph) *chase {
+	toReturn := &chase{schema: schema, edges: make(map[string]chaseEdge), values: make(map[string]map[int]int),
+		related: make(map[string]map[int]map[int]bool), attributes: make(map[string]map[int]homs.Element)}
+	for _, edge := range schema.GetFunctionEdges() {
+		toReturn.edges[edge.GetIdentifier()] = chaseEdge{name: edge.GetIdentifier(), kind: chaseFunction, source: edge.GetSource(), target: edge.GetTarget()}
+		toReturn.values[edge.GetIdentifier()] = make(map[int]int)
+	}
+	for _, edge := range schema.GetPartialFunctionEdges() {
+		toReturn.edges[edge.GetIdentifier()] = chaseEdge{name: edge.GetIdentifier(), kind: chasePartialFunction, source: edge.GetSource(), target: edge.GetTarget()}
+		toReturn.values[edge.GetIdentifier()] = make(map[int]int)
+	}
+	for _, edge := range schema.GetRelationEdges() {
+		toReturn.edges[edge.GetIdentifier()] = chaseEdge{name: edge.GetIdentifier(), kind: chaseRelation, source: edge.GetSource(), target: edge.GetTarget()}
+		toReturn.related[edge.GetIdentifier()] = make(map[int]map[int]bool)
+	}
+	for _, edge := range schema.GetAttributeEdges() {
+		toReturn.attributes[edge.GetIdentifier()] = make(map[int]homs.Element)
+	}
+	return toReturn
+}
+
+func (state *chase) newElement(vertex cgs.Vertex, origin chaseOrigin) int {
+	state.parent = append(state.parent, len(state.parent))
+	state.vertex = append(state.vertex, vertex)
+	state.origin = append(state.origin, origin)
+	state.changed = true
+	return len(state.parent) - 1
+}
+
+func (state *chase) find(x int) int {
+	for state.parent[x] != x {
+		state.parent[x] = state.parent[state.parent[x]]
+		x = state.parent[x]
+	}
+	return x
+}
+
+// the classes of vertex in order, each given by its root
+func (state *chase) elementsOf(vertex cgs.Vertex) []int {
+	toReturn := make([]int, 0)
+	for x := range state.parent {
+		if state.parent[x] == x && state.vertex[x] == vertex {
+			toReturn = append(toReturn, x)
+		}
+	}
+	return toReturn
+}
+
+func (state *chase) pathEdges(path []string) []chaseEdge {
+	toReturn := make([]chaseEdge, len(path))
+	for i, name := range path {
+		toReturn[i] = state.edges[name]
+	}
+	return toReturn
+}
+
+// the elements path relates x to, in order
+func (state *chase) image(x int, path []chaseEdge) []int {
+	current := []int{x}
+	for _, edge := range path {
+		next := make(map[int]bool)
+		for _, y := range current {
+			if edge.kind == chaseRelation {
+				for z := range state.related[edge.name][y] {
+					next[z] = true
+				}
+			} else if z, defined := state.values[edge.name][y]; defined {
+				next[z] = true
+			}
+		}
+		current = sortedKeys(next)
+	}
+	return current
+}
+
+// the value of a path of function and partial function edges, second return is false if it is not yet defined
+func (state *chase) value(x int, path []chaseEdge) (int, bool) {
+	for _, edge := range path {
+		next, defined := state.values[edge.name][x]
+		if !defined {
+			return 0, false
+		}
+		x = next
+	}
+	return x, true
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	toReturn := make([]int, 0, len(m))
+	for k := range m {
+		toReturn = append(toReturn, k)
+	}
+	sort.Ints(toReturn)
+	return toReturn
+}
+
+func (state *chase) relate(edge string, x int, y int) {
+	if state.related[edge][x] == nil {
+		state.related[edge][x] = make(map[int]bool)
+	}
+	if !state.related[edge][x][y] {
+		state.related[edge][x][y] = true
+		state.changed = true
+	}
+}
+
+// makes path relate x to y, adding values where the path is undefined and new elements for relation edges in the middle
+func (state *chase) ensurePath(x int, path []chaseEdge, y int) {
+	for _, z := range state.image(x, path) {
+		if z == y {
+			return
+		}
+	}
+	if len(path) == 0 {
+		state.pending = append(state.pending, [2]int{x, y})
+		return
+	}
+	current := x
+	for i, edge := range path {
+		last := i == len(path)-1
+		if edge.kind == chaseRelation {
+			if last {
+				state.relate(edge.name, current, y)
+				break
+			}
+			next := state.newElement(edge.target, chaseOrigin{edge: edge.name, from: current, witness: true})
+			state.relate(edge.name, current, next)
+			current = next
+			continue
+		}
+		next, defined := state.values[edge.name][current]
+		switch {
+		case defined && last:
+			state.pending = append(state.pending, [2]int{next, y})
+		case last:
+			state.values[edge.name][current] = y
+			state.changed = true
+		case !defined:
+			next = state.newElement(edge.target, chaseOrigin{edge: edge.name, from: current})
+			state.values[edge.name][current] = next
+		}
+		current = next
+	}
+}
+
+// the element at the end of path from x, making values where the path is undefined
+func (state *chase) follow(x int, path []chaseEdge) int {
+	for _, edge := range path {
+		next, defined := state.values[edge.name][x]
+		if !defined {
+			next = state.newElement(edge.target, chaseOrigin{edge: edge.name, from: x})
+			state.values[edge.name][x] = next
+		}
+		x = next
+	}
+	return x
+}
+
+func (state *chase) conflict(attribute string, x int, first homs.Element, second homs.Element) {
+	state.conflicts = append(state.conflicts, ValidationError{Kind: ConflictingValues, Vertex: state.vertex[x].GetIdentifier(), Edge: attribute,
+		Detail: fmt.Sprintf("one element would need both %v and %v", first, second)})
+}
+
+func (state *chase) setAttribute(attribute string, x int, value homs.Element) {
+	existing, defined := state.attributes[attribute][x]
+	if !defined {
+		state.attributes[attribute][x] = value
+		state.changed = true
+	} else if existing != value {
+		state.conflict(attribute, x, existing, value)
+	}
+}
+
+// every function edge gets a value on every element
+func (state *chase) applyTotality() {
+	for _, edge := range state.schema.GetFunctionEdges() {
+		for _, x := range state.elementsOf(edge.GetSource()) {
+			state.follow(x, state.pathEdges([]string{edge.GetIdentifier()}))
+		}
+	}
+}
+
+// when one side of an equation is defined the other is made to agree with it
+// for a Kleene equation only sides that are both defined are made equal
+func (state *chase) applyFunctionEquation(lhs []chaseEdge, rhs []chaseEdge, source cgs.Vertex, kleene bool) {
+	for _, x := range state.elementsOf(source) {
+		lhsValue, lhsDefined := state.value(x, lhs)
+		rhsValue, rhsDefined := state.value(x, rhs)
+		switch {
+		case lhsDefined && rhsDefined:
+			if lhsValue != rhsValue {
+				state.pending = append(state.pending, [2]int{lhsValue, rhsValue})
+			}
+		case kleene:
+			// an undefined side of a Kleene equation agrees with anything
+		case lhsDefined:
+			state.ensurePath(x, rhs, lhsValue)
+		case rhsDefined:
+			state.ensurePath(x, lhs, rhsValue)
+		}
+	}
+}
+
+func (state *chase) applyRelationEquation(lhs []chaseEdge, rhs []chaseEdge, source cgs.Vertex, equality bool) {
+	for _, x := range state.elementsOf(source) {
+		for _, y := range state.image(x, lhs) {
+			state.ensurePath(x, rhs, y)
+		}
+		if equality {
+			for _, y := range state.image(x, rhs) {
+				state.ensurePath(x, lhs, y)
+			}
+		}
+	}
+}
+
+func (state *chase) applyAttributeEquation(eq cgs.AttributeEquation) {
+	lhs, rhs := state.pathEdges(edgeNames(eq.GetLHSPath())), state.pathEdges(edgeNames(eq.GetRHSPath()))
+	lhsAttribute, rhsAttribute := eq.GetLHSAttribute().GetIdentifier(), eq.GetRHSAttribute().GetIdentifier()
+	for _, x := range state.elementsOf(eq.GetSource()) {
+		var lhsValue, rhsValue homs.Element
+		lhsDefined, rhsDefined := false, false
+		if end, defined := state.value(x, lhs); defined {
+			lhsValue, lhsDefined = state.attributes[lhsAttribute][end]
+		}
+		if end, defined := state.value(x, rhs); defined {
+			rhsValue, rhsDefined = state.attributes[rhsAttribute][end]
+		}
+		switch {
+		case lhsDefined && rhsDefined:
+			if lhsValue != rhsValue {
+				state.conflict(lhsAttribute, x, lhsValue, rhsValue)
+			}
+		case lhsDefined:
+			state.setAttribute(rhsAttribute, state.follow(x, rhs), lhsValue)
+		case rhsDefined:
+			state.setAttribute(lhsAttribute, state.follow(x, lhs), rhsValue)
+		}
+	}
+}
+
+func edgeNames[E cgs.UnspecifiedEdge](path []E) []string {
+	toReturn := make([]string, len(path))
+	for i, edge := range path {
+		toReturn[i] = edge.GetIdentifier()
+	}
+	return toReturn
+}
+
+func (state *chase) applyRules() {
+	state.applyTotality()
+	for _, eq := range state.schema.GetFunctionEquations() {
+		if source, nonTrivial := equationSource(eq); nonTrivial {
+			state.applyFunctionEquation(state.pathEdges(edgeNames(eq.GetLHS())), state.pathEdges(edgeNames(eq.GetRHS())), source, false)
+		}
+	}
+	for _, eq := range state.schema.GetPartialFunctionEquations() {
+		if source, nonTrivial := equationSource(eq); nonTrivial {
+			state.applyFunctionEquation(state.pathEdges(edgeNames(eq.GetLHS())), state.pathEdges(edgeNames(eq.GetRHS())), source,
+				eq.GetEquality() == cgs.KleeneEquality)
+		}
+	}
+	for _, eq := range state.schema.GetRelationEquations() {
+		if source, nonTrivial := equationSource(eq); nonTrivial {
+			state.applyRelationEquation(state.pathEdges(edgeNames(eq.GetLHS())), state.pathEdges(edgeNames(eq.GetRHS())), source,
+				eq.GetComparison() == cgs.SetEquality)
+		}
+	}
+	for _, eq := range state.schema.GetAttributeEquations() {
+		state.applyAttributeEquation(eq)
+	}
+}
+
+// merges the pending pairs and then whatever has to be merged so that functions stay functions
+func (state *chase) merge() {
+	for len(state.pending) > 0 {
+		for _, pair := range state.pending {
+			x, y := state.find(pair[0]), state.find(pair[1])
+			if x == y {
+				continue
+			}
+			if y < x {
+				x, y = y, x
+			}
+			state.parent[y] = x
+			state.changed = true
+		}
+		state.pending = state.pending[:0]
+		for name, values := range state.values {
+			canonical := make(map[int]int, len(values))
+			for _, x := range sortedKeys(values) {
+				root, value := state.find(x), state.find(values[x])
+				if existing, defined := canonical[root]; defined && existing != value {
+					state.pending = append(state.pending, [2]int{existing, value})
+					continue
+				}
+				canonical[root] = value
+			}
+			state.values[name] = canonical
+		}
+	}
+	for name, related := range state.related {
+		canonical := make(map[int]map[int]bool, len(related))
+		for x, ys := range related {
+			root := state.find(x)
+			if canonical[root] == nil {
+				canonical[root] = make(map[int]bool, len(ys))
+			}
+			for y := range ys {
+				canonical[root][state.find(y)] = true
+			}
+		}
+		state.related[name] = canonical
+	}
+	for name, values := range state.attributes {
+		canonical := make(map[int]homs.Element, len(values))
+		for _, x := range sortedKeys(values) {
+			root := state.find(x)
+			if existing, defined := canonical[root]; defined && existing != values[x] {
+				state.conflict(name, root, existing, values[x])
+				continue
+			}
+			canonical[root] = values[x]
+		}
+		state.attributes[name] = canonical
+	}
+}
+
+// applies the rules until a round changes nothing, failing once more than maxRounds rounds have changed something
+// so starting facts that already satisfy the rules need no rounds at all
+func (state *chase) run(maxRounds int) ValidationErrors {
+	state.merge()
+	for round := 0; ; round++ {
+		if len(state.conflicts) > 0 {
+			return state.conflicts
+		}
+		state.changed = false
+		state.applyRules()
+		state.merge()
+		if !state.changed {
+			return state.conflicts
+		}
+		if round == maxRounds {
+			return ValidationErrors{{Kind: ChaseDidNotTerminate,
+				Detail: fmt.Sprintf("still adding elements or merging them after %d rounds, with %d elements made so far", maxRounds, len(state.parent))}}
+		}
+	}
+}
+
+// the names given to the classes in the result
+// taken holds every name already used at each vertex, starting with those of the starting elements
+type chaseNames struct {
+	byClass map[int]homs.Element
+	taken   map[cgs.Vertex]map[homs.Element]bool
+}
+
+func (state *chase) newNames() *chaseNames {
+	toReturn := &chaseNames{byClass: make(map[int]homs.Element), taken: make(map[cgs.Vertex]map[homs.Element]bool)}
+	for _, v := range state.schema.GetVertices() {
+		toReturn.taken[v] = make(map[homs.Element]bool)
+	}
+	for x := range state.parent {
+		if state.parent[x] == x && state.origin[x].starting {
+			toReturn.taken[state.vertex[x]][state.origin[x].element] = true
+		}
+	}
+	return toReturn
+}
+
+// the name of the class of x in the result
+// a starting element keeps its own, a made one is a tuple of the edge name and the name of the element it is the value on
+// for a relation edge the number the chase gave the element comes last, as one element can have many such values
+// a made name that is already taken at its vertex is paired with that number until it is not
+func (state *chase) name(x int, names *chaseNames) homs.Element {
+	x = state.find(x)
+	if toReturn, known := names.byClass[x]; known {
+		return toReturn
+	}
+	origin := state.origin[x]
+	var toReturn homs.Element
+	switch {
+	case origin.starting:
+		toReturn = origin.element
+	case origin.witness:
+		toReturn = homs.NewTupleElement(homs.NewStringElement(origin.edge), state.name(origin.from, names), homs.NewIntElement(x))
+	default:
+		toReturn = homs.NewTupleElement(homs.NewStringElement(origin.edge), state.name(origin.from, names))
+	}
+	if !origin.starting {
+		for names.taken[state.vertex[x]][toReturn] {
+			toReturn = homs.NewTupleElement(toReturn, homs.NewIntElement(x))
+		}
+		names.taken[state.vertex[x]][toReturn] = true
+	}
+	names.byClass[x] = toReturn
+	return toReturn
+}
+
+// the chased data as a database over the schema, without validating it
+// second return lists the elements left with no value for some attribute
+func (state *chase) result() (InstantiatedDB, ValidationErrors) {
+	names := state.newNames()
+	problems := make(ValidationErrors, 0)
+	sets := make(map[cgs.Vertex]([]homs.Element))
+	for _, v := range state.schema.GetVertices() {
+		sets[v] = make([]homs.Element, 0)
+		for _, x := range state.elementsOf(v) {
+			sets[v] = append(sets[v], state.name(x, names))
+		}
+	}
+	functions := make(map[cgs.FunctionEdge](homs.FunctionTable))
+	for _, edge := range state.schema.GetFunctionEdges() {
+		functions[edge] = homs.NewFunctionTable(state.namedValues(edge.GetIdentifier(), names))
+	}
+	partialFunctions := make(map[cgs.PartialFunctionEdge](homs.PartialFunctionTable))
+	for _, edge := range state.schema.GetPartialFunctionEdges() {
+		partialFunctions[edge] = homs.NewPartialFunctionTable(state.namedValues(edge.GetIdentifier(), names))
+	}
+	relations := make(map[cgs.RelationEdge](homs.RelationTable))
+	for _, edge := range state.schema.GetRelationEdges() {
+		related := make(map[homs.Element]([]homs.Element))
+		for _, x := range sortedKeys(state.related[edge.GetIdentifier()]) {
+			for _, y := range sortedKeys(state.related[edge.GetIdentifier()][x]) {
+				related[state.name(x, names)] = append(related[state.name(x, names)], state.name(y, names))
+			}
+		}
+		relations[edge] = homs.NewRelationTable(related)
+	}
+	attributes := make(map[cgs.AttributeEdge](homs.FunctionTable))
+	for _, edge := range state.schema.GetAttributeEdges() {
+		values := make(map[homs.Element]homs.Element)
+		for _, x := range state.elementsOf(edge.GetSource()) {
+			value, defined := state.attributes[edge.GetIdentifier()][x]
+			if !defined {
+				problems = append(problems, ValidationError{Kind: MissingValue, Vertex: edge.GetSource().GetIdentifier(), Edge: edge.GetIdentifier(),
+					Element: state.name(x, names), HasElement: true, Detail: "no value is forced for this attribute"})
+				continue
+			}
+			values[state.name(x, names)] = value
+		}
+		attributes[edge] = homs.NewFunctionTable(values)
+	}
+	return assembleDBFromTables(*state.schema, sets, functions, partialFunctions, relations, attributes), problems
+}
+
+func (state *chase) namedValues(edge string, names *chaseNames) map[homs.Element]homs.Element {
+	toReturn := make(map[homs.Element]homs.Element, len(state.values[edge]))
+	for x, y := range state.values[edge] {
+		toReturn[state.name(x, names)] = state.name(y, names)
+	}
+	return toReturn
+}
+
+// the edges followed from a starting element to make the class of x
+func (state *chase) pathTo(x int) []string {
+	origin := state.origin[state.find(x)]
+	if origin.starting {
+		return []string{}
+	}
+	return append(state.pathTo(origin.from), origin.edge)
+}
@@ -0,0 +1,237 @@
+package relationalGraphDB
+
+import (
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// an element together with the vertex whose set it is in
+type VertexElement struct {
+	Vertex  string       `json:"vertex"`
+	Element homs.Element `json:"element"`
+}
+
+// what a delete did
+// Deleted has every element removed, the one asked for first and then those removed by cascading
+// Undefined has the partial function edges that became undefined on an element that was kept
+type DeletionReport struct {
+	Deleted   []VertexElement
+	Undefined []UndefinedValue
+}
+
+// the partial function edge named Edge is no longer defined on Element
+type UndefinedValue struct {
+	Edge    string
+	Element homs.Element
+}
+
+// the elements being deleted, by vertex
+type doomedElements map[cgs.Vertex](map[homs.Element]bool)
+
+func (doomed doomedElements) has(v cgs.Vertex, x homs.Element) bool {
+	return doomed[v][x]
+}
+
+func (doomed doomedElements) add(v cgs.Vertex, x homs.Element) {
+	if doomed[v] == nil {
+		doomed[v] = make(map[homs.Element]bool)
+	}
+	doomed[v][x] = true
+}
+
+// someone pointing at a deleted element along a restrict edge
+type restriction struct {
+	edge     string
+	source   cgs.Vertex
+	referrer homs.Element
+	target   homs.Element
+}
+
+// sets the delete action of a function or partial function edge of the schema, see cgs.SetDeleteAction
+func (currentDB *InstantiatedDB) SetDeleteAction(edgeName string, action cgs.DeleteAction) error {
+	if err := currentDB.beginChange(); err != nil {
+		return err
+	}
+	mySchema := currentDB.underlyingGraph.Copy()
+	if !mySchema.SetDeleteAction(edgeName, action) {
+		return ValidationErrors{unknownName("", edgeName, "not an edge that can be given the delete action "+action.String())}
+	}
+	currentDB.underlyingGraph = mySchema
+	return nil
+}
+
+// deletes deletedItem from the set over modifiedVertex
+// an element x with f(x) deleted for a function or partial function edge f is handled by the delete action of f
+// Restrict refuses the whole delete, Cascade deletes x too and SetUndefined leaves f undefined on x
+// pairs of a relation that involve a deleted element are dropped, attributes of deleted elements go with them
+// the whole database is validated afterwards and currentDB is left as it was whenever an error is returned
+// inside a Transaction both the restrict check and the validation wait for Commit,
+// so an element can be deleted before the elements pointing at it
+func (currentDB *InstantiatedDB) DeleteElementFromSet(modifiedVertex string, deletedItem homs.Element) (DeletionReport, error) {
+	if err := currentDB.beginChange(); err != nil {
+		return DeletionReport{}, err
+	}
+	thisVertex := cgs.NewVertex(modifiedVertex)
+	oldSet, present := currentDB.underlyingSets[thisVertex]
+	if !present {
+		return DeletionReport{}, ValidationErrors{unknownName(modifiedVertex, "", "not a vertex of the schema")}
+	}
+	if !elementInSet(deletedItem, oldSet) {
+		return DeletionReport{}, ValidationErrors{{Kind: UnknownName, Vertex: modifiedVertex, Element: deletedItem, HasElement: true, Detail: "not in the set"}}
+	}
+	schema := &currentDB.underlyingGraph
+	functions := make(map[cgs.FunctionEdge](homs.FunctionTable))
+	for _, edge := range schema.GetFunctionEdges() {
+		functions[edge], _ = currentDB.GetFunctionTable(edge)
+	}
+	partialFunctions := make(map[cgs.PartialFunctionEdge](homs.PartialFunctionTable))
+	for _, edge := range schema.GetPartialFunctionEdges() {
+		partialFunctions[edge], _ = currentDB.GetPartialFunctionTable(edge)
+	}
+
+	report := DeletionReport{Deleted: make([]VertexElement, 0), Undefined: make([]UndefinedValue, 0)}
+	doomed := make(doomedElements)
+	undefined := make(map[cgs.PartialFunctionEdge](map[homs.Element]bool))
+	restrictions := make([]restriction, 0)
+	doomed.add(thisVertex, deletedItem)
+	queue := []VertexElement{{Vertex: modifiedVertex, Element: deletedItem}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		report.Deleted = append(report.Deleted, current)
+		currentVertex := cgs.NewVertex(current.Vertex)
+		cascadeTo := func(source cgs.Vertex, x homs.Element) {
+			if !doomed.has(source, x) {
+				doomed.add(source, x)
+				queue = append(queue, VertexElement{Vertex: source.GetIdentifier(), Element: x})
+			}
+		}
+		for _, edge := range schema.GetFunctionEdges() {
+			table := functions[edge]
+			if edge.GetTarget() != currentVertex {
+				continue
+			}
+			for _, x := range table.Keys() {
+				if y, _ := table.Evaluate(x); y != current.Element {
+					continue
+				}
+				if schema.GetDeleteAction(edge.GetIdentifier()) == cgs.Cascade {
+					cascadeTo(edge.GetSource(), x)
+				} else {
+					restrictions = append(restrictions, restriction{edge: edge.GetIdentifier(), source: edge.GetSource(), referrer: x, target: current.Element})
+				}
+			}
+		}
+		for _, edge := range schema.GetPartialFunctionEdges() {
+			table := partialFunctions[edge]
+			if edge.GetTarget() != currentVertex {
+				continue
+			}
+			for _, x := range table.Keys() {
+				if y, _ := table.Evaluate(x); y != current.Element {
+					continue
+				}
+				switch schema.GetDeleteAction(edge.GetIdentifier()) {
+				case cgs.Cascade:
+					cascadeTo(edge.GetSource(), x)
+				case cgs.SetUndefined:
+					if undefined[edge] == nil {
+						undefined[edge] = make(map[homs.Element]bool)
+					}
+					undefined[edge][x] = true
+				default:
+					restrictions = append(restrictions, restriction{edge: edge.GetIdentifier(), source: edge.GetSource(), referrer: x, target: current.Element})
+				}
+			}
+		}
+	}
+
+	// a referrer that is itself deleted by some other cascade does not hold the delete up
+	problems := make(ValidationErrors, 0)
+	for _, blocked := range restrictions {
+		if !doomed.has(blocked.source, blocked.referrer) {
+			problems = append(problems, ValidationError{Kind: DeleteRestricted, Vertex: blocked.source.GetIdentifier(), Edge: blocked.edge,
+				Element: blocked.referrer, HasElement: true, Detail: "still points at " + blocked.target.String()})
+		}
+	}
+	if len(problems) > 0 && !currentDB.deferValidation {
+		return DeletionReport{}, problems
+	}
+
+	sets := make(map[cgs.Vertex]([]homs.Element), len(currentDB.underlyingSets))
+	for v, set := range currentDB.underlyingSets {
+		sets[v] = make([]homs.Element, 0, len(set))
+		for _, x := range set {
+			if !doomed.has(v, x) {
+				sets[v] = append(sets[v], x)
+			}
+		}
+	}
+	newFunctions := make(map[cgs.FunctionEdge](homs.FunctionTable), len(functions))
+	for edge, table := range functions {
+		newFunctions[edge] = keepKeys(table, func(x homs.Element) bool { return !doomed.has(edge.GetSource(), x) })
+	}
+	newPartialFunctions := make(map[cgs.PartialFunctionEdge](homs.PartialFunctionTable), len(partialFunctions))
+	for _, edge := range schema.GetPartialFunctionEdges() {
+		table := partialFunctions[edge]
+		kept := keepKeys(table.FunctionTable, func(x homs.Element) bool { return !doomed.has(edge.GetSource(), x) })
+		for _, x := range kept.Keys() {
+			if undefined[edge][x] {
+				report.Undefined = append(report.Undefined, UndefinedValue{Edge: edge.GetIdentifier(), Element: x})
+			}
+		}
+		kept = keepKeys(kept, func(x homs.Element) bool { return !undefined[edge][x] })
+		newPartialFunctions[edge] = homs.PartialFunctionTable{FunctionTable: kept}
+	}
+	relations := make(map[cgs.RelationEdge](homs.RelationTable))
+	for _, edge := range schema.GetRelationEdges() {
+		table, _ := currentDB.GetRelationTable(edge)
+		related := make(map[homs.Element]([]homs.Element))
+		for _, x := range table.Keys() {
+			if doomed.has(edge.GetSource(), x) {
+				continue
+			}
+			related[x] = make([]homs.Element, 0)
+			for _, y := range table.Evaluate(x) {
+				if !doomed.has(edge.GetTarget(), y) {
+					related[x] = append(related[x], y)
+				}
+			}
+		}
+		relations[edge] = homs.NewRelationTable(related)
+	}
+	attributes := make(map[cgs.AttributeEdge](homs.FunctionTable))
+	for _, edge := range schema.GetAttributeEdges() {
+		table, _ := currentDB.GetAttributeTable(edge)
+		attributes[edge] = keepKeys(table, func(x homs.Element) bool { return !doomed.has(edge.GetSource(), x) })
+	}
+	result := assembleDBFromTables(schema.Copy(), sets, newFunctions, newPartialFunctions, relations, attributes)
+	if !currentDB.deferValidation {
+		if problems := ValidateDB(result); len(problems) > 0 {
+			return DeletionReport{}, problems
+		}
+	}
+	currentDB.replaceWith(result)
+	return report, nil
+}
+
+func elementInSet(x homs.Element, set []homs.Element) bool {
+	for _, y := range set {
+		if x == y {
+			return true
+		}
+	}
+	return false
+}
+
+// the part of table on the keys that keep says to keep, in the same order
+func keepKeys(table homs.FunctionTable, keep func(homs.Element) bool) homs.FunctionTable {
+	var toReturn homs.FunctionTable
+	for _, x := range table.Keys() {
+		if keep(x) {
+			y, _ := table.Evaluate(x)
+			toReturn.Set(x, y)
+		}
+	}
+	return toReturn
+}
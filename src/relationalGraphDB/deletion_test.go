@@ -0,0 +1,90 @@
+package relationalGraphDB
+
+import (
+	"testing"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// employees each in a department, deleted with it, with a manager who can not be deleted from under them
+// and maybe a mentor, forgotten when the mentor goes, and the people they know
+func officeDB(t *testing.T) InstantiatedDB {
+	t.Helper()
+	schema := cgs.EmptySchemaGraph()
+	mustSucceed(t, schema.AddVertex2("Employee"), "add Employee")
+	mustSucceed(t, schema.AddVertex2("Department"), "add Department")
+	mustSucceed(t, schema.AddFunctionEdge2("Employee", "Department", "worksIn"), "add worksIn")
+	mustSucceed(t, schema.AddFunctionEdge2("Employee", "Employee", "manager"), "add manager")
+	mustSucceed(t, schema.AddPartialFunctionEdge2("Employee", "Employee", "mentor"), "add mentor")
+	mustSucceed(t, schema.AddRelationEdge2("Employee", "Employee", "knows"), "add knows")
+	mustSucceed(t, schema.SetDeleteAction("worksIn", cgs.Cascade), "cascade worksIn")
+	mustSucceed(t, schema.SetDeleteAction("mentor", cgs.SetUndefined), "set mentor undefined")
+	s := homs.NewStringElement
+	mentor, _ := schema.GetDefPartialFunctionEdgeByName("mentor")
+	knows, _ := schema.GetDefRelationEdgeByName("knows")
+	db, err := NewInstantiatedDBFromTables(schema,
+		map[cgs.Vertex]([]homs.Element){cgs.NewVertex("Employee"): {s("al"), s("bo"), s("cy")}, cgs.NewVertex("Department"): {s("sales"), s("ops")}},
+		map[cgs.FunctionEdge](homs.FunctionTable){
+			functionEdge(t, schema, "worksIn"): homs.NewFunctionTable(map[homs.Element]homs.Element{s("al"): s("sales"), s("bo"): s("sales"), s("cy"): s("ops")}),
+			functionEdge(t, schema, "manager"): homs.NewFunctionTable(map[homs.Element]homs.Element{s("al"): s("al"), s("bo"): s("al"), s("cy"): s("cy")})},
+		map[cgs.PartialFunctionEdge](homs.PartialFunctionTable){mentor: homs.NewPartialFunctionTable(map[homs.Element]homs.Element{s("al"): s("cy")})},
+		map[cgs.RelationEdge](homs.RelationTable){knows: homs.NewRelationTable(map[homs.Element]([]homs.Element){s("al"): {s("bo"), s("cy")}})},
+		nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// bo is managed by al, al managing themself does not count
+func TestDeleteRestrict(t *testing.T) {
+	db := officeDB(t)
+	_, err := db.DeleteElementFromSet("Employee", homs.NewStringElement("al"))
+	violation := expectKind(t, err, DeleteRestricted)
+	if violation.Element != homs.NewStringElement("bo") || violation.Edge != "manager" {
+		t.Errorf("restricted by %v along %s, want bo along manager", violation.Element, violation.Edge)
+	}
+	if diff := Diff(officeDB(t), db); !diff.IsEmpty() {
+		t.Errorf("a restricted delete changed the data: %+v", diff)
+	}
+}
+
+// ops takes cy with it, so al loses their mentor and no longer knows cy
+func TestDeleteCascadeAndSetUndefined(t *testing.T) {
+	db := officeDB(t)
+	s := homs.NewStringElement
+	report, err := db.DeleteElementFromSet("Department", s("ops"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Deleted) != 2 || report.Deleted[0] != (VertexElement{Vertex: "Department", Element: s("ops")}) ||
+		report.Deleted[1] != (VertexElement{Vertex: "Employee", Element: s("cy")}) {
+		t.Errorf("deleted %v, want ops and then cy", report.Deleted)
+	}
+	if len(report.Undefined) != 1 || report.Undefined[0] != (UndefinedValue{Edge: "mentor", Element: s("al")}) {
+		t.Errorf("undefined %v, want the mentor of al", report.Undefined)
+	}
+	schema := db.GetSchema()
+	mentorEdge, _ := schema.GetDefPartialFunctionEdgeByName("mentor")
+	mentor, _ := db.GetPartialFunction(mentorEdge)
+	if mentor.IsDefined(s("al")) {
+		t.Error("al still has a mentor")
+	}
+	knowsEdge, _ := schema.GetDefRelationEdgeByName("knows")
+	knows, _ := db.GetRelation(knowsEdge)
+	if known := knows.Evaluate(s("al")); len(known) != 1 || known[0] != s("bo") {
+		t.Errorf("al knows %v, want only bo", known)
+	}
+	if employees, _ := db.GetUnderlyingSet(cgs.NewVertex("Employee")); len(employees) != 2 {
+		t.Errorf("employees %v, want al and bo", employees)
+	}
+}
+
+func TestDeleteUnknown(t *testing.T) {
+	db := officeDB(t)
+	_, err := db.DeleteElementFromSet("Employee", homs.NewStringElement("di"))
+	expectKind(t, err, UnknownName)
+	_, err = db.DeleteElementFromSet("Office", homs.NewStringElement("al"))
+	expectKind(t, err, UnknownName)
+}
@@ -0,0 +1,156 @@
+package relationalGraphDB
+
+import (
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// everything that differs between two databases
+// schema parts are given by name, data is compared edge by edge
+// the data of a vertex or edge only one side has is all added or all removed
+// an equation redefined under the same name is both removed and added
+type DBDiff struct {
+	AddedVertices    []string
+	RemovedVertices  []string
+	AddedEdges       []string
+	RemovedEdges     []string
+	AddedEquations   []string
+	RemovedEquations []string
+	AddedElements    []VertexElement
+	RemovedElements  []VertexElement
+	// for function edges, partial function edges and attributes
+	// an element that is new or gone counts as going from undefined or to undefined
+	ChangedValues []ValueChange
+	AddedPairs    []RelatedPair
+	RemovedPairs  []RelatedPair
+}
+
+// the value of the edge called Edge at Element, Before and After only mean something when the matching Defined is true
+type ValueChange struct {
+	Edge          string
+	Element       homs.Element
+	Before        homs.Element
+	BeforeDefined bool
+	After         homs.Element
+	AfterDefined  bool
+}
+
+// From is related to To along the relation edge called Edge
+type RelatedPair struct {
+	Edge string
+	From homs.Element
+	To   homs.Element
+}
+
+func (diff DBDiff) IsEmpty() bool {
+	return len(diff.AddedVertices)+len(diff.RemovedVertices)+len(diff.AddedEdges)+len(diff.RemovedEdges)+
+		len(diff.AddedEquations)+len(diff.RemovedEquations)+len(diff.AddedElements)+len(diff.RemovedElements)+
+		len(diff.ChangedValues)+len(diff.AddedPairs)+len(diff.RemovedPairs) == 0
+}
+
+// what has to happen to before to get after, everything is listed in the order of the schemas
+func Diff(before InstantiatedDB, after InstantiatedDB) DBDiff {
+	oldSchema, newSchema := &before.underlyingGraph, &after.underlyingGraph
+	toReturn := DBDiff{AddedVertices: namesRemoved(vertexNames(newSchema), vertexNames(oldSchema)),
+		RemovedVertices:  namesRemoved(vertexNames(oldSchema), vertexNames(newSchema)),
+		AddedEdges:       namesRemoved(allEdgeNames(newSchema), allEdgeNames(oldSchema)),
+		RemovedEdges:     namesRemoved(allEdgeNames(oldSchema), allEdgeNames(newSchema)),
+		AddedEquations:   cgs.EquationsMissing(*newSchema, *oldSchema),
+		RemovedEquations: cgs.EquationsMissing(*oldSchema, *newSchema),
+		AddedElements:    make([]VertexElement, 0), RemovedElements: make([]VertexElement, 0),
+		ChangedValues: make([]ValueChange, 0), AddedPairs: make([]RelatedPair, 0), RemovedPairs: make([]RelatedPair, 0)}
+	// a vertex or edge on one side only is compared with no data on the other
+	for _, v := range bothSides(oldSchema.GetVertices(), newSchema.GetVertices()) {
+		toReturn.RemovedElements = append(toReturn.RemovedElements, elementsMissing(v, before.underlyingSets[v], after.underlyingSets[v])...)
+		toReturn.AddedElements = append(toReturn.AddedElements, elementsMissing(v, after.underlyingSets[v], before.underlyingSets[v])...)
+	}
+	for _, edge := range bothSides(oldSchema.GetFunctionEdges(), newSchema.GetFunctionEdges()) {
+		oldTable, _ := before.GetFunctionTable(edge)
+		newTable, _ := after.GetFunctionTable(edge)
+		toReturn.ChangedValues = append(toReturn.ChangedValues, valueChanges(edge.GetIdentifier(), oldTable, newTable)...)
+	}
+	for _, edge := range bothSides(oldSchema.GetPartialFunctionEdges(), newSchema.GetPartialFunctionEdges()) {
+		oldTable, _ := before.GetPartialFunctionTable(edge)
+		newTable, _ := after.GetPartialFunctionTable(edge)
+		toReturn.ChangedValues = append(toReturn.ChangedValues, valueChanges(edge.GetIdentifier(), oldTable.FunctionTable, newTable.FunctionTable)...)
+	}
+	for _, edge := range bothSides(oldSchema.GetAttributeEdges(), newSchema.GetAttributeEdges()) {
+		oldTable, _ := before.GetAttributeTable(edge)
+		newTable, _ := after.GetAttributeTable(edge)
+		toReturn.ChangedValues = append(toReturn.ChangedValues, valueChanges(edge.GetIdentifier(), oldTable, newTable)...)
+	}
+	for _, edge := range bothSides(oldSchema.GetRelationEdges(), newSchema.GetRelationEdges()) {
+		oldTable, _ := before.GetRelationTable(edge)
+		newTable, _ := after.GetRelationTable(edge)
+		toReturn.RemovedPairs = append(toReturn.RemovedPairs, pairsMissing(edge.GetIdentifier(), oldTable, newTable)...)
+		toReturn.AddedPairs = append(toReturn.AddedPairs, pairsMissing(edge.GetIdentifier(), newTable, oldTable)...)
+	}
+	return toReturn
+}
+
+// the parts of the old schema and then those only the new one has
+func bothSides[Part comparable](oldParts []Part, newParts []Part) []Part {
+	toReturn := append([]Part{}, oldParts...)
+	seen := make(map[Part]bool, len(oldParts))
+	for _, part := range oldParts {
+		seen[part] = true
+	}
+	for _, part := range newParts {
+		if !seen[part] {
+			toReturn = append(toReturn, part)
+		}
+	}
+	return toReturn
+}
+
+func elementSet(elements []homs.Element) map[homs.Element]bool {
+	toReturn := make(map[homs.Element]bool, len(elements))
+	for _, x := range elements {
+		toReturn[x] = true
+	}
+	return toReturn
+}
+
+// the elements of these that are not in those
+func elementsMissing(v cgs.Vertex, these []homs.Element, those []homs.Element) []VertexElement {
+	toReturn := make([]VertexElement, 0)
+	inThose := elementSet(those)
+	for _, x := range these {
+		if !inThose[x] {
+			toReturn = append(toReturn, VertexElement{Vertex: v.GetIdentifier(), Element: x})
+		}
+	}
+	return toReturn
+}
+
+// every key of either table where the two do not agree, old keys first
+func valueChanges(edgeName string, oldTable homs.FunctionTable, newTable homs.FunctionTable) []ValueChange {
+	toReturn := make([]ValueChange, 0)
+	seen := make(map[homs.Element]bool)
+	for _, x := range append(oldTable.Keys(), newTable.Keys()...) {
+		if seen[x] {
+			continue
+		}
+		seen[x] = true
+		oldValue, oldDefined := oldTable.Evaluate(x)
+		newValue, newDefined := newTable.Evaluate(x)
+		if oldDefined != newDefined || oldValue != newValue {
+			toReturn = append(toReturn, ValueChange{Edge: edgeName, Element: x, Before: oldValue, BeforeDefined: oldDefined, After: newValue, AfterDefined: newDefined})
+		}
+	}
+	return toReturn
+}
+
+// the pairs of these that are not in those
+func pairsMissing(edgeName string, these homs.RelationTable, those homs.RelationTable) []RelatedPair {
+	toReturn := make([]RelatedPair, 0)
+	for _, x := range these.Keys() {
+		relatedInThose := elementSet(those.Evaluate(x))
+		for _, y := range these.Evaluate(x) {
+			if !relatedInThose[y] {
+				toReturn = append(toReturn, RelatedPair{Edge: edgeName, From: x, To: y})
+			}
+		}
+	}
+	return toReturn
+}
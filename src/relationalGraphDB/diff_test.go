@@ -0,0 +1,105 @@
+package relationalGraphDB
+
+import (
+	"reflect"
+	"testing"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+func TestDiffSame(t *testing.T) {
+	if diff := Diff(companyDB(t), companyDB(t)); !diff.IsEmpty() {
+		t.Errorf("two copies of the same database differ by %+v", diff)
+	}
+}
+
+// bo leaves and di joins ops
+func TestDiffElements(t *testing.T) {
+	before := companyDB(t)
+	after := companyDB(t)
+	s := homs.NewStringElement
+	if _, err := after.DeleteElementFromSet("Person", s("bo")); err != nil {
+		t.Fatal(err)
+	}
+	if err := after.AddElementToSet("Person", s("di"), ElementInsertion{FunctionValues: map[string]homs.Element{"worksIn": s("ops")},
+		AttributeValues: map[string]homs.Element{"name": s("Di")}}); err != nil {
+		t.Fatal(err)
+	}
+	diff := Diff(before, after)
+	if want := []VertexElement{{Vertex: "Person", Element: s("bo")}}; !reflect.DeepEqual(diff.RemovedElements, want) {
+		t.Errorf("removed %v, want %v", diff.RemovedElements, want)
+	}
+	if want := []VertexElement{{Vertex: "Person", Element: s("di")}}; !reflect.DeepEqual(diff.AddedElements, want) {
+		t.Errorf("added %v, want %v", diff.AddedElements, want)
+	}
+	want := []ValueChange{
+		{Edge: "worksIn", Element: s("bo"), Before: s("sales"), BeforeDefined: true},
+		{Edge: "worksIn", Element: s("di"), After: s("ops"), AfterDefined: true},
+		{Edge: "name", Element: s("bo"), Before: s("Bo"), BeforeDefined: true},
+		{Edge: "name", Element: s("di"), After: s("Di"), AfterDefined: true}}
+	if !reflect.DeepEqual(diff.ChangedValues, want) {
+		t.Errorf("changed %+v, want %+v", diff.ChangedValues, want)
+	}
+	if len(diff.AddedEdges)+len(diff.RemovedEdges)+len(diff.AddedPairs)+len(diff.RemovedPairs) > 0 {
+		t.Errorf("the schema and relations changed too: %+v", diff)
+	}
+}
+
+// the data of an edge or vertex on only one side is all added or all removed
+func TestDiffAddedAndRemovedEdges(t *testing.T) {
+	before := officeDB(t)
+	after := officeDB(t)
+	s := homs.NewStringElement
+	if _, err := after.RemoveEdge("knows"); err != nil {
+		t.Fatal(err)
+	}
+	if err := after.AddAttributeEdge("Department", cgs.IntSort, "floor", homs.NewFunction(func(homs.Element) homs.Element {
+		return homs.NewIntElement(1)
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if err := after.AddVertex("Project", []homs.Element{s("launch")}); err != nil {
+		t.Fatal(err)
+	}
+	diff := Diff(before, after)
+	if !reflect.DeepEqual(diff.AddedEdges, []string{"floor"}) || !reflect.DeepEqual(diff.RemovedEdges, []string{"knows"}) {
+		t.Errorf("added edges %v and removed edges %v, want floor and knows", diff.AddedEdges, diff.RemovedEdges)
+	}
+	if want := []RelatedPair{{Edge: "knows", From: s("al"), To: s("bo")}, {Edge: "knows", From: s("al"), To: s("cy")}}; !reflect.DeepEqual(diff.RemovedPairs, want) {
+		t.Errorf("removed pairs %v, want %v", diff.RemovedPairs, want)
+	}
+	want := []ValueChange{
+		{Edge: "floor", Element: s("sales"), After: homs.NewIntElement(1), AfterDefined: true},
+		{Edge: "floor", Element: s("ops"), After: homs.NewIntElement(1), AfterDefined: true}}
+	if !reflect.DeepEqual(diff.ChangedValues, want) {
+		t.Errorf("changed %+v, want %+v", diff.ChangedValues, want)
+	}
+	if want := []VertexElement{{Vertex: "Project", Element: s("launch")}}; !reflect.DeepEqual(diff.AddedElements, want) {
+		t.Errorf("added %v, want %v", diff.AddedElements, want)
+	}
+	// and back again
+	reverse := Diff(after, before)
+	if !reflect.DeepEqual(reverse.AddedPairs, diff.RemovedPairs) || !reflect.DeepEqual(reverse.RemovedElements, diff.AddedElements) {
+		t.Errorf("the reverse diff %+v does not undo %+v", reverse, diff)
+	}
+}
+
+// the same name with other sides is a different equation
+func TestDiffRedefinedEquation(t *testing.T) {
+	before := companyDB(t)
+	after := companyDB(t)
+	if err := after.RemoveEquation("heads work there"); err != nil {
+		t.Fatal(err)
+	}
+	if err := after.AddFunctionEquation([]string{"head", "worksIn", "head"}, []string{"head"}, "heads work there"); err != nil {
+		t.Fatal(err)
+	}
+	diff := Diff(before, after)
+	if want := []string{"heads work there"}; !reflect.DeepEqual(diff.RemovedEquations, want) || !reflect.DeepEqual(diff.AddedEquations, want) {
+		t.Errorf("removed equations %v and added equations %v, want heads work there in both", diff.RemovedEquations, diff.AddedEquations)
+	}
+	if again := companyDB(t); !Diff(before, again).IsEmpty() {
+		t.Error("an equation with the same sides counts as changed")
+	}
+}
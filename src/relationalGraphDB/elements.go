@@ -0,0 +1,248 @@
+package relationalGraphDB
+
+import (
+	"fmt"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// what a relation edge does with a new element when it is not told which pairs to add
+type RelationDefault int
+
+const (
+	// the new element is related to nothing, or nothing is related to it
+	RelateToNone RelationDefault = iota
+	// the new element is related to everything at the other end, or everything there is related to it
+	RelateToAll
+)
+
+// everything needed to add one element to the set over a vertex, edges are given by name
+// FunctionValues must have a value for every function edge out of the vertex
+// and AttributeValues for every attribute on it
+// a partial function edge out of the vertex missing from PartialFunctionValues is undefined on the new element
+// OutgoingRelated says what the new element is related to along relation edges out of the vertex
+// IncomingRelated says which elements are related to the new element along relation edges into the vertex
+// relation edges missing from those use OutgoingDefault and IncomingDefault
+type ElementInsertion struct {
+	FunctionValues        map[string]homs.Element   `json:"function_values,omitempty"`
+	PartialFunctionValues map[string]homs.Element   `json:"partial_function_values,omitempty"`
+	AttributeValues       map[string]homs.Element   `json:"attribute_values,omitempty"`
+	OutgoingRelated       map[string][]homs.Element `json:"outgoing_related,omitempty"`
+	IncomingRelated       map[string][]homs.Element `json:"incoming_related,omitempty"`
+	OutgoingDefault       RelationDefault           `json:"outgoing_default,omitempty"`
+	IncomingDefault       RelationDefault           `json:"incoming_default,omitempty"`
+}
+
+func unknownName(vertex string, edge string, detail string) ValidationError {
+	return ValidationError{Kind: UnknownName, Vertex: vertex, Edge: edge, Detail: detail}
+}
+
+// every edge named in the insertion has to leave the vertex, or for IncomingRelated arrive at it
+func unexpectedEdges(schema *cgs.SchemaGraph, modifiedVertex cgs.Vertex, insertion ElementInsertion) ValidationErrors {
+	toReturn := make(ValidationErrors, 0)
+	for name := range insertion.FunctionValues {
+		if edge, found := schema.GetFunctionEdgeByName(name); !found || edge.GetSource() != modifiedVertex {
+			toReturn = append(toReturn, unknownName(modifiedVertex.GetIdentifier(), name, "not a function edge out of this vertex"))
+		}
+	}
+	for name := range insertion.PartialFunctionValues {
+		if edge, found := schema.GetDefPartialFunctionEdgeByName(name); !found || edge.GetSource() != modifiedVertex {
+			toReturn = append(toReturn, unknownName(modifiedVertex.GetIdentifier(), name, "not a partial function edge out of this vertex"))
+		}
+	}
+	for name := range insertion.AttributeValues {
+		if edge, found := schema.GetAttributeEdgeByName(name); !found || edge.GetSource() != modifiedVertex {
+			toReturn = append(toReturn, unknownName(modifiedVertex.GetIdentifier(), name, "not an attribute of this vertex"))
+		}
+	}
+	for name := range insertion.OutgoingRelated {
+		if edge, found := schema.GetDefRelationEdgeByName(name); !found || edge.GetSource() != modifiedVertex {
+			toReturn = append(toReturn, unknownName(modifiedVertex.GetIdentifier(), name, "not a relation edge out of this vertex"))
+		}
+	}
+	for name := range insertion.IncomingRelated {
+		if edge, found := schema.GetDefRelationEdgeByName(name); !found || edge.GetTarget() != modifiedVertex {
+			toReturn = append(toReturn, unknownName(modifiedVertex.GetIdentifier(), name, "not a relation edge into this vertex"))
+		}
+	}
+	return toReturn
+}
+
+func missingValue(vertex cgs.Vertex, edgeName string, addedItem homs.Element) ValidationError {
+	return ValidationError{Kind: MissingValue, Vertex: vertex.GetIdentifier(), Edge: edgeName, Element: addedItem, HasElement: true,
+		Detail: "a value is needed on the new element"}
+}
+
+// for all the function edges and attributes that go out from modifiedVertex a value on addedItem has to be supplied
+// for all the partial function edges that go out from modifiedVertex either supply a value or it is undefined there
+// for all the relation edges that go out from this vertex, decide what y (addedItem,y) go into the relation
+// for all the relation edges that go into this vertex, decide what y (y,addedItem) go into the relation
+// a relation edge from the vertex to itself is handled as outgoing for the pair (addedItem,addedItem)
+// the values on addedItem and the equations at it are checked afterwards, so an insert that breaks an equation is rejected,
+// unless this is inside a Transaction which validates at Commit instead
+// currentDB is left as it was whenever an error is returned
+func (currentDB *InstantiatedDB) AddElementToSet(modifiedVertex string, addedItem homs.Element, insertion ElementInsertion) error {
+	if err := currentDB.beginChange(); err != nil {
+		return err
+	}
+	thisVertex := cgs.NewVertex(modifiedVertex)
+	oldSet, present := currentDB.underlyingSets[thisVertex]
+	if !present {
+		return ValidationErrors{unknownName(modifiedVertex, "", "not a vertex of the schema")}
+	}
+	for _, x := range oldSet {
+		if x == addedItem {
+			return ValidationErrors{{Kind: DuplicateElement, Vertex: modifiedVertex, Element: addedItem, HasElement: true, Detail: "already in the set"}}
+		}
+	}
+	problems := unexpectedEdges(&currentDB.underlyingGraph, thisVertex, insertion)
+	if len(problems) > 0 {
+		return problems
+	}
+	potentialDB := currentDB.Clone()
+	potentialDB.underlyingSets[thisVertex] = append(potentialDB.underlyingSets[thisVertex], addedItem)
+	for _, edge := range potentialDB.underlyingGraph.GetFunctionEdges() {
+		if edge.GetSource() != thisVertex {
+			continue
+		}
+		value, given := insertion.FunctionValues[edge.GetIdentifier()]
+		if !given {
+			problems = append(problems, missingValue(thisVertex, edge.GetIdentifier(), addedItem))
+			continue
+		}
+		table, _ := currentDB.GetFunctionTable(edge)
+		table.Set(addedItem, value)
+		potentialDB.underlyingFunctions[edge] = table.AsFunction()
+	}
+	for _, edge := range potentialDB.underlyingGraph.GetAttributeEdges() {
+		if edge.GetSource() != thisVertex {
+			continue
+		}
+		value, given := insertion.AttributeValues[edge.GetIdentifier()]
+		if !given {
+			problems = append(problems, missingValue(thisVertex, edge.GetIdentifier(), addedItem))
+			continue
+		}
+		table, _ := currentDB.GetAttributeTable(edge)
+		table.Set(addedItem, value)
+		potentialDB.underlyingAttributes[edge] = table.AsFunction()
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	for _, edge := range potentialDB.underlyingGraph.GetPartialFunctionEdges() {
+		value, given := insertion.PartialFunctionValues[edge.GetIdentifier()]
+		if edge.GetSource() != thisVertex || !given {
+			continue
+		}
+		table, _ := currentDB.GetPartialFunctionTable(edge)
+		table.Set(addedItem, value)
+		potentialDB.underlyingPartialFunctions[edge] = table.AsPartialFunction()
+	}
+	for _, edge := range potentialDB.underlyingGraph.GetRelationEdges() {
+		if edge.GetSource() != thisVertex && edge.GetTarget() != thisVertex {
+			continue
+		}
+		table, _ := currentDB.GetRelationTable(edge)
+		if edge.GetSource() == thisVertex {
+			related, given := insertion.OutgoingRelated[edge.GetIdentifier()]
+			if !given && insertion.OutgoingDefault == RelateToAll {
+				related = potentialDB.underlyingSets[edge.GetTarget()]
+			}
+			for _, y := range related {
+				table.Add(addedItem, y)
+			}
+		}
+		if edge.GetTarget() == thisVertex {
+			related, given := insertion.IncomingRelated[edge.GetIdentifier()]
+			if !given && insertion.IncomingDefault == RelateToAll {
+				related = currentDB.underlyingSets[edge.GetSource()]
+			}
+			for _, y := range related {
+				table.Add(y, addedItem)
+			}
+		}
+		potentialDB.underlyingRelations[edge] = table.AsRelation()
+	}
+	if !currentDB.deferValidation {
+		problems = validateElement(&potentialDB, thisVertex, addedItem, insertion)
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	currentDB.replaceWith(potentialDB)
+	return nil
+}
+
+// what ValidateDB would find after addedItem went into an otherwise valid potentialDB
+// the values of other elements do not change and can not reach addedItem along function or partial function edges,
+// so only the values on addedItem and the equations starting at it need checking
+// except for relation equations once something is related to addedItem, as paths from anywhere may now pass through it
+func validateElement(potentialDB *InstantiatedDB, vertex cgs.Vertex, addedItem homs.Element, insertion ElementInsertion) ValidationErrors {
+	toReturn := make(ValidationErrors, 0)
+	added := []homs.Element{addedItem}
+	schema := &potentialDB.underlyingGraph
+	for _, edge := range schema.GetFunctionEdges() {
+		if edge.GetSource() == vertex {
+			toReturn = append(toReturn, outOfTargetErrors(edge, homs.FunctionOutOfTarget(added,
+				potentialDB.underlyingSets[edge.GetTarget()], potentialDB.underlyingFunctions[edge]))...)
+		}
+	}
+	for _, edge := range schema.GetPartialFunctionEdges() {
+		if edge.GetSource() == vertex {
+			toReturn = append(toReturn, outOfTargetErrors(edge, homs.PartialFunctionOutOfTarget(added,
+				potentialDB.underlyingSets[edge.GetTarget()], potentialDB.underlyingPartialFunctions[edge]))...)
+		}
+	}
+	incoming := false
+	for _, edge := range schema.GetRelationEdges() {
+		if edge.GetSource() == vertex {
+			toReturn = append(toReturn, outOfTargetErrors(edge, homs.RelationOutOfTarget(added,
+				potentialDB.underlyingSets[edge.GetTarget()], potentialDB.underlyingRelations[edge]))...)
+		}
+		if edge.GetTarget() != vertex {
+			continue
+		}
+		sourceSet := make(map[homs.Element]bool)
+		for _, y := range potentialDB.underlyingSets[edge.GetSource()] {
+			sourceSet[y] = true
+		}
+		for _, y := range insertion.IncomingRelated[edge.GetIdentifier()] {
+			incoming = true
+			if !sourceSet[y] {
+				toReturn = append(toReturn, ValidationError{Kind: ValueOutsideTarget, Vertex: vertex.GetIdentifier(), Edge: edge.GetIdentifier(),
+					Element: addedItem, HasElement: true, Detail: fmt.Sprintf("related from %v which is not in %s", y, edge.GetSource().GetIdentifier())})
+			}
+		}
+		incoming = incoming || (insertion.IncomingDefault == RelateToAll && len(sourceSet) > 0)
+	}
+	for _, edge := range schema.GetAttributeEdges() {
+		if edge.GetSource() == vertex {
+			toReturn = append(toReturn, sortMismatchErrors(edge, added, potentialDB.underlyingAttributes[edge])...)
+		}
+	}
+	for _, eq := range schema.GetFunctionEquations() {
+		if source, _ := equationSource(eq); source == vertex {
+			toReturn = append(toReturn, checkFunctionEquationOn(potentialDB, eq, added).asValidationErrors()...)
+		}
+	}
+	for _, eq := range schema.GetPartialFunctionEquations() {
+		if source, _ := equationSource(eq); source == vertex {
+			toReturn = append(toReturn, checkPartialFunctionEquationOn(potentialDB, eq, added).asValidationErrors()...)
+		}
+	}
+	for _, eq := range schema.GetRelationEquations() {
+		if incoming {
+			toReturn = append(toReturn, checkRelationEquation(potentialDB, eq).asValidationErrors()...)
+		} else if source, _ := equationSource(eq); source == vertex {
+			toReturn = append(toReturn, checkRelationEquationOn(potentialDB, eq, added).asValidationErrors()...)
+		}
+	}
+	for _, eq := range schema.GetAttributeEquations() {
+		if eq.GetSource() == vertex {
+			toReturn = append(toReturn, checkAttributeEquationOn(potentialDB, eq, added).asValidationErrors()...)
+		}
+	}
+	return toReturn
+}
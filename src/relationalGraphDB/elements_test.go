@@ -0,0 +1,83 @@
+package relationalGraphDB
+
+import (
+	"testing"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+func expectKind(t *testing.T, err error, kind ViolationKind) ValidationError {
+	t.Helper()
+	problems, isValidation := err.(ValidationErrors)
+	if !isValidation || len(problems) == 0 || problems[0].Kind != kind {
+		t.Fatalf("got %v, want %v", err, kind)
+	}
+	return problems[0]
+}
+
+func TestAddElementToSet(t *testing.T) {
+	db := companyDB(t)
+	s := homs.NewStringElement
+	err := db.AddElementToSet("Person", s("di"), ElementInsertion{FunctionValues: map[string]homs.Element{"worksIn": s("ops")},
+		AttributeValues: map[string]homs.Element{"name": s("Di")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	people, _ := db.GetUnderlyingSet(cgs.NewVertex("Person"))
+	worksIn, _ := db.GetFunction(functionEdge(t, companySchema(t), "worksIn"))
+	if len(people) != 4 || worksIn.Evaluate(s("di")) != s("ops") {
+		t.Errorf("people %v and di works in %v, want 4 people and ops", people, worksIn.Evaluate(s("di")))
+	}
+}
+
+func TestAddElementToSetRejected(t *testing.T) {
+	s := homs.NewStringElement
+	name := map[string]homs.Element{"name": s("Di")}
+	for _, example := range []struct {
+		vertex    string
+		element   homs.Element
+		insertion ElementInsertion
+		kind      ViolationKind
+	}{
+		{"Person", s("al"), ElementInsertion{FunctionValues: map[string]homs.Element{"worksIn": s("ops")}, AttributeValues: name}, DuplicateElement},
+		{"Person", s("di"), ElementInsertion{AttributeValues: name}, MissingValue},
+		{"Person", s("di"), ElementInsertion{FunctionValues: map[string]homs.Element{"worksIn": s("hr")}, AttributeValues: name}, ValueOutsideTarget},
+		{"Person", s("di"), ElementInsertion{FunctionValues: map[string]homs.Element{"worksIn": s("ops"), "head": s("al")}, AttributeValues: name}, UnknownName},
+		{"Person", s("di"), ElementInsertion{FunctionValues: map[string]homs.Element{"worksIn": s("ops")},
+			AttributeValues: map[string]homs.Element{"name": homs.NewIntElement(4)}}, AttributeSortMismatch},
+		// bo works in sales, so can not head a new department
+		{"Department", s("hr"), ElementInsertion{FunctionValues: map[string]homs.Element{"head": s("bo")}}, FunctionEquationViolated},
+		{"Office", s("hr"), ElementInsertion{}, UnknownName},
+	} {
+		db := companyDB(t)
+		expectKind(t, db.AddElementToSet(example.vertex, example.element, example.insertion), example.kind)
+		if diff := Diff(companyDB(t), db); !diff.IsEmpty() {
+			t.Errorf("a rejected %v changed the data: %+v", example.kind, diff)
+		}
+	}
+}
+
+// relating b to the new c means a, which is related to b, must now be related to c
+// so the violation is at a and not at the new element
+func TestAddElementToSetRelatedInto(t *testing.T) {
+	schema := cgs.EmptySchemaGraph()
+	mustSucceed(t, schema.AddVertex2("A"), "add A")
+	mustSucceed(t, schema.AddRelationEdge2("A", "A", "r"), "add r")
+	mustSucceed(t, schema.AddRelationInclusion2([]string{"r", "r"}, []string{"r"}, "transitive"), "add transitive")
+	s := homs.NewStringElement
+	edge, _ := schema.GetDefRelationEdgeByName("r")
+	db, err := NewInstantiatedDBFromTables(schema, map[cgs.Vertex]([]homs.Element){cgs.NewVertex("A"): {s("a"), s("b")}}, nil, nil,
+		map[cgs.RelationEdge](homs.RelationTable){edge: homs.NewRelationTable(map[homs.Element]([]homs.Element){s("a"): {s("b")}})}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	violation := expectKind(t, db.AddElementToSet("A", s("c"), ElementInsertion{IncomingRelated: map[string][]homs.Element{"r": {s("b")}}}),
+		RelationEquationViolated)
+	if violation.Element != s("a") {
+		t.Errorf("violation at %v, want a", violation.Element)
+	}
+	if err := db.AddElementToSet("A", s("c"), ElementInsertion{IncomingRelated: map[string][]homs.Element{"r": {s("a"), s("b")}}}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,149 @@
+package relationalGraphDB
+
+import (
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+)
+
+func duplicateEquation(name string) ValidationError {
+	return ValidationError{Kind: DuplicateName, Equation: name, Detail: "there is already an equation with this name"}
+}
+
+func unimposableEquation(name string) ValidationErrors {
+	return ValidationErrors{{Kind: InvalidSchema, Equation: name, Detail: "the paths do not make an equation the schema can impose"}}
+}
+
+func (currentDB *InstantiatedDB) hasEquationNamed(name string) bool {
+	for _, other := range allEquationNames(&currentDB.underlyingGraph) {
+		if other == name {
+			return true
+		}
+	}
+	return false
+}
+
+// adds the equation to a copy of the schema with add and then checks the data against it with check
+// which is given the copy of the database with that schema
+// the schema is only replaced when the equation holds, otherwise the error has one entry per counterexample
+// inside a Transaction the check is left to Commit
+func (currentDB *InstantiatedDB) addEquation(identifier string, add func(*cgs.SchemaGraph) bool, check func(*InstantiatedDB) ValidationErrors) error {
+	if err := currentDB.beginChange(); err != nil {
+		return err
+	}
+	if currentDB.hasEquationNamed(identifier) {
+		return ValidationErrors{duplicateEquation(identifier)}
+	}
+	potentialDB := *currentDB
+	potentialDB.underlyingGraph = currentDB.underlyingGraph.Copy()
+	if !add(&potentialDB.underlyingGraph) {
+		return unimposableEquation(identifier)
+	}
+	if currentDB.deferValidation {
+		currentDB.underlyingGraph = potentialDB.underlyingGraph
+		return nil
+	}
+	if problems := check(&potentialDB); len(problems) > 0 {
+		return problems
+	}
+	currentDB.underlyingGraph = potentialDB.underlyingGraph
+	return nil
+}
+
+// lhs and rhs are function edges by name, an empty side is the identity
+func (currentDB *InstantiatedDB) AddFunctionEquation(lhs []string, rhs []string, identifier string) error {
+	return currentDB.addEquation(identifier, func(schema *cgs.SchemaGraph) bool {
+		return schema.AddFunctionEquation2(lhs, rhs, identifier)
+	}, func(potentialDB *InstantiatedDB) ValidationErrors {
+		myEquations := potentialDB.underlyingGraph.GetFunctionEquations()
+		return checkFunctionEquation(potentialDB, myEquations[len(myEquations)-1]).asValidationErrors()
+	})
+}
+
+// both sides are defined at the same elements and agree there
+func (currentDB *InstantiatedDB) AddPartialFunctionEquation(lhs []string, rhs []string, identifier string) error {
+	return currentDB.addPartialFunctionEquation(identifier, func(schema *cgs.SchemaGraph) bool {
+		return schema.AddPartialFunctionEquation2(lhs, rhs, identifier)
+	})
+}
+
+// both sides agree wherever both are defined
+func (currentDB *InstantiatedDB) AddKleenePartialFunctionEquation(lhs []string, rhs []string, identifier string) error {
+	return currentDB.addPartialFunctionEquation(identifier, func(schema *cgs.SchemaGraph) bool {
+		return schema.AddKleenePartialFunctionEquation2(lhs, rhs, identifier)
+	})
+}
+
+func (currentDB *InstantiatedDB) addPartialFunctionEquation(identifier string, add func(*cgs.SchemaGraph) bool) error {
+	return currentDB.addEquation(identifier, add, func(potentialDB *InstantiatedDB) ValidationErrors {
+		myEquations := potentialDB.underlyingGraph.GetPartialFunctionEquations()
+		return checkPartialFunctionEquation(potentialDB, myEquations[len(myEquations)-1]).asValidationErrors()
+	})
+}
+
+// both sides relate every element to the same set
+func (currentDB *InstantiatedDB) AddRelationEquation(lhs []string, rhs []string, identifier string) error {
+	return currentDB.addRelationEquation(identifier, func(schema *cgs.SchemaGraph) bool {
+		return schema.AddRelationEquation2(lhs, rhs, identifier)
+	})
+}
+
+// lhs relates every element to a subset of what rhs relates it to
+func (currentDB *InstantiatedDB) AddRelationInclusion(lhs []string, rhs []string, identifier string) error {
+	return currentDB.addRelationEquation(identifier, func(schema *cgs.SchemaGraph) bool {
+		return schema.AddRelationInclusion2(lhs, rhs, identifier)
+	})
+}
+
+func (currentDB *InstantiatedDB) addRelationEquation(identifier string, add func(*cgs.SchemaGraph) bool) error {
+	return currentDB.addEquation(identifier, add, func(potentialDB *InstantiatedDB) ValidationErrors {
+		myEquations := potentialDB.underlyingGraph.GetRelationEquations()
+		return checkRelationEquation(potentialDB, myEquations[len(myEquations)-1]).asValidationErrors()
+	})
+}
+
+// lhsPath then lhsAttribute has to give the same value as rhsPath then rhsAttribute on every element
+func (currentDB *InstantiatedDB) AddAttributeEquation(lhsPath []string, lhsAttribute string, rhsPath []string, rhsAttribute string, identifier string) error {
+	return currentDB.addEquation(identifier, func(schema *cgs.SchemaGraph) bool {
+		return schema.AddAttributeEquation2(lhsPath, lhsAttribute, rhsPath, rhsAttribute, identifier)
+	}, func(potentialDB *InstantiatedDB) ValidationErrors {
+		myEquations := potentialDB.underlyingGraph.GetAttributeEquations()
+		return checkAttributeEquation(potentialDB, myEquations[len(myEquations)-1]).asValidationErrors()
+	})
+}
+
+// removing an equation only relaxes the schema so the data needs no checking
+// the first of removers to find an equation called identifier takes it out
+func (currentDB *InstantiatedDB) removeEquation(identifier string, detail string, removers ...func(*cgs.SchemaGraph, string) int) error {
+	if err := currentDB.beginChange(); err != nil {
+		return err
+	}
+	mySchema := currentDB.underlyingGraph.Copy()
+	for _, remove := range removers {
+		if remove(&mySchema, identifier) > 0 {
+			currentDB.underlyingGraph = mySchema
+			return nil
+		}
+	}
+	return ValidationErrors{{Kind: UnknownName, Equation: identifier, Detail: detail}}
+}
+
+func (currentDB *InstantiatedDB) RemoveFunctionEquation(identifier string) error {
+	return currentDB.removeEquation(identifier, "not a function equation of the schema", (*cgs.SchemaGraph).RemoveFunctionEquation)
+}
+
+func (currentDB *InstantiatedDB) RemovePartialFunctionEquation(identifier string) error {
+	return currentDB.removeEquation(identifier, "not a partial function equation of the schema", (*cgs.SchemaGraph).RemovePartialFunctionEquation)
+}
+
+func (currentDB *InstantiatedDB) RemoveRelationEquation(identifier string) error {
+	return currentDB.removeEquation(identifier, "not a relation equation of the schema", (*cgs.SchemaGraph).RemoveRelationEquation)
+}
+
+func (currentDB *InstantiatedDB) RemoveAttributeEquation(identifier string) error {
+	return currentDB.removeEquation(identifier, "not an attribute equation of the schema", (*cgs.SchemaGraph).RemoveAttributeEquation)
+}
+
+// whichever of the four removals has an equation called identifier
+func (currentDB *InstantiatedDB) RemoveEquation(identifier string) error {
+	return currentDB.removeEquation(identifier, "not an equation of the schema", (*cgs.SchemaGraph).RemoveFunctionEquation,
+		(*cgs.SchemaGraph).RemovePartialFunctionEquation, (*cgs.SchemaGraph).RemoveRelationEquation, (*cgs.SchemaGraph).RemoveAttributeEquation)
+}
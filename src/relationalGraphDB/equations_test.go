@@ -0,0 +1,101 @@
+package relationalGraphDB
+
+import (
+	"testing"
+
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+func TestAddEquationThatHolds(t *testing.T) {
+	db := officeDB(t)
+	if err := db.AddFunctionEquation([]string{"manager", "manager"}, []string{"manager"}, "one level"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AddPartialFunctionEquation([]string{"mentor", "manager"}, []string{"mentor"}, "mentors manage themselves"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AddRelationInclusion([]string{"knows"}, []string{"knows"}, "knowing"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"one level", "mentors manage themselves", "knowing"} {
+		if !db.hasEquationNamed(name) {
+			t.Errorf("%s is not in the schema", name)
+		}
+	}
+}
+
+// each failing equation is blamed on the first element it fails at and leaves the schema as it was
+func TestAddEquationViolated(t *testing.T) {
+	s := homs.NewStringElement
+	cases := []struct {
+		name    string
+		add     func(*InstantiatedDB) error
+		kind    ViolationKind
+		element homs.Element
+	}{
+		{"self managed", func(db *InstantiatedDB) error {
+			return db.AddFunctionEquation([]string{"manager"}, []string{}, "self managed")
+		}, FunctionEquationViolated, s("bo")},
+		// the mentor of al is not their manager, and bo has a manager but no mentor
+		{"mentor is manager", func(db *InstantiatedDB) error {
+			return db.AddPartialFunctionEquation([]string{"mentor"}, []string{"manager"}, "mentor is manager")
+		}, PartialFunctionEquationViolated, s("al")},
+		// only al has both, and they differ
+		{"mentor is manager where both are", func(db *InstantiatedDB) error {
+			return db.AddKleenePartialFunctionEquation([]string{"mentor"}, []string{"manager"}, "mentor is manager where both are")
+		}, PartialFunctionEquationViolated, s("al")},
+		// neither bo nor cy knows anyone
+		{"friends of friends", func(db *InstantiatedDB) error {
+			return db.AddRelationInclusion([]string{"knows"}, []string{"knows", "knows"}, "friends of friends")
+		}, RelationEquationViolated, s("al")},
+	}
+	for _, c := range cases {
+		db := officeDB(t)
+		violation := expectKind(t, c.add(&db), c.kind)
+		if violation.Equation != c.name || violation.Element != c.element {
+			t.Errorf("%s: got %v at %v, want it at %v", c.name, violation.Equation, violation.Element, c.element)
+		}
+		if db.hasEquationNamed(c.name) {
+			t.Errorf("%s was added although it does not hold", c.name)
+		}
+	}
+}
+
+// bo is named Bo but works for al
+func TestAddAttributeEquationViolated(t *testing.T) {
+	db := companyDB(t)
+	err := db.AddAttributeEquation([]string{}, "name", []string{"worksIn", "head"}, "name", "named after the head")
+	violation := expectKind(t, err, AttributeEquationViolated)
+	if violation.Element != homs.NewStringElement("bo") {
+		t.Errorf("violated at %v, want bo", violation.Element)
+	}
+}
+
+func TestAddEquationRejected(t *testing.T) {
+	db := companyDB(t)
+	duplicate := expectKind(t, db.AddFunctionEquation([]string{"worksIn", "head"}, []string{"worksIn", "head"}, "heads work there"), DuplicateName)
+	if duplicate.Equation != "heads work there" {
+		t.Errorf("duplicate %s, want heads work there", duplicate.Equation)
+	}
+	// the two sides start at different vertices
+	expectKind(t, db.AddFunctionEquation([]string{"worksIn"}, []string{"head"}, "mismatched"), InvalidSchema)
+	if db.hasEquationNamed("mismatched") {
+		t.Error("an equation that can not be imposed was added")
+	}
+}
+
+func TestRemoveEquation(t *testing.T) {
+	db := companyDB(t)
+	expectKind(t, db.RemoveRelationEquation("heads work there"), UnknownName)
+	before := db.changes
+	if err := db.RemoveEquation("heads work there"); err != nil {
+		t.Fatal(err)
+	}
+	if db.changes != before+1 {
+		t.Errorf("removing one equation counted as %d changes", db.changes-before)
+	}
+	if db.hasEquationNamed("heads work there") {
+		t.Error("heads work there is still in the schema")
+	}
+	expectKind(t, db.RemoveEquation("heads work there"), UnknownName)
+}
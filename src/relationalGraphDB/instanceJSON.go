@@ -0,0 +1,142 @@
+package relationalGraphDB
+
+import (
+	"encoding/json"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// the JSON form of an instance is its schema, the set over every vertex
+// and the table of every edge over its source set, all in schema order
+// edges and vertices are referred to by name
+
+type setJSON struct {
+	Vertex   string         `json:"vertex"`
+	Elements []homs.Element `json:"elements"`
+}
+
+type functionDataJSON struct {
+	Edge  string             `json:"edge"`
+	Table homs.FunctionTable `json:"table"`
+}
+
+type partialFunctionDataJSON struct {
+	Edge  string                    `json:"edge"`
+	Table homs.PartialFunctionTable `json:"table"`
+}
+
+type relationDataJSON struct {
+	Edge  string             `json:"edge"`
+	Table homs.RelationTable `json:"table"`
+}
+
+type instanceJSON struct {
+	Schema           cgs.SchemaGraph           `json:"schema"`
+	Sets             []setJSON                 `json:"sets"`
+	Functions        []functionDataJSON        `json:"functions"`
+	PartialFunctions []partialFunctionDataJSON `json:"partial_functions"`
+	Relations        []relationDataJSON        `json:"relations"`
+	Attributes       []functionDataJSON        `json:"attributes"`
+}
+
+// every closure is tabulated over the current source set, so this is only as good as ValidateDB says
+func (currentDB InstantiatedDB) MarshalJSON() ([]byte, error) {
+	result := instanceJSON{Schema: currentDB.underlyingGraph, Sets: make([]setJSON, 0), Functions: make([]functionDataJSON, 0),
+		PartialFunctions: make([]partialFunctionDataJSON, 0), Relations: make([]relationDataJSON, 0), Attributes: make([]functionDataJSON, 0)}
+	for _, v := range currentDB.underlyingGraph.GetVertices() {
+		elements := currentDB.underlyingSets[v]
+		if elements == nil {
+			elements = make([]homs.Element, 0)
+		}
+		result.Sets = append(result.Sets, setJSON{Vertex: v.GetIdentifier(), Elements: elements})
+	}
+	for _, edge := range currentDB.underlyingGraph.GetFunctionEdges() {
+		if table, present := currentDB.GetFunctionTable(edge); present {
+			result.Functions = append(result.Functions, functionDataJSON{Edge: edge.GetIdentifier(), Table: table})
+		}
+	}
+	for _, edge := range currentDB.underlyingGraph.GetPartialFunctionEdges() {
+		if table, present := currentDB.GetPartialFunctionTable(edge); present {
+			result.PartialFunctions = append(result.PartialFunctions, partialFunctionDataJSON{Edge: edge.GetIdentifier(), Table: table})
+		}
+	}
+	for _, edge := range currentDB.underlyingGraph.GetRelationEdges() {
+		if table, present := currentDB.GetRelationTable(edge); present {
+			result.Relations = append(result.Relations, relationDataJSON{Edge: edge.GetIdentifier(), Table: table})
+		}
+	}
+	for _, edge := range currentDB.underlyingGraph.GetAttributeEdges() {
+		if table, present := currentDB.GetAttributeTable(edge); present {
+			result.Attributes = append(result.Attributes, functionDataJSON{Edge: edge.GetIdentifier(), Table: table})
+		}
+	}
+	return json.Marshal(result)
+}
+
+func unknownEdgeData(name string) ValidationError {
+	return ValidationError{Kind: InvalidSchema, Edge: name, Detail: "data given for an edge the schema does not have"}
+}
+
+// the schema is checked as it is read and then the whole instance goes through ValidateDB
+// the error is a ValidationErrors unless the JSON itself is malformed
+// and currentDB is only replaced when there is no error
+func (currentDB *InstantiatedDB) UnmarshalJSON(data []byte) error {
+	if err := currentDB.beginChange(); err != nil {
+		return err
+	}
+	var input instanceJSON
+	if err := json.Unmarshal(data, &input); err != nil {
+		return err
+	}
+	problems := make(ValidationErrors, 0)
+	sets := make(map[cgs.Vertex]([]homs.Element), len(input.Sets))
+	for _, set := range input.Sets {
+		sets[cgs.NewVertex(set.Vertex)] = set.Elements
+	}
+	functions := make(map[cgs.FunctionEdge](homs.FunctionTable), len(input.Functions))
+	for _, entry := range input.Functions {
+		edge, found := input.Schema.GetFunctionEdgeByName(entry.Edge)
+		if !found {
+			problems = append(problems, unknownEdgeData(entry.Edge))
+			continue
+		}
+		functions[edge] = entry.Table
+	}
+	partialFunctions := make(map[cgs.PartialFunctionEdge](homs.PartialFunctionTable), len(input.PartialFunctions))
+	for _, entry := range input.PartialFunctions {
+		edge, found := input.Schema.GetDefPartialFunctionEdgeByName(entry.Edge)
+		if !found {
+			problems = append(problems, unknownEdgeData(entry.Edge))
+			continue
+		}
+		partialFunctions[edge] = entry.Table
+	}
+	relations := make(map[cgs.RelationEdge](homs.RelationTable), len(input.Relations))
+	for _, entry := range input.Relations {
+		edge, found := input.Schema.GetDefRelationEdgeByName(entry.Edge)
+		if !found {
+			problems = append(problems, unknownEdgeData(entry.Edge))
+			continue
+		}
+		relations[edge] = entry.Table
+	}
+	attributes := make(map[cgs.AttributeEdge](homs.FunctionTable), len(input.Attributes))
+	for _, entry := range input.Attributes {
+		edge, found := input.Schema.GetAttributeEdgeByName(entry.Edge)
+		if !found {
+			problems = append(problems, unknownEdgeData(entry.Edge))
+			continue
+		}
+		attributes[edge] = entry.Table
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	result, err := NewInstantiatedDBFromTables(input.Schema, sets, functions, partialFunctions, relations, attributes)
+	if err != nil {
+		return err
+	}
+	currentDB.replaceWith(result)
+	return nil
+}
@@ -0,0 +1,143 @@
+package relationalGraphDB
+
+import (
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+func notOverSchema(which string) ValidationErrors {
+	return ValidationErrors{{Kind: InvalidSchema, Detail: "the database is not an instance of the " + which + " of the mapping"}}
+}
+
+// the instance over the source of mapping that targetDB is seen as through it
+// each vertex gets the set of its image and each edge the composite of the path it is sent to
+// targetDB must be over exactly the target of mapping
+// the result is materialized, so it does not change when targetDB does
+// the error is a ValidationErrors, either from the schemas not matching or from ValidateDB on the result
+func Delta(mapping cgs.Mapping, targetDB InstantiatedDB) (InstantiatedDB, error) {
+	if !cgs.SameSchema(mapping.GetTarget(), targetDB.underlyingGraph) {
+		return InstantiatedDB{}, notOverSchema("target")
+	}
+	source := mapping.GetSource()
+	sets := make(map[cgs.Vertex]([]homs.Element))
+	for _, v := range source.GetVertices() {
+		image, _ := mapping.VertexImage(v)
+		sets[v] = append([]homs.Element{}, targetDB.underlyingSets[image]...)
+	}
+	functions := make(map[cgs.FunctionEdge](homs.MyFunction))
+	for _, edge := range source.GetFunctionEdges() {
+		path, _ := mapping.FunctionEdgeImage(edge)
+		if composite, valid := targetDB.functionPath(path); valid {
+			functions[edge] = composite
+		}
+	}
+	partialFunctions := make(map[cgs.PartialFunctionEdge](homs.MyPartialFunction))
+	for _, edge := range source.GetPartialFunctionEdges() {
+		path, _ := mapping.PartialFunctionEdgeImage(edge)
+		start, _ := mapping.VertexImage(edge.GetSource())
+		if composite, valid := targetDB.partialFunctionPath(path, start); valid {
+			partialFunctions[edge] = composite
+		}
+	}
+	relations := make(map[cgs.RelationEdge](homs.MyRelation))
+	for _, edge := range source.GetRelationEdges() {
+		path, _ := mapping.RelationEdgeImage(edge)
+		if composite, valid := targetDB.relationPath(path); valid {
+			relations[edge] = composite
+		}
+	}
+	attributes := make(map[cgs.AttributeEdge](homs.MyFunction))
+	for _, edge := range source.GetAttributeEdges() {
+		path, attribute, _ := mapping.AttributeEdgeImage(edge)
+		if composite, valid := targetDB.attributeTerm(path, attribute); valid {
+			attributes[edge] = composite
+		}
+	}
+	toReturn := assembleDB(source, sets, functions, partialFunctions, relations, attributes)
+	toReturn.Materialize()
+	return toReturn, ValidateDB(toReturn).asError()
+}
+
+// how many rounds of the chase Sigma is given when the caller has no better bound
+const DefaultChaseRounds = 100
+
+// the instance over the target of mapping freely generated by sourceDB
+// every element of sourceDB is an element of the image of its vertex, and every edge value in sourceDB
+// holds along the path the edge is sent to
+// the chase then adds the values the target needs that are missing, for function edges and for the
+// sides of equations that have to be defined, and merges the elements the equations of the target make equal
+//
+// an element of sourceDB keeps its name unless more than one vertex of the source is sent to the image of its vertex,
+// then it is the tuple of the vertex name and the element
+// an added element is the tuple of the edge name and the element it is the value on,
+// paired with a number when that name is already taken at its vertex
+// sourceDB must be a valid instance of exactly the source of mapping
+// the error is a ValidationErrors, ChaseDidNotTerminate when more than maxRounds rounds changed the data,
+// ConflictingValues when two attribute values are forced to be equal and MissingValue for attributes of added elements
+func Sigma(mapping cgs.Mapping, sourceDB InstantiatedDB, maxRounds int) (InstantiatedDB, error) {
+	if !cgs.SameSchema(mapping.GetSource(), sourceDB.underlyingGraph) {
+		return InstantiatedDB{}, notOverSchema("source")
+	}
+	if problems := ValidateDB(sourceDB); len(problems) > 0 {
+		return InstantiatedDB{}, problems
+	}
+	source, target := mapping.GetSource(), mapping.GetTarget()
+	state := newChase(&target)
+	sharedImages := make(map[cgs.Vertex]int)
+	for _, v := range source.GetVertices() {
+		image, _ := mapping.VertexImage(v)
+		sharedImages[image]++
+	}
+	starting := make(map[cgs.Vertex]map[homs.Element]int)
+	for _, v := range source.GetVertices() {
+		image, _ := mapping.VertexImage(v)
+		starting[v] = make(map[homs.Element]int)
+		for _, x := range sourceDB.underlyingSets[v] {
+			name := x
+			if sharedImages[image] > 1 {
+				name = homs.NewTupleElement(homs.NewStringElement(v.GetIdentifier()), x)
+			}
+			starting[v][x] = state.newElement(image, chaseOrigin{starting: true, element: name})
+		}
+	}
+	for _, edge := range source.GetFunctionEdges() {
+		content := sourceDB.underlyingFunctions[edge]
+		path := state.pathEdges(mapping.PathImage([]string{edge.GetIdentifier()}))
+		for _, x := range sourceDB.underlyingSets[edge.GetSource()] {
+			state.ensurePath(starting[edge.GetSource()][x], path, starting[edge.GetTarget()][content.Evaluate(x)])
+		}
+	}
+	for _, edge := range source.GetPartialFunctionEdges() {
+		content := sourceDB.underlyingPartialFunctions[edge]
+		path := state.pathEdges(mapping.PathImage([]string{edge.GetIdentifier()}))
+		for _, x := range sourceDB.underlyingSets[edge.GetSource()] {
+			if y, defined := content.Evaluate(x); defined {
+				state.ensurePath(starting[edge.GetSource()][x], path, starting[edge.GetTarget()][y])
+			}
+		}
+	}
+	for _, edge := range source.GetRelationEdges() {
+		content := sourceDB.underlyingRelations[edge]
+		path := state.pathEdges(mapping.PathImage([]string{edge.GetIdentifier()}))
+		for _, x := range sourceDB.underlyingSets[edge.GetSource()] {
+			for _, y := range content.Evaluate(x) {
+				state.ensurePath(starting[edge.GetSource()][x], path, starting[edge.GetTarget()][y])
+			}
+		}
+	}
+	for _, edge := range source.GetAttributeEdges() {
+		content := sourceDB.underlyingAttributes[edge]
+		path, attribute, _ := mapping.AttributeEdgeImage(edge)
+		for _, x := range sourceDB.underlyingSets[edge.GetSource()] {
+			state.setAttribute(attribute.GetIdentifier(), state.follow(starting[edge.GetSource()][x], state.pathEdges(edgeNames(path))), content.Evaluate(x))
+		}
+	}
+	if problems := state.run(maxRounds); len(problems) > 0 {
+		return InstantiatedDB{}, problems
+	}
+	toReturn, problems := state.result()
+	if len(problems) > 0 {
+		return InstantiatedDB{}, problems
+	}
+	return toReturn, ValidateDB(toReturn).asError()
+}
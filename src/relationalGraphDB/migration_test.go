@@ -0,0 +1,287 @@
+package relationalGraphDB
+
+import (
+	"testing"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+func mustSucceed(t *testing.T, ok bool, what string) {
+	t.Helper()
+	if !ok {
+		t.Fatal("could not " + what)
+	}
+}
+
+// people each working in a department, departments each with a head who works there
+func companySchema(t *testing.T) cgs.SchemaGraph {
+	t.Helper()
+	schema := cgs.EmptySchemaGraph()
+	mustSucceed(t, schema.AddVertex2("Person"), "add Person")
+	mustSucceed(t, schema.AddVertex2("Department"), "add Department")
+	mustSucceed(t, schema.AddFunctionEdge2("Person", "Department", "worksIn"), "add worksIn")
+	mustSucceed(t, schema.AddFunctionEdge2("Department", "Person", "head"), "add head")
+	mustSucceed(t, schema.AddFunctionEquation2([]string{"head", "worksIn"}, []string{}, "heads work there"), "add heads work there")
+	mustSucceed(t, schema.AddAttributeEdge2("Person", cgs.StringSort, "name"), "add name")
+	return schema
+}
+
+func companyDB(t *testing.T) InstantiatedDB {
+	t.Helper()
+	s := homs.NewStringElement
+	db, err := NewInstantiatedDBFromTables(companySchema(t),
+		map[cgs.Vertex]([]homs.Element){cgs.NewVertex("Person"): {s("al"), s("bo"), s("cy")}, cgs.NewVertex("Department"): {s("sales"), s("ops")}},
+		map[cgs.FunctionEdge](homs.FunctionTable){
+			functionEdge(t, companySchema(t), "worksIn"): homs.NewFunctionTable(map[homs.Element]homs.Element{s("al"): s("sales"), s("bo"): s("sales"), s("cy"): s("ops")}),
+			functionEdge(t, companySchema(t), "head"):    homs.NewFunctionTable(map[homs.Element]homs.Element{s("sales"): s("al"), s("ops"): s("cy")})},
+		nil, nil,
+		map[cgs.AttributeEdge](homs.FunctionTable){
+			attributeEdge(t, companySchema(t), "name"): homs.NewFunctionTable(map[homs.Element]homs.Element{s("al"): s("Al"), s("bo"): s("Bo"), s("cy"): s("Cy")})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func functionEdge(t *testing.T, schema cgs.SchemaGraph, name string) cgs.FunctionEdge {
+	t.Helper()
+	edge, present := schema.GetFunctionEdgeByName(name)
+	mustSucceed(t, present, "find "+name)
+	return edge
+}
+
+func attributeEdge(t *testing.T, schema cgs.SchemaGraph, name string) cgs.AttributeEdge {
+	t.Helper()
+	edge, present := schema.GetAttributeEdgeByName(name)
+	mustSucceed(t, present, "find "+name)
+	return edge
+}
+
+// employees each with a boss, the boss of the boss is the boss
+func staffSchema(t *testing.T) cgs.SchemaGraph {
+	t.Helper()
+	schema := cgs.EmptySchemaGraph()
+	mustSucceed(t, schema.AddVertex2("Employee"), "add Employee")
+	mustSucceed(t, schema.AddFunctionEdge2("Employee", "Employee", "boss"), "add boss")
+	mustSucceed(t, schema.AddFunctionEquation2([]string{"boss", "boss"}, []string{"boss"}, "one level"), "add one level")
+	mustSucceed(t, schema.AddAttributeEdge2("Employee", cgs.StringSort, "label"), "add label")
+	return schema
+}
+
+// an employee is a person and their boss is the head of their department
+func staffMapping(t *testing.T) cgs.Mapping {
+	t.Helper()
+	mapping, err := cgs.NewMapping(staffSchema(t), companySchema(t), map[string]string{"Employee": "Person"},
+		map[string][]string{"boss": {"worksIn", "head"}, "label": {"name"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mapping
+}
+
+func TestDelta(t *testing.T) {
+	staff, err := Delta(staffMapping(t), companyDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := staff.GetSchema()
+	employees, _ := staff.GetUnderlyingSet(cgs.NewVertex("Employee"))
+	if len(employees) != 3 {
+		t.Errorf("%d employees, want 3", len(employees))
+	}
+	boss, _ := staff.GetFunction(functionEdge(t, schema, "boss"))
+	label, _ := staff.GetAttribute(attributeEdge(t, schema, "label"))
+	for person, want := range map[string]string{"al": "al", "bo": "al", "cy": "cy"} {
+		if got := boss.Evaluate(homs.NewStringElement(person)); got != homs.NewStringElement(want) {
+			t.Errorf("boss of %s is %v, want %s", person, got, want)
+		}
+	}
+	if got := label.Evaluate(homs.NewStringElement("bo")); got != homs.NewStringElement("Bo") {
+		t.Errorf("label of bo is %v, want Bo", got)
+	}
+}
+
+func TestDeltaWrongSchema(t *testing.T) {
+	if _, err := Delta(staffMapping(t), EmptyInstantiatedDB()); err == nil {
+		t.Error("Delta accepted a database over another schema")
+	}
+}
+
+func staffDB(t *testing.T) InstantiatedDB {
+	t.Helper()
+	s := homs.NewStringElement
+	db, err := NewInstantiatedDBFromTables(staffSchema(t),
+		map[cgs.Vertex]([]homs.Element){cgs.NewVertex("Employee"): {s("al"), s("bo")}},
+		map[cgs.FunctionEdge](homs.FunctionTable){
+			functionEdge(t, staffSchema(t), "boss"): homs.NewFunctionTable(map[homs.Element]homs.Element{s("al"): s("al"), s("bo"): s("al")})},
+		nil, nil,
+		map[cgs.AttributeEdge](homs.FunctionTable){
+			attributeEdge(t, staffSchema(t), "label"): homs.NewFunctionTable(map[homs.Element]homs.Element{s("al"): s("Al"), s("bo"): s("Bo")})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// al and bo both get a department, heads work in their own department makes them the same one
+func TestSigma(t *testing.T) {
+	company, err := Sigma(staffMapping(t), staffDB(t), DefaultChaseRounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := company.GetSchema()
+	people, _ := company.GetUnderlyingSet(cgs.NewVertex("Person"))
+	departments, _ := company.GetUnderlyingSet(cgs.NewVertex("Department"))
+	if len(people) != 2 || len(departments) != 1 {
+		t.Fatalf("people %v and departments %v, want 2 and 1", people, departments)
+	}
+	department := homs.NewTupleElement(homs.NewStringElement("worksIn"), homs.NewStringElement("al"))
+	if departments[0] != department {
+		t.Errorf("department is %v, want %v", departments[0], department)
+	}
+	worksIn, _ := company.GetFunction(functionEdge(t, schema, "worksIn"))
+	head, _ := company.GetFunction(functionEdge(t, schema, "head"))
+	if got := worksIn.Evaluate(homs.NewStringElement("bo")); got != department {
+		t.Errorf("bo works in %v, want %v", got, department)
+	}
+	if got := head.Evaluate(department); got != homs.NewStringElement("al") {
+		t.Errorf("head is %v, want al", got)
+	}
+	name, _ := company.GetAttribute(attributeEdge(t, schema, "name"))
+	if got := name.Evaluate(homs.NewStringElement("bo")); got != homs.NewStringElement("Bo") {
+		t.Errorf("name of bo is %v, want Bo", got)
+	}
+}
+
+func TestSigmaIdentity(t *testing.T) {
+	staff := staffDB(t)
+	pushed, err := Sigma(cgs.IdentityMapping(staffSchema(t)), staff, DefaultChaseRounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := Diff(staff, pushed); !diff.IsEmpty() {
+		t.Errorf("identity changed the data: %+v", diff)
+	}
+}
+
+// nothing is missing from the data, so the chase needs no rounds
+func TestSigmaNoRounds(t *testing.T) {
+	staff := staffDB(t)
+	pushed, err := Sigma(cgs.IdentityMapping(staffSchema(t)), staff, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := Diff(staff, pushed); !diff.IsEmpty() {
+		t.Errorf("identity changed the data: %+v", diff)
+	}
+	if _, err := Sigma(staffMapping(t), staffDB(t), 0); err == nil {
+		t.Error("departments were added without any rounds")
+	}
+}
+
+// the head made for sales would be called ["head", "sales"], which is already a person
+func TestSigmaAddedNameTaken(t *testing.T) {
+	unrelated := cgs.EmptySchemaGraph()
+	mustSucceed(t, unrelated.AddVertex2("Department"), "add Department")
+	mustSucceed(t, unrelated.AddVertex2("Person"), "add Person")
+	headed := unrelated.Copy()
+	mustSucceed(t, headed.AddFunctionEdge2("Department", "Person", "head"), "add head")
+	mapping, err := cgs.NewMapping(unrelated, headed, map[string]string{"Department": "Department", "Person": "Person"}, map[string][]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := homs.NewStringElement
+	impostor := homs.NewTupleElement(s("head"), s("sales"))
+	db, err := NewInstantiatedDB(unrelated, map[cgs.Vertex]([]homs.Element){cgs.NewVertex("Department"): {s("sales")},
+		cgs.NewVertex("Person"): {impostor}}, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Sigma(mapping, db, DefaultChaseRounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	people, _ := result.GetUnderlyingSet(cgs.NewVertex("Person"))
+	head, _ := result.GetFunction(functionEdge(t, headed, "head"))
+	if len(people) != 2 || people[0] != impostor || head.Evaluate(s("sales")) == impostor {
+		t.Errorf("people %v with %v as head of sales, want a new person as head", people, head.Evaluate(s("sales")))
+	}
+}
+
+// a successor function with nothing to stop it needs infinitely many elements
+func TestSigmaDoesNotTerminate(t *testing.T) {
+	points := cgs.EmptySchemaGraph()
+	mustSucceed(t, points.AddVertex2("N"), "add N")
+	numbers := points.Copy()
+	mustSucceed(t, numbers.AddFunctionEdge2("N", "N", "succ"), "add succ")
+	mapping, err := cgs.NewMapping(points, numbers, map[string]string{"N": "N"}, map[string][]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zero, err := NewInstantiatedDB(points, map[cgs.Vertex]([]homs.Element){cgs.NewVertex("N"): homs.NewIntElements([]int{0})}, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Sigma(mapping, zero, 10)
+	problems, isValidation := err.(ValidationErrors)
+	if !isValidation || len(problems) != 1 || problems[0].Kind != ChaseDidNotTerminate {
+		t.Errorf("got %v, want the chase not to terminate", err)
+	}
+}
+
+// along the inclusion of two vertices into an edge between them, the source of the edge becomes the product
+func TestPiProduct(t *testing.T) {
+	pair := cgs.EmptySchemaGraph()
+	mustSucceed(t, pair.AddVertex2("A"), "add A")
+	mustSucceed(t, pair.AddVertex2("B"), "add B")
+	arrow := pair.Copy()
+	mustSucceed(t, arrow.AddFunctionEdge2("A", "B", "f"), "add f")
+	mapping, err := cgs.NewMapping(pair, arrow, map[string]string{"A": "A", "B": "B"}, map[string][]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := NewInstantiatedDB(pair, map[cgs.Vertex]([]homs.Element){cgs.NewVertex("A"): homs.NewIntElements([]int{1, 2}),
+		cgs.NewVertex("B"): homs.NewStringElements([]string{"x", "y", "z"})}, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	product, err := Pi(mapping, db, DefaultChaseRounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	as, _ := product.GetUnderlyingSet(cgs.NewVertex("A"))
+	bs, _ := product.GetUnderlyingSet(cgs.NewVertex("B"))
+	if len(as) != 6 || len(bs) != 3 {
+		t.Fatalf("A is %v and B is %v, want 6 and 3 elements", as, bs)
+	}
+	f, _ := product.GetFunction(functionEdge(t, arrow, "f"))
+	pairElement := homs.NewTupleElement(homs.NewIntElement(2), homs.NewStringElement("y"))
+	if got := f.Evaluate(pairElement); got != homs.NewStringElement("y") {
+		t.Errorf("f%v is %v, want y", pairElement, got)
+	}
+}
+
+func TestPiIdentity(t *testing.T) {
+	schema := companySchema(t)
+	schema.RemoveAttributeEdge("name")
+	db := companyDB(t)
+	if _, err := db.RemoveAttributeEdge("name"); err != nil {
+		t.Fatal(err)
+	}
+	pushed, err := Pi(cgs.IdentityMapping(schema), db, DefaultChaseRounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := Diff(db, pushed); !diff.IsEmpty() {
+		t.Errorf("identity changed the data: %+v", diff)
+	}
+}
+
+func TestPiUnsupported(t *testing.T) {
+	_, err := Pi(staffMapping(t), staffDB(t), DefaultChaseRounds)
+	problems, isValidation := err.(ValidationErrors)
+	if !isValidation || len(problems) != 2 || problems[0].Kind != UnsupportedEdge {
+		t.Errorf("got %v, want label and name reported as unsupported", err)
+	}
+}
@@ -0,0 +1,227 @@
+package relationalGraphDB
+
+import (
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// one vertex t of the target of a Pi migration
+// the paths out of t up to the equations of the target are found by chasing a single element at t
+// an element of Pi at t is a compatible family, one element of sourceDB for each vertex s of the source and
+// each such path from t to the image of s, with the source edges between them respected
+type piVertex struct {
+	vertex    cgs.Vertex
+	state     *chase
+	variables []piVariable
+	index     map[piVariable]int
+	// the edges of the source out of each variable and the variable they lead to
+	constraints [][]piConstraint
+	// variables from which the rest are determined, an element is named by its values on these
+	starts   []int
+	families [][]homs.Element
+}
+
+type piVariable struct {
+	source cgs.Vertex
+	class  int
+}
+
+type piConstraint struct {
+	edge cgs.FunctionEdge
+	to   int
+}
+
+// only function edges are handled, every other edge of either schema is reported
+func unsupportedByPi(schema cgs.SchemaGraph) ValidationErrors {
+	toReturn := make(ValidationErrors, 0)
+	unsupported := func(edge string, kind string) {
+		toReturn = append(toReturn, ValidationError{Kind: UnsupportedEdge, Edge: edge, Detail: "Pi only handles function edges, not " + kind})
+	}
+	for _, edge := range schema.GetPartialFunctionEdges() {
+		unsupported(edge.GetIdentifier(), "partial function edges")
+	}
+	for _, edge := range schema.GetRelationEdges() {
+		unsupported(edge.GetIdentifier(), "relation edges")
+	}
+	for _, edge := range schema.GetAttributeEdges() {
+		unsupported(edge.GetIdentifier(), "attributes")
+	}
+	return toReturn
+}
+
+// the instance over the target of mapping whose elements at each vertex t are the compatible families of
+// elements of sourceDB, indexed by the paths from t to the images of the source vertices
+// this is the right adjoint of Delta, so along the identity it gives back sourceDB
+//
+// an element is named by its values on a few paths it is determined by, the empty path first and then in the order of the source vertices,
+// as a tuple of those values, or just the value when there is one
+// both schemas may only have function edges and the paths out of every vertex of the target must be finite,
+// which is checked with a chase of at most maxRounds rounds
+// the error is a ValidationErrors, UnsupportedEdge for every other edge and ChaseDidNotTerminate when there are infinitely many paths
+func Pi(mapping cgs.Mapping, sourceDB InstantiatedDB, maxRounds int) (InstantiatedDB, error) {
+	if !cgs.SameSchema(mapping.GetSource(), sourceDB.underlyingGraph) {
+		return InstantiatedDB{}, notOverSchema("source")
+	}
+	source, target := mapping.GetSource(), mapping.GetTarget()
+	problems := append(unsupportedByPi(source), unsupportedByPi(target)...)
+	if len(problems) > 0 {
+		return InstantiatedDB{}, problems
+	}
+	if problems := ValidateDB(sourceDB); len(problems) > 0 {
+		return InstantiatedDB{}, problems
+	}
+	vertices := make(map[cgs.Vertex]*piVertex)
+	sets := make(map[cgs.Vertex]([]homs.Element))
+	for _, t := range target.GetVertices() {
+		current, problems := newPiVertex(mapping, &target, t, maxRounds)
+		if len(problems) > 0 {
+			return InstantiatedDB{}, problems
+		}
+		current.enumerate(&sourceDB)
+		vertices[t] = current
+		sets[t] = make([]homs.Element, 0, len(current.families))
+		for _, family := range current.families {
+			sets[t] = append(sets[t], current.name(family))
+		}
+	}
+	functions := make(map[cgs.FunctionEdge](homs.FunctionTable))
+	for _, edge := range target.GetFunctionEdges() {
+		from, to := vertices[edge.GetSource()], vertices[edge.GetTarget()]
+		along := from.along(edge, to)
+		values := make(map[homs.Element]homs.Element, len(from.families))
+		for _, family := range from.families {
+			image := make([]homs.Element, len(to.variables))
+			for i, j := range along {
+				image[i] = family[j]
+			}
+			values[from.name(family)] = to.name(image)
+		}
+		functions[edge] = homs.NewFunctionTable(values)
+	}
+	toReturn := assembleDBFromTables(target, sets, functions, nil, nil, nil)
+	return toReturn, ValidateDB(toReturn).asError()
+}
+
+func newPiVertex(mapping cgs.Mapping, target *cgs.SchemaGraph, vertex cgs.Vertex, maxRounds int) (*piVertex, ValidationErrors) {
+	toReturn := &piVertex{vertex: vertex, state: newChase(target), index: make(map[piVariable]int)}
+	toReturn.state.newElement(vertex, chaseOrigin{starting: true})
+	if problems := toReturn.state.run(maxRounds); len(problems) > 0 {
+		return nil, problems
+	}
+	source := mapping.GetSource()
+	for _, s := range source.GetVertices() {
+		image, _ := mapping.VertexImage(s)
+		for _, class := range toReturn.state.elementsOf(image) {
+			toReturn.index[piVariable{source: s, class: class}] = len(toReturn.variables)
+			toReturn.variables = append(toReturn.variables, piVariable{source: s, class: class})
+		}
+	}
+	toReturn.constraints = make([][]piConstraint, len(toReturn.variables))
+	for _, edge := range source.GetFunctionEdges() {
+		path := toReturn.state.pathEdges(mapping.PathImage([]string{edge.GetIdentifier()}))
+		for i, variable := range toReturn.variables {
+			if variable.source != edge.GetSource() {
+				continue
+			}
+			class, _ := toReturn.state.value(variable.class, path)
+			to := toReturn.index[piVariable{source: edge.GetTarget(), class: toReturn.state.find(class)}]
+			toReturn.constraints[i] = append(toReturn.constraints[i], piConstraint{edge: edge, to: to})
+		}
+	}
+	// the empty path first, so that along the identity an element keeps its name
+	order := make([]int, 0, len(toReturn.variables))
+	for i, variable := range toReturn.variables {
+		if variable.class == 0 {
+			order = append(order, i)
+		}
+	}
+	for i, variable := range toReturn.variables {
+		if variable.class != 0 {
+			order = append(order, i)
+		}
+	}
+	reached := make([]bool, len(toReturn.variables))
+	for _, i := range order {
+		if reached[i] {
+			continue
+		}
+		toReturn.starts = append(toReturn.starts, i)
+		queue := []int{i}
+		reached[i] = true
+		for len(queue) > 0 {
+			for _, constraint := range toReturn.constraints[queue[0]] {
+				if !reached[constraint.to] {
+					reached[constraint.to] = true
+					queue = append(queue, constraint.to)
+				}
+			}
+			queue = queue[1:]
+		}
+	}
+	return toReturn, nil
+}
+
+// every compatible family, choosing the values on the starts in order and following the constraints from each
+func (current *piVertex) enumerate(sourceDB *InstantiatedDB) {
+	current.families = make([][]homs.Element, 0)
+	var extend func(family []homs.Element, assigned []bool, next int)
+	extend = func(family []homs.Element, assigned []bool, next int) {
+		if next == len(current.starts) {
+			current.families = append(current.families, family)
+			return
+		}
+		start := current.starts[next]
+		for _, x := range sourceDB.underlyingSets[current.variables[start].source] {
+			extendedFamily, extendedAssigned := append([]homs.Element{}, family...), append([]bool{}, assigned...)
+			if current.propagate(sourceDB, extendedFamily, extendedAssigned, start, x) {
+				extend(extendedFamily, extendedAssigned, next+1)
+			}
+		}
+	}
+	extend(make([]homs.Element, len(current.variables)), make([]bool, len(current.variables)), 0)
+}
+
+// false if giving variable the value x contradicts a value already given
+func (current *piVertex) propagate(sourceDB *InstantiatedDB, family []homs.Element, assigned []bool, variable int, x homs.Element) bool {
+	family[variable], assigned[variable] = x, true
+	queue := []int{variable}
+	for len(queue) > 0 {
+		from := queue[0]
+		queue = queue[1:]
+		for _, constraint := range current.constraints[from] {
+			y := sourceDB.underlyingFunctions[constraint.edge].Evaluate(family[from])
+			if assigned[constraint.to] {
+				if family[constraint.to] != y {
+					return false
+				}
+				continue
+			}
+			family[constraint.to], assigned[constraint.to] = y, true
+			queue = append(queue, constraint.to)
+		}
+	}
+	return true
+}
+
+func (current *piVertex) name(family []homs.Element) homs.Element {
+	if len(current.starts) == 1 {
+		return family[current.starts[0]]
+	}
+	values := make([]homs.Element, len(current.starts))
+	for i, start := range current.starts {
+		values[i] = family[start]
+	}
+	return homs.NewTupleElement(values...)
+}
+
+// for each variable of to, the variable of this vertex it takes its value from when moving along edge
+// a path from the target of edge becomes a path from its source by putting edge in front
+func (current *piVertex) along(edge cgs.FunctionEdge, to *piVertex) []int {
+	toReturn := make([]int, len(to.variables))
+	for i, variable := range to.variables {
+		path := append([]string{edge.GetIdentifier()}, to.state.pathTo(variable.class)...)
+		class, _ := current.state.value(0, current.state.pathEdges(path))
+		toReturn[i] = current.index[piVariable{source: variable.source, class: current.state.find(class)}]
+	}
+	return toReturn
+}
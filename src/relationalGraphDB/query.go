@@ -0,0 +1,426 @@
+package relationalGraphDB
+
+import (
+	"fmt"
+	"strings"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// a variable followed along a path of function edges of the source, possibly ending in an attribute
+// or a constant attribute value when IsConstant is true
+type Term struct {
+	Variable   string
+	Path       []string
+	Constant   homs.Element
+	IsConstant bool
+}
+
+func VariableTerm(variable string, path ...string) Term {
+	return Term{Variable: variable, Path: append([]string{}, path...)}
+}
+
+func ConstantTerm(value homs.Element) Term {
+	return Term{Constant: value, IsConstant: true}
+}
+
+func (term Term) String() string {
+	if term.IsConstant {
+		return term.Constant.String()
+	}
+	return strings.Join(append([]string{term.Variable}, term.Path...), ".")
+}
+
+// Variable ranges over the elements of the source vertex called Vertex
+type Generator struct {
+	Variable string
+	Vertex   string
+}
+
+type PathEquality struct {
+	LHS Term
+	RHS Term
+}
+
+// how the elements of one vertex of the target are found
+// there is one for every choice of the From variables satisfying all the Where equalities
+// Attributes gives the term for each attribute of the target on the vertex
+// ForeignKeys gives, for each function edge of the target out of the vertex, the term for each variable of the block at its target
+// all the terms are over the variables of this block
+type QueryBlock struct {
+	From        []Generator
+	Where       []PathEquality
+	Attributes  map[string]Term
+	ForeignKeys map[string](map[string]Term)
+}
+
+// an uber-flower query from the schema source to the schema target, one block for each vertex of target
+// evaluating it on an instance of source gives an instance of target
+type Query struct {
+	source cgs.SchemaGraph
+	target cgs.SchemaGraph
+	blocks map[string]QueryBlock
+}
+
+// what a term gives, an element of a vertex, a value of a sort, or a constant of some kind
+type termType struct {
+	vertex     cgs.Vertex
+	sort       cgs.Sort
+	isValue    bool
+	isConstant bool
+	kind       homs.ElementKind
+}
+
+func (this termType) matches(that termType) bool {
+	switch {
+	case this.isConstant && that.isConstant:
+		return this.kind == that.kind
+	case this.isConstant:
+		return that.isValue && SortKind(that.sort) == this.kind
+	case that.isConstant:
+		return this.isValue && SortKind(this.sort) == that.kind
+	case this.isValue:
+		return that.isValue && this.sort == that.sort
+	}
+	return !that.isValue && this.vertex == that.vertex
+}
+
+func (this termType) String() string {
+	switch {
+	case this.isConstant:
+		return "a constant"
+	case this.isValue:
+		return "a " + this.sort.String()
+	}
+	return "an element of " + this.vertex.GetIdentifier()
+}
+
+func invalidQuery(vertex string, edge string, detail string) ValidationError {
+	return ValidationError{Kind: InvalidQuery, Vertex: vertex, Edge: edge, Detail: detail}
+}
+
+// blocks are by the name of the vertex of target they give
+// target may only have function edges and attributes, and the paths in the terms may only follow function edges of source
+// the equations of target are not checked here but by ValidateDB on every result of Evaluate
+// the error is a ValidationErrors naming each block, variable and term that does not fit the schemas
+func NewQuery(source cgs.SchemaGraph, target cgs.SchemaGraph, blocks map[string]QueryBlock) (Query, error) {
+	toReturn := Query{source: source.Copy(), target: target.Copy(), blocks: make(map[string]QueryBlock, len(blocks))}
+	problems := make(ValidationErrors, 0)
+	for _, edge := range target.GetPartialFunctionEdges() {
+		problems = append(problems, ValidationError{Kind: UnsupportedEdge, Edge: edge.GetIdentifier(), Detail: "a query can not give partial function edges"})
+	}
+	for _, edge := range target.GetRelationEdges() {
+		problems = append(problems, ValidationError{Kind: UnsupportedEdge, Edge: edge.GetIdentifier(), Detail: "a query can not give relation edges"})
+	}
+	for _, v := range target.GetVertices() {
+		block, present := blocks[v.GetIdentifier()]
+		if !present {
+			problems = append(problems, invalidQuery(v.GetIdentifier(), "", "no block gives this vertex"))
+			continue
+		}
+		toReturn.blocks[v.GetIdentifier()] = copyBlock(block)
+	}
+	for name := range blocks {
+		if !vertexNamed(&target, name) {
+			problems = append(problems, invalidQuery(name, "", "the block is not for a vertex of the target"))
+		}
+	}
+	if len(problems) > 0 {
+		return Query{}, problems
+	}
+	for _, v := range target.GetVertices() {
+		problems = append(problems, toReturn.blockProblems(v)...)
+	}
+	if len(problems) > 0 {
+		return Query{}, problems
+	}
+	return toReturn, nil
+}
+
+func vertexNamed(schema *cgs.SchemaGraph, name string) bool {
+	for _, v := range schema.GetVertices() {
+		if v.GetIdentifier() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func copyTerm(term Term) Term {
+	term.Path = append([]string{}, term.Path...)
+	return term
+}
+
+func copyBlock(block QueryBlock) QueryBlock {
+	toReturn := QueryBlock{From: append([]Generator{}, block.From...), Where: make([]PathEquality, len(block.Where)),
+		Attributes: make(map[string]Term, len(block.Attributes)), ForeignKeys: make(map[string](map[string]Term), len(block.ForeignKeys))}
+	for i, equality := range block.Where {
+		toReturn.Where[i] = PathEquality{LHS: copyTerm(equality.LHS), RHS: copyTerm(equality.RHS)}
+	}
+	for name, term := range block.Attributes {
+		toReturn.Attributes[name] = copyTerm(term)
+	}
+	for name, terms := range block.ForeignKeys {
+		toReturn.ForeignKeys[name] = make(map[string]Term, len(terms))
+		for variable, term := range terms {
+			toReturn.ForeignKeys[name][variable] = copyTerm(term)
+		}
+	}
+	return toReturn
+}
+
+// the vertex each variable ranges over
+func (block QueryBlock) variables() map[string]cgs.Vertex {
+	toReturn := make(map[string]cgs.Vertex, len(block.From))
+	for _, generator := range block.From {
+		toReturn[generator.Variable] = cgs.NewVertex(generator.Vertex)
+	}
+	return toReturn
+}
+
+// second return describes what is wrong with the term when it is not empty
+func (query *Query) termType(variables map[string]cgs.Vertex, term Term) (termType, string) {
+	if term.IsConstant {
+		return termType{isConstant: true, kind: term.Constant.GetKind()}, ""
+	}
+	current, bound := variables[term.Variable]
+	if !bound {
+		return termType{}, "uses " + term.Variable + " which is not a variable of the block"
+	}
+	for i, name := range term.Path {
+		if edge, found := query.source.GetFunctionEdgeByName(name); found && edge.GetSource() == current {
+			current = edge.GetTarget()
+			continue
+		}
+		if attribute, found := query.source.GetAttributeEdgeByName(name); found && attribute.GetSource() == current && i == len(term.Path)-1 {
+			return termType{isValue: true, sort: attribute.GetSort()}, ""
+		}
+		return termType{}, fmt.Sprintf("%s can not be followed from %s in %s", name, current.GetIdentifier(), term)
+	}
+	return termType{vertex: current}, ""
+}
+
+func (query *Query) blockProblems(vertex cgs.Vertex) ValidationErrors {
+	name := vertex.GetIdentifier()
+	block := query.blocks[name]
+	toReturn := make(ValidationErrors, 0)
+	variables := make(map[string]cgs.Vertex, len(block.From))
+	for _, generator := range block.From {
+		if _, repeated := variables[generator.Variable]; repeated || generator.Variable == "" {
+			toReturn = append(toReturn, invalidQuery(name, "", "the variable "+generator.Variable+" is bound more than once or has no name"))
+		}
+		if !vertexNamed(&query.source, generator.Vertex) {
+			toReturn = append(toReturn, invalidQuery(name, "", "the variable "+generator.Variable+" ranges over "+generator.Vertex+" which is not a vertex of the source"))
+		}
+		variables[generator.Variable] = cgs.NewVertex(generator.Vertex)
+	}
+	if len(toReturn) > 0 {
+		return toReturn
+	}
+	for _, equality := range block.Where {
+		lhs, lhsProblem := query.termType(variables, equality.LHS)
+		rhs, rhsProblem := query.termType(variables, equality.RHS)
+		switch {
+		case lhsProblem != "" || rhsProblem != "":
+			toReturn = append(toReturn, invalidQuery(name, "", strings.TrimSpace("where "+lhsProblem+" "+rhsProblem)))
+		case !lhs.matches(rhs):
+			toReturn = append(toReturn, invalidQuery(name, "", fmt.Sprintf("where compares %s which is %s with %s which is %s", equality.LHS, lhs, equality.RHS, rhs)))
+		}
+	}
+	for _, attribute := range query.target.GetAttributeEdges() {
+		if attribute.GetSource() != vertex {
+			continue
+		}
+		term, present := block.Attributes[attribute.GetIdentifier()]
+		if !present {
+			toReturn = append(toReturn, invalidQuery(name, attribute.GetIdentifier(), "no term gives this attribute"))
+			continue
+		}
+		given, problem := query.termType(variables, term)
+		if problem != "" {
+			toReturn = append(toReturn, invalidQuery(name, attribute.GetIdentifier(), problem))
+		} else if !given.matches(termType{isValue: true, sort: attribute.GetSort()}) {
+			toReturn = append(toReturn, invalidQuery(name, attribute.GetIdentifier(), fmt.Sprintf("%s is %s not a %s", term, given, attribute.GetSort())))
+		}
+	}
+	for attributeName := range block.Attributes {
+		if attribute, found := query.target.GetAttributeEdgeByName(attributeName); !found || attribute.GetSource() != vertex {
+			toReturn = append(toReturn, invalidQuery(name, attributeName, "not an attribute of the target on this vertex"))
+		}
+	}
+	for _, edge := range query.target.GetFunctionEdges() {
+		if edge.GetSource() != vertex {
+			continue
+		}
+		terms, present := block.ForeignKeys[edge.GetIdentifier()]
+		if !present {
+			toReturn = append(toReturn, invalidQuery(name, edge.GetIdentifier(), "no terms give this foreign key"))
+			continue
+		}
+		toReturn = append(toReturn, query.foreignKeyProblems(variables, edge, terms)...)
+	}
+	for edgeName := range block.ForeignKeys {
+		if edge, found := query.target.GetFunctionEdgeByName(edgeName); !found || edge.GetSource() != vertex {
+			toReturn = append(toReturn, invalidQuery(name, edgeName, "not a function edge of the target out of this vertex"))
+		}
+	}
+	return toReturn
+}
+
+// every variable of the block at the target of edge needs a term giving an element of the vertex it ranges over
+func (query *Query) foreignKeyProblems(variables map[string]cgs.Vertex, edge cgs.FunctionEdge, terms map[string]Term) ValidationErrors {
+	toReturn := make(ValidationErrors, 0)
+	name := edge.GetSource().GetIdentifier()
+	targetVariables := query.blocks[edge.GetTarget().GetIdentifier()].variables()
+	for _, generator := range query.blocks[edge.GetTarget().GetIdentifier()].From {
+		term, present := terms[generator.Variable]
+		if !present {
+			toReturn = append(toReturn, invalidQuery(name, edge.GetIdentifier(), "no term for "+generator.Variable))
+			continue
+		}
+		given, problem := query.termType(variables, term)
+		wanted := termType{vertex: targetVariables[generator.Variable]}
+		if problem != "" {
+			toReturn = append(toReturn, invalidQuery(name, edge.GetIdentifier(), problem))
+		} else if given.isConstant || !given.matches(wanted) {
+			toReturn = append(toReturn, invalidQuery(name, edge.GetIdentifier(), fmt.Sprintf("%s is %s not %s", term, given, wanted)))
+		}
+	}
+	for variable := range terms {
+		if _, bound := targetVariables[variable]; !bound {
+			toReturn = append(toReturn, invalidQuery(name, edge.GetIdentifier(), variable+" is not a variable of the block at "+edge.GetTarget().GetIdentifier()))
+		}
+	}
+	return toReturn
+}
+
+func (query Query) GetSource() cgs.SchemaGraph {
+	return query.source.Copy()
+}
+
+func (query Query) GetTarget() cgs.SchemaGraph {
+	return query.target.Copy()
+}
+
+// the block giving the vertex of the target called vertex
+func (query Query) Block(vertex string) (QueryBlock, bool) {
+	toReturn, present := query.blocks[vertex]
+	if !present {
+		return QueryBlock{}, false
+	}
+	return copyBlock(toReturn), true
+}
+
+// the value of term when each variable has the value at its position in row
+func (currentDB *InstantiatedDB) evaluateTerm(positions map[string]int, row []homs.Element, term Term) homs.Element {
+	if term.IsConstant {
+		return term.Constant
+	}
+	toReturn := row[positions[term.Variable]]
+	for _, name := range term.Path {
+		if edge, found := currentDB.underlyingGraph.GetFunctionEdgeByName(name); found {
+			toReturn = currentDB.underlyingFunctions[edge].Evaluate(toReturn)
+		} else if attribute, found := currentDB.underlyingGraph.GetAttributeEdgeByName(name); found {
+			toReturn = currentDB.underlyingAttributes[attribute].Evaluate(toReturn)
+		}
+	}
+	return toReturn
+}
+
+func positionsOf(block QueryBlock) map[string]int {
+	toReturn := make(map[string]int, len(block.From))
+	for i, generator := range block.From {
+		toReturn[generator.Variable] = i
+	}
+	return toReturn
+}
+
+// the element of the target a row of a block stands for
+// the value itself for a single variable, otherwise the tuple of the values in the order of From
+func rowElement(row []homs.Element) homs.Element {
+	if len(row) == 1 {
+		return row[0]
+	}
+	return homs.NewTupleElement(row...)
+}
+
+// every choice of values for the variables of block satisfying its where equalities
+// an equality is checked as soon as all of its variables have values
+func (currentDB *InstantiatedDB) rows(block QueryBlock) [][]homs.Element {
+	positions := positionsOf(block)
+	checkAfter := make([][]PathEquality, len(block.From)+1)
+	for _, equality := range block.Where {
+		last := 0
+		for _, term := range []Term{equality.LHS, equality.RHS} {
+			if !term.IsConstant && positions[term.Variable]+1 > last {
+				last = positions[term.Variable] + 1
+			}
+		}
+		checkAfter[last] = append(checkAfter[last], equality)
+	}
+	toReturn := make([][]homs.Element, 0)
+	var extend func(row []homs.Element)
+	extend = func(row []homs.Element) {
+		for _, equality := range checkAfter[len(row)] {
+			if currentDB.evaluateTerm(positions, row, equality.LHS) != currentDB.evaluateTerm(positions, row, equality.RHS) {
+				return
+			}
+		}
+		if len(row) == len(block.From) {
+			toReturn = append(toReturn, row)
+			return
+		}
+		for _, x := range currentDB.underlyingSets[cgs.NewVertex(block.From[len(row)].Vertex)] {
+			extend(append(append(make([]homs.Element, 0, len(block.From)), row...), x))
+		}
+	}
+	extend(make([]homs.Element, 0, len(block.From)))
+	return toReturn
+}
+
+// the instance of the target of query computed from sourceDB, which must be an instance of exactly its source
+// the error is a ValidationErrors, from the schemas not matching or from ValidateDB on the result,
+// for example when a foreign key gives a choice of values that fails the where equalities of its target block
+func (query Query) Evaluate(sourceDB InstantiatedDB) (InstantiatedDB, error) {
+	if !cgs.SameSchema(query.source, sourceDB.underlyingGraph) {
+		return InstantiatedDB{}, ValidationErrors{{Kind: InvalidSchema, Detail: "the database is not an instance of the source of the query"}}
+	}
+	rows := make(map[cgs.Vertex]([][]homs.Element))
+	sets := make(map[cgs.Vertex]([]homs.Element))
+	for _, v := range query.target.GetVertices() {
+		rows[v] = sourceDB.rows(query.blocks[v.GetIdentifier()])
+		sets[v] = make([]homs.Element, 0, len(rows[v]))
+		for _, row := range rows[v] {
+			sets[v] = append(sets[v], rowElement(row))
+		}
+	}
+	functions := make(map[cgs.FunctionEdge](homs.FunctionTable))
+	for _, edge := range query.target.GetFunctionEdges() {
+		block := query.blocks[edge.GetSource().GetIdentifier()]
+		positions := positionsOf(block)
+		terms := block.ForeignKeys[edge.GetIdentifier()]
+		targetFrom := query.blocks[edge.GetTarget().GetIdentifier()].From
+		values := make(map[homs.Element]homs.Element, len(rows[edge.GetSource()]))
+		for _, row := range rows[edge.GetSource()] {
+			image := make([]homs.Element, len(targetFrom))
+			for i, generator := range targetFrom {
+				image[i] = sourceDB.evaluateTerm(positions, row, terms[generator.Variable])
+			}
+			values[rowElement(row)] = rowElement(image)
+		}
+		functions[edge] = homs.NewFunctionTable(values)
+	}
+	attributes := make(map[cgs.AttributeEdge](homs.FunctionTable))
+	for _, attribute := range query.target.GetAttributeEdges() {
+		block := query.blocks[attribute.GetSource().GetIdentifier()]
+		positions := positionsOf(block)
+		values := make(map[homs.Element]homs.Element, len(rows[attribute.GetSource()]))
+		for _, row := range rows[attribute.GetSource()] {
+			values[rowElement(row)] = sourceDB.evaluateTerm(positions, row, block.Attributes[attribute.GetIdentifier()])
+		}
+		attributes[attribute] = homs.NewFunctionTable(values)
+	}
+	toReturn := assembleDBFromTables(query.target.Copy(), sets, functions, nil, nil, attributes)
+	return toReturn, ValidateDB(toReturn).asError()
+}
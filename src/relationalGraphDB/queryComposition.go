@@ -0,0 +1,174 @@
+package relationalGraphDB
+
+import (
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+)
+
+// the terms over the variables of a composite block that stand for the variables of one block of the first query
+type rowTerms map[string]Term
+
+// the variable of the composite standing for variable of the first query inside variable of the second
+// joined by _ so that it is still a bare name in the query language
+func composedVariable(outer string, inner string) string {
+	return outer + "_" + inner
+}
+
+// term with each of its variables replaced by the term row gives it
+func substitute(term Term, row rowTerms) Term {
+	if term.IsConstant {
+		return term
+	}
+	base := row[term.Variable]
+	return VariableTerm(base.Variable, append(append([]string{}, base.Path...), term.Path...)...)
+}
+
+func (query *Query) substituteAll(equalities []PathEquality, row rowTerms) []PathEquality {
+	toReturn := make([]PathEquality, len(equalities))
+	for i, equality := range equalities {
+		toReturn[i] = PathEquality{LHS: substitute(equality.LHS, row), RHS: substitute(equality.RHS, row)}
+	}
+	return toReturn
+}
+
+// a term over the target of query, evaluated on row, an element of vertex
+// gives either the row of the element it ends at along with its vertex, or when it ends in an attribute, the term for the value
+func (query *Query) follow(row rowTerms, vertex cgs.Vertex, path []string) (rowTerms, cgs.Vertex, Term, bool) {
+	for _, name := range path {
+		block := query.blocks[vertex.GetIdentifier()]
+		edge, isEdge := query.target.GetFunctionEdgeByName(name)
+		if !isEdge {
+			return nil, vertex, substitute(block.Attributes[name], row), true
+		}
+		next := make(rowTerms, len(query.blocks[edge.GetTarget().GetIdentifier()].From))
+		for variable, term := range block.ForeignKeys[name] {
+			next[variable] = substitute(term, row)
+		}
+		row, vertex = next, edge.GetTarget()
+	}
+	return row, vertex, Term{}, false
+}
+
+// first this query then next, without computing the instance in between
+// a variable x of the block of this query at the vertex a variable y of next ranges over becomes the variable y_x
+// so evaluating the composite gives the same instance as evaluating one after the other, with each tuple of tuples flattened
+// the error is a ValidationErrors, InvalidQuery when the target of this query is not the source of next
+// or when two variables of a block would get the same name, otherwise what NewQuery reports about the composite
+func (currentQuery Query) Compose(next Query) (Query, error) {
+	if !cgs.SameSchema(currentQuery.target, next.source) {
+		return Query{}, ValidationErrors{invalidQuery("", "", "the target of the first query is not the source of the second")}
+	}
+	problems := make(ValidationErrors, 0)
+	blocks := make(map[string]QueryBlock, len(next.blocks))
+	for name, outer := range next.blocks {
+		composite := QueryBlock{From: make([]Generator, 0), Where: make([]PathEquality, 0),
+			Attributes: make(map[string]Term, len(outer.Attributes)), ForeignKeys: make(map[string](map[string]Term), len(outer.ForeignKeys))}
+		rows := make(map[string]rowTerms, len(outer.From))
+		taken := make(map[string]bool)
+		for _, generator := range outer.From {
+			inner := currentQuery.blocks[generator.Vertex]
+			rows[generator.Variable] = make(rowTerms, len(inner.From))
+			for _, innerGenerator := range inner.From {
+				variable := composedVariable(generator.Variable, innerGenerator.Variable)
+				if taken[variable] {
+					problems = append(problems, invalidQuery(name, "", "two variables of the composite would both be called "+variable))
+				}
+				taken[variable] = true
+				composite.From = append(composite.From, Generator{Variable: variable, Vertex: innerGenerator.Vertex})
+				rows[generator.Variable][innerGenerator.Variable] = VariableTerm(variable)
+			}
+			composite.Where = append(composite.Where, currentQuery.substituteAll(inner.Where, rows[generator.Variable])...)
+		}
+		variables := outer.variables()
+		translate := func(term Term) (rowTerms, cgs.Vertex, Term, bool) {
+			if term.IsConstant {
+				return nil, cgs.Vertex{}, term, true
+			}
+			return currentQuery.follow(rows[term.Variable], variables[term.Variable], term.Path)
+		}
+		for _, equality := range outer.Where {
+			lhsRow, vertex, lhs, isValue := translate(equality.LHS)
+			rhsRow, _, rhs, _ := translate(equality.RHS)
+			if isValue {
+				composite.Where = append(composite.Where, PathEquality{LHS: lhs, RHS: rhs})
+				continue
+			}
+			// two elements of the middle are equal when all the variables of their rows are
+			for _, generator := range currentQuery.blocks[vertex.GetIdentifier()].From {
+				composite.Where = append(composite.Where, PathEquality{LHS: lhsRow[generator.Variable], RHS: rhsRow[generator.Variable]})
+			}
+		}
+		for attribute, term := range outer.Attributes {
+			_, _, composite.Attributes[attribute], _ = translate(term)
+		}
+		for edge, terms := range outer.ForeignKeys {
+			composite.ForeignKeys[edge] = make(map[string]Term)
+			for variable, term := range terms {
+				row, _, _, _ := translate(term)
+				for innerVariable, innerTerm := range row {
+					composite.ForeignKeys[edge][composedVariable(variable, innerVariable)] = innerTerm
+				}
+			}
+		}
+		blocks[name] = composite
+	}
+	if len(problems) > 0 {
+		return Query{}, problems
+	}
+	return NewQuery(currentQuery.source, next.target, blocks)
+}
+
+// the query from the target of mapping to its source that computes Delta along it
+// each vertex has a single variable ranging over its image, so elements keep their names as they do with Delta
+// the source of mapping may only have function edges and attributes, as a query can not give other edges
+func MappingToQuery(mapping cgs.Mapping) (Query, error) {
+	source := mapping.GetSource()
+	blocks := make(map[string]QueryBlock)
+	for _, v := range source.GetVertices() {
+		image, _ := mapping.VertexImage(v)
+		blocks[v.GetIdentifier()] = QueryBlock{From: []Generator{{Variable: "x", Vertex: image.GetIdentifier()}},
+			Attributes: make(map[string]Term), ForeignKeys: make(map[string](map[string]Term))}
+	}
+	for _, edge := range source.GetFunctionEdges() {
+		blocks[edge.GetSource().GetIdentifier()].ForeignKeys[edge.GetIdentifier()] = map[string]Term{
+			"x": VariableTerm("x", mapping.PathImage([]string{edge.GetIdentifier()})...)}
+	}
+	for _, attribute := range source.GetAttributeEdges() {
+		blocks[attribute.GetSource().GetIdentifier()].Attributes[attribute.GetIdentifier()] = VariableTerm("x",
+			mapping.PathImage([]string{attribute.GetIdentifier()})...)
+	}
+	return NewQuery(mapping.GetTarget(), source, blocks)
+}
+
+// the mapping from the target of query to its source that query computes Delta along, when there is one
+// that is when every block has one variable and no where equalities, and no constants are returned
+// the error is a ValidationErrors naming the blocks that are not of that shape, or the SchemaProblems of cgs.NewMapping
+// when the equations of the target of query do not follow from those of its source
+func QueryToMapping(query Query) (cgs.Mapping, error) {
+	problems := make(ValidationErrors, 0)
+	vertices := make(map[string]string)
+	edges := make(map[string][]string)
+	for _, v := range query.target.GetVertices() {
+		block := query.blocks[v.GetIdentifier()]
+		if len(block.From) != 1 || len(block.Where) > 0 {
+			problems = append(problems, invalidQuery(v.GetIdentifier(), "", "a mapping needs exactly one variable and no where equalities"))
+			continue
+		}
+		vertices[v.GetIdentifier()] = block.From[0].Vertex
+		for edge, terms := range block.ForeignKeys {
+			for _, term := range terms {
+				edges[edge] = term.Path
+			}
+		}
+		for attribute, term := range block.Attributes {
+			if term.IsConstant {
+				problems = append(problems, invalidQuery(v.GetIdentifier(), attribute, "a mapping can not send an attribute to a constant"))
+				continue
+			}
+			edges[attribute] = term.Path
+		}
+	}
+	if len(problems) > 0 {
+		return cgs.Mapping{}, problems
+	}
+	return cgs.NewMapping(query.target, query.source, vertices, edges)
+}
@@ -0,0 +1,200 @@
+package relationalGraphDB
+
+import (
+	"testing"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// pairs of people working in the same department, each pair pointing at its first member
+func colleaguesSchema(t *testing.T) cgs.SchemaGraph {
+	t.Helper()
+	schema := cgs.EmptySchemaGraph()
+	mustSucceed(t, schema.AddVertex2("Pair"), "add Pair")
+	mustSucceed(t, schema.AddVertex2("Member"), "add Member")
+	mustSucceed(t, schema.AddFunctionEdge2("Pair", "Member", "first"), "add first")
+	mustSucceed(t, schema.AddAttributeEdge2("Pair", cgs.StringSort, "firstName"), "add firstName")
+	mustSucceed(t, schema.AddAttributeEdge2("Member", cgs.StringSort, "name"), "add name")
+	mustSucceed(t, schema.AddAttributeEquation2([]string{"first"}, "name", []string{}, "firstName", "first name"), "add first name")
+	return schema
+}
+
+func colleaguesBlocks(memberWhere []PathEquality) map[string]QueryBlock {
+	return map[string]QueryBlock{
+		"Pair": {From: []Generator{{Variable: "p", Vertex: "Person"}, {Variable: "q", Vertex: "Person"}},
+			Where:       []PathEquality{{LHS: VariableTerm("p", "worksIn"), RHS: VariableTerm("q", "worksIn")}},
+			Attributes:  map[string]Term{"firstName": VariableTerm("p", "name")},
+			ForeignKeys: map[string](map[string]Term){"first": {"m": VariableTerm("p")}}},
+		"Member": {From: []Generator{{Variable: "m", Vertex: "Person"}}, Where: memberWhere,
+			Attributes: map[string]Term{"name": VariableTerm("m", "name")}},
+	}
+}
+
+func TestQueryEvaluate(t *testing.T) {
+	query, err := NewQuery(companySchema(t), colleaguesSchema(t), colleaguesBlocks(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	colleagues, err := query.Evaluate(companyDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairs, _ := colleagues.GetUnderlyingSet(cgs.NewVertex("Pair"))
+	if len(pairs) != 5 {
+		t.Errorf("pairs %v, want 5 of them", pairs)
+	}
+	alBo := homs.NewTupleElement(homs.NewStringElement("al"), homs.NewStringElement("bo"))
+	first, _ := colleagues.GetFunction(functionEdge(t, colleaguesSchema(t), "first"))
+	if got := first.Evaluate(alBo); got != homs.NewStringElement("al") {
+		t.Errorf("first of %v is %v, want al", alBo, got)
+	}
+}
+
+// bo is not a member once members are restricted to Al, so the pair of bo with bo has nowhere to point
+func TestQueryForeignKeyOutsideTarget(t *testing.T) {
+	query, err := NewQuery(companySchema(t), colleaguesSchema(t),
+		colleaguesBlocks([]PathEquality{{LHS: VariableTerm("m", "name"), RHS: ConstantTerm(homs.NewStringElement("Al"))}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = query.Evaluate(companyDB(t))
+	problems, isValidation := err.(ValidationErrors)
+	if !isValidation || len(problems) == 0 || problems[0].Kind != ValueOutsideTarget {
+		t.Errorf("got %v, want a value outside the target", err)
+	}
+}
+
+func TestQueryProblems(t *testing.T) {
+	blocks := colleaguesBlocks(nil)
+	pair := blocks["Pair"]
+	pair.Where = []PathEquality{{LHS: VariableTerm("p", "worksIn"), RHS: VariableTerm("q")}}
+	pair.Attributes = map[string]Term{"firstName": VariableTerm("x", "name")}
+	blocks["Pair"] = pair
+	_, err := NewQuery(companySchema(t), colleaguesSchema(t), blocks)
+	problems, isValidation := err.(ValidationErrors)
+	if !isValidation || len(problems) != 2 || problems[0].Kind != InvalidQuery {
+		t.Errorf("got %v, want the where and the attribute reported", err)
+	}
+}
+
+func TestMappingToQuery(t *testing.T) {
+	query, err := MappingToQuery(staffMapping(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	evaluated, err := query.Evaluate(companyDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pulled, err := Delta(staffMapping(t), companyDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := Diff(pulled, evaluated); !diff.IsEmpty() {
+		t.Errorf("query and Delta differ: %+v", diff)
+	}
+	mapping, err := QueryToMapping(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mapping.PathImage([]string{"boss"}); len(got) != 2 || got[0] != "worksIn" || got[1] != "head" {
+		t.Errorf("boss goes to %v, want worksIn head", got)
+	}
+}
+
+func TestQueryToMappingNotPossible(t *testing.T) {
+	query, err := NewQuery(companySchema(t), colleaguesSchema(t), colleaguesBlocks(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = QueryToMapping(query)
+	problems, isValidation := err.(ValidationErrors)
+	if !isValidation || len(problems) != 1 || problems[0].Vertex != "Pair" {
+		t.Errorf("got %v, want Pair reported", err)
+	}
+}
+
+// pairs of pairs sharing their first member, composed with the colleagues query and compared with evaluating one then the other
+func TestQueryCompose(t *testing.T) {
+	first, err := NewQuery(companySchema(t), colleaguesSchema(t), colleaguesBlocks(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := cgs.EmptySchemaGraph()
+	mustSucceed(t, schema.AddVertex2("Quad"), "add Quad")
+	mustSucceed(t, schema.AddVertex2("Lead"), "add Lead")
+	mustSucceed(t, schema.AddFunctionEdge2("Quad", "Lead", "lead"), "add lead")
+	mustSucceed(t, schema.AddAttributeEdge2("Lead", cgs.StringSort, "label"), "add label")
+	second, err := NewQuery(colleaguesSchema(t), schema, map[string]QueryBlock{
+		"Quad": {From: []Generator{{Variable: "a", Vertex: "Pair"}, {Variable: "b", Vertex: "Pair"}},
+			Where:       []PathEquality{{LHS: VariableTerm("a", "first"), RHS: VariableTerm("b", "first")}},
+			ForeignKeys: map[string](map[string]Term){"lead": {"l": VariableTerm("a", "first")}}},
+		"Lead": {From: []Generator{{Variable: "l", Vertex: "Member"}},
+			Attributes: map[string]Term{"label": VariableTerm("l", "name")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	composite, err := first.Compose(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	direct, err := composite.Evaluate(companyDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	colleagues, err := first.Evaluate(companyDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stepwise, err := second.Evaluate(colleagues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quads, _ := direct.GetUnderlyingSet(cgs.NewVertex("Quad")); len(quads) != 9 {
+		t.Errorf("quads %v, want 9 of them", quads)
+	}
+	for _, vertex := range []string{"Quad", "Lead"} {
+		directSet, _ := direct.GetUnderlyingSet(cgs.NewVertex(vertex))
+		stepwiseSet, _ := stepwise.GetUnderlyingSet(cgs.NewVertex(vertex))
+		if len(directSet) != len(stepwiseSet) {
+			t.Errorf("%s has %d elements composed and %d one after the other", vertex, len(directSet), len(stepwiseSet))
+		}
+	}
+	if _, err := second.Compose(first); err == nil {
+		t.Error("composed queries whose schemas do not meet")
+	}
+}
+
+// c inside a_b and b_c inside a would both be a_b_c
+func TestQueryComposeNameClash(t *testing.T) {
+	points := cgs.EmptySchemaGraph()
+	mustSucceed(t, points.AddVertex2("P"), "add P")
+	pairs := cgs.EmptySchemaGraph()
+	mustSucceed(t, pairs.AddVertex2("Q"), "add Q")
+	first, err := NewQuery(points, pairs, map[string]QueryBlock{"Q": {From: []Generator{{Variable: "c", Vertex: "P"}, {Variable: "b_c", Vertex: "P"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewQuery(pairs, pairs, map[string]QueryBlock{"Q": {From: []Generator{{Variable: "a_b", Vertex: "Q"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	composite, err := first.Compose(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block, _ := composite.Block("Q"); len(block.From) != 2 || block.From[0].Variable != "a_b_c" || block.From[1].Variable != "a_b_b_c" {
+		t.Errorf("variables %v, want a_b_c and a_b_b_c", block.From)
+	}
+	clashing, err := NewQuery(pairs, pairs, map[string]QueryBlock{"Q": {From: []Generator{{Variable: "a", Vertex: "Q"}, {Variable: "a_b", Vertex: "Q"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = first.Compose(clashing)
+	clash := expectKind(t, err, InvalidQuery)
+	if clash.Vertex != "Q" {
+		t.Errorf("clash reported at %q, want Q", clash.Vertex)
+	}
+}
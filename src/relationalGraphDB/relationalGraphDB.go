@@ -6,172 +6,334 @@ import homs "RelationalGraphDB/src/morphismTypes"
 
 type InstantiatedDB struct {
 	underlyingGraph            cgs.SchemaGraph
-	underlyingSets             map[cgs.Vertex]([]int)
-	underlyingFunctions        map[cgs.FunctionEdge](homs.myFunction)
-	underlyingPartialFunctions map[cgs.PartialFunctionEdge](homs.myPartialFunction)
-	underlyingRelations        map[cgs.RelationEdge](homs.myRelation)
+	underlyingSets             map[cgs.Vertex]([]homs.Element)
+	underlyingFunctions        map[cgs.FunctionEdge](homs.MyFunction)
+	underlyingPartialFunctions map[cgs.PartialFunctionEdge](homs.MyPartialFunction)
+	underlyingRelations        map[cgs.RelationEdge](homs.MyRelation)
+	underlyingAttributes       map[cgs.AttributeEdge](homs.MyFunction)
+	// set on the working copy of a Transaction, the mutations then only check that names make sense
+	// and leave the data to be validated once at Commit
+	deferValidation bool
+	// set on that working copy once the Transaction is committed or rolled back, every mutation then fails
+	finished bool
+	// how many mutations have been tried, a Transaction uses it to see that its target was left alone
+	changes int
 }
 
-func validateDB(potentialDB InstantiatedDB) bool {
-	result := true
-	result = cgs.validateGraph(potentialDB.underlyingGraph)
-	if !result {
-		fmt.Printf("The schema was bad")
-		return False
+// a database over the empty schema, grow it with AddVertex and the edge adders
+func EmptyInstantiatedDB() InstantiatedDB {
+	return InstantiatedDB{underlyingGraph: cgs.EmptySchemaGraph(),
+		underlyingSets:             make(map[cgs.Vertex]([]homs.Element)),
+		underlyingFunctions:        make(map[cgs.FunctionEdge](homs.MyFunction)),
+		underlyingPartialFunctions: make(map[cgs.PartialFunctionEdge](homs.MyPartialFunction)),
+		underlyingRelations:        make(map[cgs.RelationEdge](homs.MyRelation)),
+		underlyingAttributes:       make(map[cgs.AttributeEdge](homs.MyFunction))}
+}
+
+// build a database all at once from a schema and the data for every vertex and edge
+// the error is a ValidationErrors with everything ValidateDB found wrong
+func NewInstantiatedDB(schema cgs.SchemaGraph, sets map[cgs.Vertex]([]homs.Element), functions map[cgs.FunctionEdge](homs.MyFunction),
+	partialFunctions map[cgs.PartialFunctionEdge](homs.MyPartialFunction), relations map[cgs.RelationEdge](homs.MyRelation),
+	attributes map[cgs.AttributeEdge](homs.MyFunction)) (InstantiatedDB, error) {
+	toReturn := assembleDB(schema, sets, functions, partialFunctions, relations, attributes)
+	return toReturn, ValidateDB(toReturn).asError()
+}
+
+// NewInstantiatedDB without the validation
+func assembleDB(schema cgs.SchemaGraph, sets map[cgs.Vertex]([]homs.Element), functions map[cgs.FunctionEdge](homs.MyFunction),
+	partialFunctions map[cgs.PartialFunctionEdge](homs.MyPartialFunction), relations map[cgs.RelationEdge](homs.MyRelation),
+	attributes map[cgs.AttributeEdge](homs.MyFunction)) InstantiatedDB {
+	toReturn := EmptyInstantiatedDB()
+	toReturn.underlyingGraph = schema
+	for k, v := range sets {
+		toReturn.underlyingSets[k] = v
 	}
-	result = validateFunctions(potentialDB)
-	if !result {
-		fmt.Printf("The functions were bad")
-		return False
+	for k, v := range functions {
+		toReturn.underlyingFunctions[k] = v
 	}
-	result = validatePartialFunctions(potentialDB)
-	if !result {
-		fmt.Printf("The partial functions were bad")
-		return False
+	for k, v := range partialFunctions {
+		toReturn.underlyingPartialFunctions[k] = v
 	}
-	result = validateRelations(potentialDB)
-	if !result {
-		fmt.Printf("The relations were bad")
-		return False
+	for k, v := range relations {
+		toReturn.underlyingRelations[k] = v
 	}
-	result = validateFunctionEquations(potentialDB)
-	if !result {
-		fmt.Printf("The function equations were bad")
-		return False
+	for k, v := range attributes {
+		toReturn.underlyingAttributes[k] = v
 	}
-	result = validatePartialFunctionEquations(potentialDB)
-	if !result {
-		fmt.Printf("The partial function equations were bad")
-		return False
+	return toReturn
+}
+
+// a database sharing nothing mutable with this one
+// the morphisms themselves are never changed in place so they can be shared
+func (currentDB *InstantiatedDB) Clone() InstantiatedDB {
+	toReturn := EmptyInstantiatedDB()
+	toReturn.underlyingGraph = currentDB.underlyingGraph.Copy()
+	for k, v := range currentDB.underlyingSets {
+		toReturn.underlyingSets[k] = append([]homs.Element{}, v...)
 	}
-	result = validateRelationEquations(potentialDB)
-	if !result {
-		fmt.Printf("The relation equations were bad")
-		return False
+	for k, v := range currentDB.underlyingFunctions {
+		toReturn.underlyingFunctions[k] = v
+	}
+	for k, v := range currentDB.underlyingPartialFunctions {
+		toReturn.underlyingPartialFunctions[k] = v
+	}
+	for k, v := range currentDB.underlyingRelations {
+		toReturn.underlyingRelations[k] = v
+	}
+	for k, v := range currentDB.underlyingAttributes {
+		toReturn.underlyingAttributes[k] = v
+	}
+	toReturn.deferValidation = currentDB.deferValidation
+	return toReturn
+}
+
+// puts the contents of result in place of those of this database
+// whether it defers validation, is finished and how many changes it has seen stay as they were
+func (currentDB *InstantiatedDB) replaceWith(result InstantiatedDB) {
+	result.deferValidation, result.finished, result.changes = currentDB.deferValidation, currentDB.finished, currentDB.changes
+	*currentDB = result
+}
+
+func (currentDB *InstantiatedDB) GetSchema() cgs.SchemaGraph {
+	return currentDB.underlyingGraph
+}
+
+func (currentDB *InstantiatedDB) GetUnderlyingSet(vertex cgs.Vertex) ([]homs.Element, bool) {
+	toReturn, present := currentDB.underlyingSets[vertex]
+	return toReturn, present
+}
+
+func (currentDB *InstantiatedDB) GetFunction(edge cgs.FunctionEdge) (homs.MyFunction, bool) {
+	toReturn, present := currentDB.underlyingFunctions[edge]
+	return toReturn, present
+}
+
+func (currentDB *InstantiatedDB) GetPartialFunction(edge cgs.PartialFunctionEdge) (homs.MyPartialFunction, bool) {
+	toReturn, present := currentDB.underlyingPartialFunctions[edge]
+	return toReturn, present
+}
+
+func (currentDB *InstantiatedDB) GetRelation(edge cgs.RelationEdge) (homs.MyRelation, bool) {
+	toReturn, present := currentDB.underlyingRelations[edge]
+	return toReturn, present
+}
+
+// every violation in potentialDB, empty when it is a valid instance of its schema
+// when the schema itself is bad only those problems are reported
+// because the data can not be meaningfully checked against it
+func ValidateDB(potentialDB InstantiatedDB) ValidationErrors {
+	toReturn := validateSchema(potentialDB)
+	if len(toReturn) > 0 {
+		return toReturn
 	}
-	return result
+	toReturn = append(toReturn, validateFunctions(potentialDB)...)
+	toReturn = append(toReturn, validatePartialFunctions(potentialDB)...)
+	toReturn = append(toReturn, validateRelations(potentialDB)...)
+	toReturn = append(toReturn, validateAttributes(potentialDB)...)
+	toReturn = append(toReturn, validateFunctionEquations(potentialDB)...)
+	toReturn = append(toReturn, validatePartialFunctionEquations(potentialDB)...)
+	toReturn = append(toReturn, validateRelationEquations(potentialDB)...)
+	toReturn = append(toReturn, validateAttributeEquations(potentialDB)...)
+	return toReturn
+}
+
+func validateSchema(potentialDB InstantiatedDB) ValidationErrors {
+	toReturn := make(ValidationErrors, 0)
+	for _, problem := range cgs.FindSchemaProblems(potentialDB.underlyingGraph) {
+		toReturn = append(toReturn, ValidationError{Kind: InvalidSchema, Vertex: problem.Vertex, Edge: problem.Edge,
+			Equation: problem.Equation, Detail: problem.Reason})
+	}
+	return toReturn
+}
+
+func missingMorphism(edge cgs.UnspecifiedEdge) ValidationError {
+	return ValidationError{Kind: MissingMorphism, Edge: edge.GetIdentifier(), Detail: "no data given for this edge"}
+}
+
+func outOfTargetErrors(edge cgs.UnspecifiedEdge, violations []homs.OutOfTarget) ValidationErrors {
+	toReturn := make(ValidationErrors, 0, len(violations))
+	for _, violation := range violations {
+		toReturn = append(toReturn, ValidationError{Kind: ValueOutsideTarget, Vertex: edge.GetSource().GetIdentifier(), Edge: edge.GetIdentifier(),
+			Element: violation.Element, HasElement: true,
+			Detail: fmt.Sprintf("goes to %v which is not in %s", violation.Value, edge.GetTarget().GetIdentifier())})
+	}
+	return toReturn
 }
 
 // all the functions should have the property that when inputing a item from the proported source
 // you do get a member of the proported target
-func validateFunctions(potentialDB InstantiatedDB) bool {
-	myFunctionEdges := potentialDB.underlyingGraph.functionEdges
-	myVertices := potentialDB.underlyingGraph.vertices
+func validateFunctions(potentialDB InstantiatedDB) ValidationErrors {
+	myFunctionEdges := potentialDB.underlyingGraph.GetFunctionEdges()
 	myVerticesSets := potentialDB.underlyingSets
 	myFunctions := potentialDB.underlyingFunctions
-	result := true
-	for index, edge := range myFunctionEdges {
-		sourceVertex := edge.source
-		targetVertex := edge.target
+	toReturn := make(ValidationErrors, 0)
+	for _, edge := range myFunctionEdges {
+		sourceVertex := edge.GetSource()
+		targetVertex := edge.GetTarget()
 		sourceSet := myVerticesSets[sourceVertex]
 		targetSet := myVerticesSets[targetVertex]
-		targetSetMap := cgs.presentVertices2(targetSet)
-		currentFunction := myFunctions[edge]
-		for index2, sourceItem := range sourceSet {
-			result = targetSetMap[currentFunction(sourceItem)]
-			if !result {
-				return false
-			}
+		currentFunction, present := myFunctions[edge]
+		if !present {
+			toReturn = append(toReturn, missingMorphism(edge))
+			continue
 		}
+		toReturn = append(toReturn, outOfTargetErrors(edge, homs.FunctionOutOfTarget(sourceSet, targetSet, currentFunction))...)
 	}
-	return result
+	return toReturn
 }
 
 // all the functions should have the property that when inputing a item from the proported source
 // which is well defined according to the partial's domain
 // you do get a member of the proported target
-func validatePartialFunctions(potentialDB InstantiatedDB) bool {
-	myPartialFunctionEdges := potentialDB.underlyingGraph.partialFunctionEdges
-	myVertices := potentialDB.underlyingGraph.vertices
+func validatePartialFunctions(potentialDB InstantiatedDB) ValidationErrors {
+	myPartialFunctionEdges := potentialDB.underlyingGraph.GetPartialFunctionEdges()
 	myVerticesSets := potentialDB.underlyingSets
 	myPartialFunctions := potentialDB.underlyingPartialFunctions
-	result := true
-	for index, edge := range myPartialFunctionEdges {
-		currentPartialFunction := myPartialFunctions[edge]
-		sourceVertex := edge.source
-		targetVertex := edge.target
+	toReturn := make(ValidationErrors, 0)
+	for _, edge := range myPartialFunctionEdges {
+		currentPartialFunction, present := myPartialFunctions[edge]
+		if !present {
+			toReturn = append(toReturn, missingMorphism(edge))
+			continue
+		}
+		sourceVertex := edge.GetSource()
+		targetVertex := edge.GetTarget()
 		sourceSet := myVerticesSets[sourceVertex]
 		targetSet := myVerticesSets[targetVertex]
-		targetSetMap := cgs.presentVertices2(targetSet)
-		for index2, sourceItem := range sourceSet {
-			if currentPartialFunction.myDomain[sourceItem] {
-				result = targetSetMap[currentPartialFunction.myUnderlyingFunction(sourceItem)]
-			}
-			if !result {
-				return false
-			}
-		}
+		toReturn = append(toReturn, outOfTargetErrors(edge, homs.PartialFunctionOutOfTarget(sourceSet, targetSet, currentPartialFunction))...)
 	}
-	return result
+	return toReturn
 }
 
 // all the functions should have the property that when inputing a item from the proported source
 // every t that gets outputed with (s,t1) ... (s,tn) as [t1...tn]
 // all of them should be in the proported target
-func validateRelations(potentialDB InstantiatedDB) bool {
-	myRelationEdges := potentialDB.underlyingGraph.RelationEdges
-	myVertices := potentialDB.underlyingGraph.vertices
+func validateRelations(potentialDB InstantiatedDB) ValidationErrors {
+	myRelationEdges := potentialDB.underlyingGraph.GetRelationEdges()
 	myVerticesSets := potentialDB.underlyingSets
 	myRelations := potentialDB.underlyingRelations
-	result := true
-	for index, edge := range myRelationEdges {
-		currentRelation := myRelations[edge]
-		sourceVertex := edge.source
-		targetVertex := edge.target
+	toReturn := make(ValidationErrors, 0)
+	for _, edge := range myRelationEdges {
+		currentRelation, present := myRelations[edge]
+		if !present {
+			toReturn = append(toReturn, missingMorphism(edge))
+			continue
+		}
+		sourceVertex := edge.GetSource()
+		targetVertex := edge.GetTarget()
 		sourceSet := myVerticesSets[sourceVertex]
 		targetSet := myVerticesSets[targetVertex]
-		targetSetMap := presentVertices2(targetSet)
-		for index2, sourceItem := range sourceSet {
-			allTargets := currentRelation[sourceItem]
-			for _, x := range allTargets {
-				result = result && targetSetMap[x]
-				if !result {
-					return false
-				}
-			}
-		}
+		toReturn = append(toReturn, outOfTargetErrors(edge, homs.RelationOutOfTarget(sourceSet, targetSet, currentRelation))...)
 	}
-	return result
+	return toReturn
 }
 
-// not done yet
-func validateFunctionEquations(potentialDB InstantiatedDB) bool {
-	var myEquations []cgs.FunctionEquation
-	var mySets map[cgs.Vertex]([]int)
-	var myFunctions map[cgs.FunctionEdge](homs.myFunction)
-	myEquations = potentialDB.underlyingGraph.functionEquations
-	mySets := potentialDB.underlyingSets
+// an element of the common source on which the two sides of an equation disagree
+// along with what each side sent it to
+type Counterexample struct {
+	Element  homs.Element
+	LHSValue homs.Element
+	RHSValue homs.Element
+}
+
+type FunctionEquationReport struct {
+	Equation        cgs.FunctionEquation
+	Counterexamples []Counterexample
+}
+
+func (report FunctionEquationReport) Holds() bool {
+	return len(report.Counterexamples) == 0
+}
+
+// the vertex both sides of the equation start at
+// an empty side stands for the identity on the source of the other side
+// second return is false when both sides are empty
+func equationSource(equation cgs.GeneralEquation) (cgs.Vertex, bool) {
+	if len(equation.GetLHS()) > 0 {
+		return equation.GetLHS()[0].GetSource(), true
+	}
+	if len(equation.GetRHS()) > 0 {
+		return equation.GetRHS()[0].GetSource(), true
+	}
+	return cgs.Vertex{}, false
+}
+
+func (potentialDB *InstantiatedDB) functionPath(path []cgs.FunctionEdge) (homs.MyFunction, bool) {
 	myFunctions := potentialDB.underlyingFunctions
-	for _, eq := range myEquations {
-		mylhs := make([]homs.myFunction, len(eq.lhs))
-		domainsLHS := make([][]int, len(eq.lhs))
-		for i := range domainsLHS {
-			domainsLHS[i] = make([]int, 0)
-		}
-		for i, term := range eq.lhs {
-			mylhs[i] = myFunctions[term]
-			domainsLHS[i] = mySets[term.GetSource()]
-		}
-		myrhs := make([]homs.myFunction, len(eq.rhs))
-		domainsRHS := make([][]int, len(eq.rhs))
-		for i := range domainsRHS {
-			domainsRHS[i] = make([]int, 0)
+	mySets := potentialDB.underlyingSets
+	functionList := make([]homs.MyFunction, len(path))
+	domainList := make([][]homs.Element, len(path))
+	for i, term := range path {
+		currentFunction, present := myFunctions[term]
+		if !present {
+			return homs.MyFunction{}, false
 		}
-		for i, term := range eq.rhs {
-			myrhs[i] = myFunctions[term]
-			domainsRHS[i] = mySets[term.GetSource()]
+		functionList[i] = currentFunction
+		domainList[i] = mySets[term.GetSource()]
+	}
+	return homs.ComposeManyFunctions(functionList, domainList)
+}
+
+// evaluate both sides on every element of the source
+// one report per equation, the equation holds when there are no counterexamples
+// equations which mention an edge with no data are skipped, validateFunctions reports those
+func CheckFunctionEquations(potentialDB InstantiatedDB) []FunctionEquationReport {
+	myEquations := potentialDB.underlyingGraph.GetFunctionEquations()
+	toReturn := make([]FunctionEquationReport, 0, len(myEquations))
+	for _, eq := range myEquations {
+		toReturn = append(toReturn, checkFunctionEquation(&potentialDB, eq))
+	}
+	return toReturn
+}
+
+func checkFunctionEquation(potentialDB *InstantiatedDB, eq cgs.FunctionEquation) FunctionEquationReport {
+	source, _ := equationSource(eq)
+	return checkFunctionEquationOn(potentialDB, eq, potentialDB.underlyingSets[source])
+}
+
+// only the given elements of the source are checked
+func checkFunctionEquationOn(potentialDB *InstantiatedDB, eq cgs.FunctionEquation, elements []homs.Element) FunctionEquationReport {
+	report := FunctionEquationReport{Equation: eq, Counterexamples: make([]Counterexample, 0)}
+	if _, nonTrivial := equationSource(eq); !nonTrivial {
+		return report
+	}
+	mylhsCombined, validLHS := potentialDB.functionPath(eq.GetFunctionLHS())
+	myrhsCombined, validRHS := potentialDB.functionPath(eq.GetFunctionRHS())
+	if !validLHS || !validRHS {
+		return report
+	}
+	for _, x := range elements {
+		lhsValue := mylhsCombined.Evaluate(x)
+		rhsValue := myrhsCombined.Evaluate(x)
+		if lhsValue != rhsValue {
+			report.Counterexamples = append(report.Counterexamples, Counterexample{Element: x, LHSValue: lhsValue, RHSValue: rhsValue})
 		}
-		mylhsCombined := homs.composeManyFunctions(mylhs, domainsLHS)
-		myrhsCombined := homs.composeManyFunctions(myrhs, domainsRHS)
-		//check if equal
 	}
-	result := true
-	return result
+	return report
 }
 
-func (potentialDB *InstantiatedDB) allPossiblyPartialFunctions() map[cgs.PossiblyPartialFunctionEdge]homs.possiblyPartialFunction {
+func (report FunctionEquationReport) asValidationErrors() ValidationErrors {
+	toReturn := make(ValidationErrors, 0, len(report.Counterexamples))
+	source, _ := equationSource(report.Equation)
+	for _, counterexample := range report.Counterexamples {
+		toReturn = append(toReturn, ValidationError{Kind: FunctionEquationViolated, Vertex: source.GetIdentifier(),
+			Equation: report.Equation.GetIdentifier(), Element: counterexample.Element, HasElement: true,
+			Detail: fmt.Sprintf("lhs gives %v but rhs gives %v", counterexample.LHSValue, counterexample.RHSValue)})
+	}
+	return toReturn
+}
+
+func validateFunctionEquations(potentialDB InstantiatedDB) ValidationErrors {
+	toReturn := make(ValidationErrors, 0)
+	for _, report := range CheckFunctionEquations(potentialDB) {
+		toReturn = append(toReturn, report.asValidationErrors()...)
+	}
+	return toReturn
+}
+
+func (potentialDB *InstantiatedDB) allPossiblyPartialFunctions() map[cgs.PossiblyPartialFunctionEdge]homs.PossiblyPartialFunction {
 	length := len(potentialDB.underlyingFunctions) + len(potentialDB.underlyingPartialFunctions)
-	toReturn := make(map[cgs.PossiblyPartialFunctionEdge]homs.possiblyPartialFunction, length)
+	toReturn := make(map[cgs.PossiblyPartialFunctionEdge]homs.PossiblyPartialFunction, length)
 	for k, v := range potentialDB.underlyingFunctions {
 		toReturn[k] = v
 	}
@@ -181,48 +343,124 @@ func (potentialDB *InstantiatedDB) allPossiblyPartialFunctions() map[cgs.Possibl
 	return toReturn
 }
 
-// not done yet
-func validatePartialFunctionEquations(potentialDB InstantiatedDB) bool {
-	var myEquations []cgs.PossiblyPartialFunctionEquation
-	var mySets map[cgs.Vertex]([]int)
-	//var myFunctions map[FunctionEdge](myFunction)
-	//var myPartialFunctions map[PartialFunctionEdge](myPartialFunction)
-	var myPossiblyPartialFunctions map[cgs.PossiblyPartialFunctionEdge]homs.possiblyPartialFunction
-	myEquations = potentialDB.underlyingGraph.partialFunctionEquations
-	mySets = potentialDB.underlyingSets
-	//myFunctions = potentialDB.underlyingFunctions
-	//myPartialFunctions = potentialDB.underlyingPartialFunctions
-	myPossiblyPartialFunctions = potentialDB.allPossiblyPartialFunctions()
-	for _, eq := range myEquations {
-		mylhs := make([]homs.possiblyPartialFunction, len(eq.lhs))
-		domainsLHS := make([][]int, len(eq.lhs))
-		for i := range domainsLHS {
-			domainsLHS[i] = make([]int, 0)
-		}
-		for i, term := range eq.lhs {
-			mylhs[i] = myPossiblyPartialFunctions[term]
-			domainsLHS[i] = mySets[term.GetSource()]
+// an element of the common source on which the two sides of a partial function equation
+// fail the equality chosen for that equation
+// the values are only meaningful when the corresponding Defined is true
+type PartialCounterexample struct {
+	Element    homs.Element
+	LHSValue   homs.Element
+	LHSDefined bool
+	RHSValue   homs.Element
+	RHSDefined bool
+}
+
+type PartialFunctionEquationReport struct {
+	Equation        cgs.PossiblyPartialFunctionEquation
+	Counterexamples []PartialCounterexample
+}
+
+func (report PartialFunctionEquationReport) Holds() bool {
+	return len(report.Counterexamples) == 0
+}
+
+// the empty path is the identity on source
+func (potentialDB *InstantiatedDB) partialFunctionPath(path []cgs.PossiblyPartialFunctionEdge, source cgs.Vertex) (homs.MyPartialFunction, bool) {
+	mySets := potentialDB.underlyingSets
+	if len(path) == 0 {
+		return homs.IdentityPartialFunction(mySets[source]), true
+	}
+	myPossiblyPartialFunctions := potentialDB.allPossiblyPartialFunctions()
+	functionList := make([]homs.PossiblyPartialFunction, len(path))
+	domainList := make([][]homs.Element, len(path))
+	for i, term := range path {
+		currentFunction, present := myPossiblyPartialFunctions[term]
+		if !present {
+			return homs.MyPartialFunction{}, false
 		}
-		myrhs := make([]homs.possiblyPartialFunction, len(eq.rhs))
-		domainsRHS := make([][]int, len(eq.rhs))
-		for i := range domainsRHS {
-			domainsRHS[i] = make([]int, 0)
+		functionList[i] = currentFunction
+		domainList[i] = mySets[term.GetSource()]
+	}
+	return homs.ComposeManyPartials(functionList, domainList)
+}
+
+// evaluate both sides on every element of the source
+// and compare them with the equality that equation was imposed with
+// equations which mention an edge with no data are skipped, validateFunctions and validatePartialFunctions report those
+func CheckPartialFunctionEquations(potentialDB InstantiatedDB) []PartialFunctionEquationReport {
+	myEquations := potentialDB.underlyingGraph.GetPartialFunctionEquations()
+	toReturn := make([]PartialFunctionEquationReport, 0, len(myEquations))
+	for _, eq := range myEquations {
+		toReturn = append(toReturn, checkPartialFunctionEquation(&potentialDB, eq))
+	}
+	return toReturn
+}
+
+func checkPartialFunctionEquation(potentialDB *InstantiatedDB, eq cgs.PossiblyPartialFunctionEquation) PartialFunctionEquationReport {
+	source, _ := equationSource(eq)
+	return checkPartialFunctionEquationOn(potentialDB, eq, potentialDB.underlyingSets[source])
+}
+
+func checkPartialFunctionEquationOn(potentialDB *InstantiatedDB, eq cgs.PossiblyPartialFunctionEquation, elements []homs.Element) PartialFunctionEquationReport {
+	report := PartialFunctionEquationReport{Equation: eq, Counterexamples: make([]PartialCounterexample, 0)}
+	source, nonTrivial := equationSource(eq)
+	if !nonTrivial {
+		return report
+	}
+	mylhsCombined, validLHS := potentialDB.partialFunctionPath(eq.GetPartialLHS(), source)
+	myrhsCombined, validRHS := potentialDB.partialFunctionPath(eq.GetPartialRHS(), source)
+	if !validLHS || !validRHS {
+		return report
+	}
+	for _, x := range elements {
+		lhsValue, lhsDefined := mylhsCombined.Evaluate(x)
+		rhsValue, rhsDefined := myrhsCombined.Evaluate(x)
+		var agree bool
+		switch {
+		case lhsDefined && rhsDefined:
+			agree = lhsValue == rhsValue
+		case eq.GetEquality() == cgs.KleeneEquality:
+			agree = true
+		default:
+			agree = lhsDefined == rhsDefined
 		}
-		for i, term := range eq.rhs {
-			myrhs[i] = myPossiblyPartialFunctions[term]
-			domainsRHS[i] = mySets[term.GetSource()]
+		if !agree {
+			report.Counterexamples = append(report.Counterexamples, PartialCounterexample{Element: x,
+				LHSValue: lhsValue, LHSDefined: lhsDefined, RHSValue: rhsValue, RHSDefined: rhsDefined})
 		}
-		mylhsCombined := homs.composeManyPartials(mylhs, domainsLHS)
-		myrhsCombined := homs.composeManyPartials(myrhs, domainsRHS)
-		//check if equal
 	}
-	result := true
-	return result
+	return report
+}
+
+func describePartialValue(value homs.Element, defined bool) string {
+	if !defined {
+		return "undefined"
+	}
+	return value.String()
+}
+
+func (report PartialFunctionEquationReport) asValidationErrors() ValidationErrors {
+	toReturn := make(ValidationErrors, 0, len(report.Counterexamples))
+	source, _ := equationSource(report.Equation)
+	for _, counterexample := range report.Counterexamples {
+		toReturn = append(toReturn, ValidationError{Kind: PartialFunctionEquationViolated, Vertex: source.GetIdentifier(),
+			Equation: report.Equation.GetIdentifier(), Element: counterexample.Element, HasElement: true,
+			Detail: "lhs gives " + describePartialValue(counterexample.LHSValue, counterexample.LHSDefined) +
+				" but rhs gives " + describePartialValue(counterexample.RHSValue, counterexample.RHSDefined)})
+	}
+	return toReturn
+}
+
+func validatePartialFunctionEquations(potentialDB InstantiatedDB) ValidationErrors {
+	toReturn := make(ValidationErrors, 0)
+	for _, report := range CheckPartialFunctionEquations(potentialDB) {
+		toReturn = append(toReturn, report.asValidationErrors()...)
+	}
+	return toReturn
 }
 
-func (potentialDB *InstantiatedDB) allPossiblyRelations() map[cgs.PossiblyRelationEdge]homs.possiblyRelation {
+func (potentialDB *InstantiatedDB) allPossiblyRelations() map[cgs.PossiblyRelationEdge]homs.PossiblyRelation {
 	length := len(potentialDB.underlyingFunctions) + len(potentialDB.underlyingPartialFunctions) + len(potentialDB.underlyingRelations)
-	toReturn := make(map[cgs.PossiblyPartialFunctionEdge]homs.possiblyPartialFunction, length)
+	toReturn := make(map[cgs.PossiblyRelationEdge]homs.PossiblyRelation, length)
 	for k, v := range potentialDB.underlyingFunctions {
 		toReturn[k] = v
 	}
@@ -235,113 +473,210 @@ func (potentialDB *InstantiatedDB) allPossiblyRelations() map[cgs.PossiblyRelati
 	return toReturn
 }
 
-// not done yet
-func validateRelationEquations(potentialDB InstantiatedDB) bool {
-	var myEquations []cgs.PossiblyPartialFunctionEquation
-	var mySets map[cgs.Vertex]([]int)
-	//var myFunctions map[csg.FunctionEdge](homs.myFunction)
-	//var myPartialFunctions map[csg.PartialFunctionEdge](homs.myPartialFunction)
-	//var myRelations map[csg.RelationEdge](homs.myRelation)
-	var myPossiblyRelations map[cgs.PossiblyPartialFunctionEdge]homs.possiblyPartialFunction
-	myEquations = potentialDB.underlyingGraph.partialFunctionEquations
-	mySets = potentialDB.underlyingSets
-	//myFunctions = potentialDB.underlyingFunctions
-	//myPartialFunctions = potentialDB.underlyingPartialFunctions
-	//myRelations = potentialDB.underlyingRelations
-	myPossiblyRelations = potentialDB.allPossiblyRelations()
-	for _, eq := range myEquations {
-		mylhs := make([]homs.possiblyRelation, len(eq.lhs))
-		domainsLHS := make([][]int, len(eq.lhs))
-		for i := range domainsLHS {
-			domainsLHS[i] = make([]int, 0)
-		}
-		for i, term := range eq.lhs {
-			mylhs[i] = myPossiblyRelations[term]
-			domainsLHS[i] = mySets[term.GetSource()]
-		}
-		myrhs := make([]homs.possiblyRelation, len(eq.rhs))
-		domainsRHS := make([][]int, len(eq.rhs))
-		for i := range domainsRHS {
-			domainsRHS[i] = make([]int, 0)
-		}
-		for i, term := range eq.rhs {
-			myrhs[i] = myPossiblyRelations[term]
-			domainsRHS[i] = mySets[term.GetSource()]
+// an element of the common source on which the two sides of a relation equation
+// fail the comparison chosen for that equation
+// along with the part of each image the other side is missing
+type RelationCounterexample struct {
+	Element   homs.Element
+	OnlyInLHS []homs.Element
+	OnlyInRHS []homs.Element
+}
+
+type RelationEquationReport struct {
+	Equation        cgs.PossiblyRelationEquation
+	Counterexamples []RelationCounterexample
+}
+
+func (report RelationEquationReport) Holds() bool {
+	return len(report.Counterexamples) == 0
+}
+
+func (potentialDB *InstantiatedDB) relationPath(path []cgs.PossiblyRelationEdge) (homs.MyRelation, bool) {
+	mySets := potentialDB.underlyingSets
+	myPossiblyRelations := potentialDB.allPossiblyRelations()
+	relationList := make([]homs.PossiblyRelation, len(path))
+	domainList := make([][]homs.Element, len(path))
+	for i, term := range path {
+		currentRelation, present := myPossiblyRelations[term]
+		if !present {
+			return homs.MyRelation{}, false
 		}
-		mylhsCombined := homs.composeManyRelations(mylhs, domainsLHS)
-		myrhsCombined := homs.composeManyRelations(myrhs, domainsRHS)
-		//check if equal
+		relationList[i] = currentRelation
+		domainList[i] = mySets[term.GetSource()]
 	}
-	result := true
-	return result
+	return homs.ComposeManyRelations(relationList, domainList)
 }
 
-// need to give a []int for this new vertex
-//func (currentDB *InstantiatedDB) addVertex(newVertex string,underlyingSet []int){
-//}
-
-// ?????
-//func addFunctionEdge(currentDB *InstantiatedDB,newSource cgs.Vertex,newTarget cgs.Vertex,description string){
-//}
+// compare the images of every element of the source under both sides as sets
+// with the comparison that equation was imposed with
+// equations which mention an edge with no data are skipped
+func CheckRelationEquations(potentialDB InstantiatedDB) []RelationEquationReport {
+	myEquations := potentialDB.underlyingGraph.GetRelationEquations()
+	toReturn := make([]RelationEquationReport, 0, len(myEquations))
+	for _, eq := range myEquations {
+		toReturn = append(toReturn, checkRelationEquation(&potentialDB, eq))
+	}
+	return toReturn
+}
 
-// ??????
-//func addPartialFunctionEdge(currentDB *InstantiatedDB,newSource cgs.Vertex,newTarget cgs.Vertex,description string){
-//}
+func checkRelationEquation(potentialDB *InstantiatedDB, eq cgs.PossiblyRelationEquation) RelationEquationReport {
+	source, _ := equationSource(eq)
+	return checkRelationEquationOn(potentialDB, eq, potentialDB.underlyingSets[source])
+}
 
-// ???????
-//func addRelationEdge(currentDB *InstantiatedDB,newSource cgs.Vertex,newTarget cgs.Vertex,description string){
-//}
+func checkRelationEquationOn(potentialDB *InstantiatedDB, eq cgs.PossiblyRelationEquation, elements []homs.Element) RelationEquationReport {
+	report := RelationEquationReport{Equation: eq, Counterexamples: make([]RelationCounterexample, 0)}
+	if _, nonTrivial := equationSource(eq); !nonTrivial {
+		return report
+	}
+	mylhsCombined, validLHS := potentialDB.relationPath(eq.GetLHS())
+	myrhsCombined, validRHS := potentialDB.relationPath(eq.GetRHS())
+	if !validLHS || !validRHS {
+		return report
+	}
+	for _, x := range elements {
+		onlyLHS, onlyRHS := homs.CompareImages(mylhsCombined.Evaluate(x), myrhsCombined.Evaluate(x))
+		if eq.GetComparison() == cgs.SetInclusion {
+			onlyRHS = make([]homs.Element, 0)
+		}
+		if len(onlyLHS) > 0 || len(onlyRHS) > 0 {
+			report.Counterexamples = append(report.Counterexamples, RelationCounterexample{Element: x, OnlyInLHS: onlyLHS, OnlyInRHS: onlyRHS})
+		}
+	}
+	return report
+}
 
-// ??????????
-//func addFunctionEdgeEquation(currentDB *InstantiatedDB,....){
-//}
+func (report RelationEquationReport) asValidationErrors() ValidationErrors {
+	toReturn := make(ValidationErrors, 0, len(report.Counterexamples))
+	source, _ := equationSource(report.Equation)
+	for _, counterexample := range report.Counterexamples {
+		toReturn = append(toReturn, ValidationError{Kind: RelationEquationViolated, Vertex: source.GetIdentifier(),
+			Equation: report.Equation.GetIdentifier(), Element: counterexample.Element, HasElement: true,
+			Detail: fmt.Sprintf("only lhs relates it to %v and only rhs relates it to %v", counterexample.OnlyInLHS, counterexample.OnlyInRHS)})
+	}
+	return toReturn
+}
 
-// ?????
-//func addPartialFunctionEquation(currentDB *InstantiatedDB,....){
-//}
+func validateRelationEquations(potentialDB InstantiatedDB) ValidationErrors {
+	toReturn := make(ValidationErrors, 0)
+	for _, report := range CheckRelationEquations(potentialDB) {
+		toReturn = append(toReturn, report.asValidationErrors()...)
+	}
+	return toReturn
+}
 
-// ??????
-//func addRelationEquation(currentDB *InstantiatedDB,....){
-//}
+func duplicateVertex(name string) ValidationError {
+	return ValidationError{Kind: DuplicateName, Vertex: name, Detail: "there is already a vertex with this name"}
+}
 
-// ????????
-//func removeFunctionEdgeEquation(currentDB *InstantiatedDB,....){
-//}
+func duplicateEdge(name string) ValidationError {
+	return ValidationError{Kind: DuplicateName, Edge: name, Detail: "there is already an edge with this name"}
+}
 
-// ????????
-//func removePartialFunctionEquation(currentDB *InstantiatedDB,....){
-//}
+// the edge adders of the schema only fail when an endpoint is missing
+func missingEndpoints(currentDB *InstantiatedDB, newSource string, newTarget string, description string) ValidationErrors {
+	toReturn := make(ValidationErrors, 0, 2)
+	for _, endpoint := range []string{newSource, newTarget} {
+		if _, present := currentDB.underlyingSets[cgs.NewVertex(endpoint)]; !present {
+			toReturn = append(toReturn, ValidationError{Kind: InvalidSchema, Vertex: endpoint, Edge: description, Detail: "endpoint is not a vertex of the schema"})
+		}
+	}
+	return toReturn
+}
 
-// ???????
-//func removeRelationEquation(currentDB *InstantiatedDB,....){
-//}
+// adding a disjoint vertex to the schema and the underlyingSet is given
+func (currentDB *InstantiatedDB) AddVertex(newVertex string, underlyingSet []homs.Element) error {
+	if err := currentDB.beginChange(); err != nil {
+		return err
+	}
+	result := currentDB.underlyingGraph.AddVertex2(newVertex)
+	if !result {
+		return ValidationErrors{duplicateVertex(newVertex)}
+	}
+	currentDB.underlyingSets[cgs.NewVertex(newVertex)] = underlyingSet
+	return nil
+}
 
-// ?????
-//func removeFunctionEdge(currentDB *InstantiatedDB,newSource cgs.Vertex,newTarget cgs.Vertex,description string){
-//}
+// adding function edge
+func (currentDB *InstantiatedDB) AddFunctionEdge(newSource string, newTarget string, description string, content homs.MyFunction) error {
+	if err := currentDB.beginChange(); err != nil {
+		return err
+	}
+	if currentDB.underlyingGraph.HasEdgeNamed(description) {
+		return ValidationErrors{duplicateEdge(description)}
+	}
+	mySchema := currentDB.underlyingGraph.Copy()
+	result := mySchema.AddFunctionEdge2(newSource, newTarget, description)
+	if !result {
+		return missingEndpoints(currentDB, newSource, newTarget, description)
+	}
+	thisEdge, _ := mySchema.GetFunctionEdgeByName(description)
+	if !currentDB.deferValidation {
+		sourceSet := currentDB.underlyingSets[cgs.NewVertex(newSource)]
+		targetSet := currentDB.underlyingSets[cgs.NewVertex(newTarget)]
+		problems := outOfTargetErrors(thisEdge, homs.FunctionOutOfTarget(sourceSet, targetSet, content))
+		if len(problems) > 0 {
+			return problems
+		}
+	}
+	currentDB.underlyingGraph = mySchema
+	currentDB.underlyingFunctions[thisEdge] = content
+	return nil
+}
 
 // ??????
-//func removePartialFunctionEdge(currentDB *InstantiatedDB,newSource cgs.Vertex,newTarget cgs.Vertex,description string){
-//}
+func (currentDB *InstantiatedDB) AddPartialFunctionEdge(newSource string, newTarget string, description string, content homs.MyPartialFunction) error {
+	if err := currentDB.beginChange(); err != nil {
+		return err
+	}
+	if currentDB.underlyingGraph.HasEdgeNamed(description) {
+		return ValidationErrors{duplicateEdge(description)}
+	}
+	mySchema := currentDB.underlyingGraph.Copy()
+	result := mySchema.AddPartialFunctionEdge2(newSource, newTarget, description)
+	if !result {
+		return missingEndpoints(currentDB, newSource, newTarget, description)
+	}
+	thisEdge, _ := mySchema.GetDefPartialFunctionEdgeByName(description)
+	if !currentDB.deferValidation {
+		sourceSet := currentDB.underlyingSets[cgs.NewVertex(newSource)]
+		targetSet := currentDB.underlyingSets[cgs.NewVertex(newTarget)]
+		problems := outOfTargetErrors(thisEdge, homs.PartialFunctionOutOfTarget(sourceSet, targetSet, content))
+		if len(problems) > 0 {
+			return problems
+		}
+	}
+	currentDB.underlyingGraph = mySchema
+	currentDB.underlyingPartialFunctions[thisEdge] = content
+	return nil
+}
 
 // ???????
-//func removeRelationEdge(currentDB *InstantiatedDB,newSource cgs.Vertex,newTarget cgs.Vertex,description string){
-//}
-
-// ????
-//func deleteVertex(currentDB *InstantiatedDB,badVertex cgs.Vertex){
-//}
-
-// for all the function edges that go out from modifiedVertex need to supply values on addedItem for those functions
-// for all the partialfunction edges that go out from modifiedVertex either supply value or say it is undefined on this
-// for all the relations edges that go out from this vertex, decide what y (addedItem,y) go into the relation
-//          possibly default to []
-// for all the relations edges that go into this vertex, decide what y (y,addedItem) go into the relation
-//          possibly default so that relation[y] does not add addedItem into the list, so none of (y,addedItem) are in relation
-//          possibly default so that relation[y] does add addedItem into the list, so all of (y,addedItem) are in relation
-//func addElementToSet(currentDB *InstantiatedDB,modifiedVertex Vertex,addedItem int){}
-
-//func deleteElementToSet(currentDB *InstatntiatedDB,modifiedVertex cgs.Vertex,deletedItem int){}
+func (currentDB *InstantiatedDB) AddRelationEdge(newSource string, newTarget string, description string, content homs.MyRelation) error {
+	if err := currentDB.beginChange(); err != nil {
+		return err
+	}
+	if currentDB.underlyingGraph.HasEdgeNamed(description) {
+		return ValidationErrors{duplicateEdge(description)}
+	}
+	mySchema := currentDB.underlyingGraph.Copy()
+	result := mySchema.AddRelationEdge2(newSource, newTarget, description)
+	if !result {
+		return missingEndpoints(currentDB, newSource, newTarget, description)
+	}
+	thisEdge, _ := mySchema.GetDefRelationEdgeByName(description)
+	if !currentDB.deferValidation {
+		sourceSet := currentDB.underlyingSets[cgs.NewVertex(newSource)]
+		targetSet := currentDB.underlyingSets[cgs.NewVertex(newTarget)]
+		problems := outOfTargetErrors(thisEdge, homs.RelationOutOfTarget(sourceSet, targetSet, content))
+		if len(problems) > 0 {
+			return problems
+		}
+	}
+	currentDB.underlyingGraph = mySchema
+	currentDB.underlyingRelations[thisEdge] = content
+	return nil
+}
 
 //func modifyAFunction
 //func modifyAPartialFunction
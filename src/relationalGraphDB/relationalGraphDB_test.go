@@ -0,0 +1,172 @@
+package relationalGraphDB
+
+import (
+	"testing"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// everyone is their manager's manager's report, but only al and cy manage themselves
+func TestCheckFunctionEquations(t *testing.T) {
+	db := officeDB(t)
+	mustSucceed(t, db.underlyingGraph.AddFunctionEquation2([]string{"manager", "manager"}, []string{"manager"}, "one level"), "add one level")
+	mustSucceed(t, db.underlyingGraph.AddFunctionEquation2([]string{"manager"}, []string{}, "self managed"), "add self managed")
+	s := homs.NewStringElement
+	cases := []struct {
+		name            string
+		counterexamples []Counterexample
+	}{
+		{"one level", []Counterexample{}},
+		{"self managed", []Counterexample{{Element: s("bo"), LHSValue: s("al"), RHSValue: s("bo")}}},
+	}
+	reports := CheckFunctionEquations(db)
+	if len(reports) != len(cases) {
+		t.Fatalf("%d reports, want %d", len(reports), len(cases))
+	}
+	for i, c := range cases {
+		report := reports[i]
+		if report.Equation.GetIdentifier() != c.name {
+			t.Errorf("report %d is for %s, want %s", i, report.Equation.GetIdentifier(), c.name)
+		}
+		if report.Holds() != (len(c.counterexamples) == 0) {
+			t.Errorf("%s: holds is %v", c.name, report.Holds())
+		}
+		if len(report.Counterexamples) != len(c.counterexamples) {
+			t.Errorf("%s: counterexamples %v, want %v", c.name, report.Counterexamples, c.counterexamples)
+			continue
+		}
+		for j, counterexample := range report.Counterexamples {
+			if counterexample != c.counterexamples[j] {
+				t.Errorf("%s: counterexample %v, want %v", c.name, counterexample, c.counterexamples[j])
+			}
+		}
+	}
+}
+
+// the mentor of al is not their manager, and neither bo nor cy has a mentor
+// strict equality counts an undefined side as a violation, Kleene equality only compares where both are defined
+func TestCheckPartialFunctionEquationsStrictAndKleene(t *testing.T) {
+	s := homs.NewStringElement
+	cases := []struct {
+		name     string
+		add      func(*cgs.SchemaGraph) bool
+		elements []homs.Element
+	}{
+		{"strict", func(schema *cgs.SchemaGraph) bool {
+			return schema.AddPartialFunctionEquation2([]string{"mentor"}, []string{"manager"}, "strict")
+		}, []homs.Element{s("al"), s("bo"), s("cy")}},
+		{"kleene", func(schema *cgs.SchemaGraph) bool {
+			return schema.AddKleenePartialFunctionEquation2([]string{"mentor"}, []string{"manager"}, "kleene")
+		}, []homs.Element{s("al")}},
+	}
+	for _, c := range cases {
+		db := officeDB(t)
+		mustSucceed(t, c.add(&db.underlyingGraph), "add "+c.name)
+		reports := CheckPartialFunctionEquations(db)
+		if len(reports) != 1 || reports[0].Equation.GetIdentifier() != c.name {
+			t.Fatalf("%s: reports %v", c.name, reports)
+		}
+		counterexamples := reports[0].Counterexamples
+		if len(counterexamples) != len(c.elements) {
+			t.Errorf("%s: counterexamples %v, want them at %v", c.name, counterexamples, c.elements)
+			continue
+		}
+		for i, counterexample := range counterexamples {
+			if counterexample.Element != c.elements[i] {
+				t.Errorf("%s: counterexample at %v, want %v", c.name, counterexample.Element, c.elements[i])
+			}
+		}
+		if both := counterexamples[0]; !both.LHSDefined || !both.RHSDefined || both.LHSValue != s("cy") || both.RHSValue != s("al") {
+			t.Errorf("%s: at al got %+v, want cy against al", c.name, both)
+		}
+		for _, undefined := range counterexamples[1:] {
+			if undefined.LHSDefined || !undefined.RHSDefined {
+				t.Errorf("%s: at %v got %+v, want only the rhs defined", c.name, undefined.Element, undefined)
+			}
+		}
+	}
+}
+
+// al is mentored by cy, whom they know, but also knows bo
+func TestCheckRelationEquationsEqualityAndInclusion(t *testing.T) {
+	s := homs.NewStringElement
+	cases := []struct {
+		name      string
+		add       func(*cgs.SchemaGraph) bool
+		onlyInLHS []homs.Element
+		onlyInRHS []homs.Element
+	}{
+		{"mentors are known", func(schema *cgs.SchemaGraph) bool {
+			return schema.AddRelationInclusion2([]string{"mentor"}, []string{"knows"}, "mentors are known")
+		}, nil, nil},
+		{"only mentors are known", func(schema *cgs.SchemaGraph) bool {
+			return schema.AddRelationEquation2([]string{"mentor"}, []string{"knows"}, "only mentors are known")
+		}, []homs.Element{}, []homs.Element{s("bo")}},
+		{"everyone known mentors", func(schema *cgs.SchemaGraph) bool {
+			return schema.AddRelationInclusion2([]string{"knows"}, []string{"mentor"}, "everyone known mentors")
+		}, []homs.Element{s("bo")}, []homs.Element{}},
+	}
+	for _, c := range cases {
+		db := officeDB(t)
+		mustSucceed(t, c.add(&db.underlyingGraph), "add "+c.name)
+		reports := CheckRelationEquations(db)
+		if len(reports) != 1 || reports[0].Equation.GetIdentifier() != c.name {
+			t.Fatalf("%s: reports %v", c.name, reports)
+		}
+		counterexamples := reports[0].Counterexamples
+		if c.onlyInLHS == nil {
+			if !reports[0].Holds() {
+				t.Errorf("%s: counterexamples %v, want none", c.name, counterexamples)
+			}
+			continue
+		}
+		if len(counterexamples) != 1 || counterexamples[0].Element != s("al") {
+			t.Errorf("%s: counterexamples %v, want one at al", c.name, counterexamples)
+			continue
+		}
+		if !sameElements(counterexamples[0].OnlyInLHS, c.onlyInLHS) || !sameElements(counterexamples[0].OnlyInRHS, c.onlyInRHS) {
+			t.Errorf("%s: only in lhs %v and only in rhs %v, want %v and %v", c.name,
+				counterexamples[0].OnlyInLHS, counterexamples[0].OnlyInRHS, c.onlyInLHS, c.onlyInRHS)
+		}
+	}
+}
+
+func sameElements(got []homs.Element, want []homs.Element) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// mentor has no data, al knows someone who is not an employee and bo is not their own manager
+// none of these hides the others
+func TestValidateDBCollectsEveryViolation(t *testing.T) {
+	db := officeDB(t)
+	s := homs.NewStringElement
+	mentor, _ := db.underlyingGraph.GetDefPartialFunctionEdgeByName("mentor")
+	delete(db.underlyingPartialFunctions, mentor)
+	knows, _ := db.underlyingGraph.GetDefRelationEdgeByName("knows")
+	db.underlyingRelations[knows] = homs.NewRelationTable(map[homs.Element]([]homs.Element){s("al"): {s("bo"), s("di")}}).AsRelation()
+	mustSucceed(t, db.underlyingGraph.AddFunctionEquation2([]string{"manager"}, []string{}, "self managed"), "add self managed")
+	problems := ValidateDB(db)
+	want := []ValidationError{
+		{Kind: MissingMorphism, Edge: "mentor"},
+		{Kind: ValueOutsideTarget, Edge: "knows", Element: s("al"), HasElement: true},
+		{Kind: FunctionEquationViolated, Equation: "self managed", Element: s("bo"), HasElement: true},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("%d violations %v, want %d", len(problems), problems, len(want))
+	}
+	for i, problem := range problems {
+		if problem.Kind != want[i].Kind || problem.Edge != want[i].Edge || problem.Equation != want[i].Equation ||
+			problem.Element != want[i].Element || problem.HasElement != want[i].HasElement {
+			t.Errorf("violation %d is %+v, want %+v", i, problem, want[i])
+		}
+	}
+}
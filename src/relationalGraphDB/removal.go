@@ -0,0 +1,199 @@
+package relationalGraphDB
+
+import (
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// the names of everything a removal took out of the schema, in schema order
+// Elements has the set of each vertex that was removed
+type RemovalReport struct {
+	Vertices  []string
+	Edges     []string
+	Equations []string
+	Elements  map[string]([]homs.Element)
+}
+
+func vertexNames(schema *cgs.SchemaGraph) []string {
+	toReturn := make([]string, 0)
+	for _, v := range schema.GetVertices() {
+		toReturn = append(toReturn, v.GetIdentifier())
+	}
+	return toReturn
+}
+
+// every edge of the schema by name, whatever its kind
+func allEdgeNames(schema *cgs.SchemaGraph) []string {
+	toReturn := make([]string, 0)
+	for _, edge := range schema.GetFunctionEdges() {
+		toReturn = append(toReturn, edge.GetIdentifier())
+	}
+	for _, edge := range schema.GetPartialFunctionEdges() {
+		toReturn = append(toReturn, edge.GetIdentifier())
+	}
+	for _, edge := range schema.GetRelationEdges() {
+		toReturn = append(toReturn, edge.GetIdentifier())
+	}
+	for _, edge := range schema.GetAttributeEdges() {
+		toReturn = append(toReturn, edge.GetIdentifier())
+	}
+	return toReturn
+}
+
+// every equation of the schema by name, whatever its kind
+func allEquationNames(schema *cgs.SchemaGraph) []string {
+	toReturn := make([]string, 0)
+	for _, eq := range schema.GetFunctionEquations() {
+		toReturn = append(toReturn, eq.GetIdentifier())
+	}
+	for _, eq := range schema.GetPartialFunctionEquations() {
+		toReturn = append(toReturn, eq.GetIdentifier())
+	}
+	for _, eq := range schema.GetRelationEquations() {
+		toReturn = append(toReturn, eq.GetIdentifier())
+	}
+	for _, eq := range schema.GetAttributeEquations() {
+		toReturn = append(toReturn, eq.GetIdentifier())
+	}
+	return toReturn
+}
+
+// the names in before that are not in after
+func namesRemoved(before []string, after []string) []string {
+	kept := make(map[string]bool, len(after))
+	for _, name := range after {
+		kept[name] = true
+	}
+	toReturn := make([]string, 0)
+	for _, name := range before {
+		if !kept[name] {
+			toReturn = append(toReturn, name)
+		}
+	}
+	return toReturn
+}
+
+// applies change to a copy of the schema, then drops the sets and morphisms
+// of whatever is no longer in it and says what went
+// taking things out of a schema only relaxes it, so valid data stays valid
+func (currentDB *InstantiatedDB) removeFromSchema(change func(*cgs.SchemaGraph)) RemovalReport {
+	mySchema := currentDB.underlyingGraph.Copy()
+	change(&mySchema)
+	toReturn := RemovalReport{Vertices: namesRemoved(vertexNames(&currentDB.underlyingGraph), vertexNames(&mySchema)),
+		Edges:     namesRemoved(allEdgeNames(&currentDB.underlyingGraph), allEdgeNames(&mySchema)),
+		Equations: namesRemoved(allEquationNames(&currentDB.underlyingGraph), allEquationNames(&mySchema)),
+		Elements:  make(map[string]([]homs.Element))}
+	for _, name := range toReturn.Vertices {
+		thisVertex := cgs.NewVertex(name)
+		toReturn.Elements[name] = currentDB.underlyingSets[thisVertex]
+		delete(currentDB.underlyingSets, thisVertex)
+	}
+	for edge := range currentDB.underlyingFunctions {
+		if _, found := mySchema.GetFunctionEdgeByName(edge.GetIdentifier()); !found {
+			delete(currentDB.underlyingFunctions, edge)
+		}
+	}
+	for edge := range currentDB.underlyingPartialFunctions {
+		if _, found := mySchema.GetDefPartialFunctionEdgeByName(edge.GetIdentifier()); !found {
+			delete(currentDB.underlyingPartialFunctions, edge)
+		}
+	}
+	for edge := range currentDB.underlyingRelations {
+		if _, found := mySchema.GetDefRelationEdgeByName(edge.GetIdentifier()); !found {
+			delete(currentDB.underlyingRelations, edge)
+		}
+	}
+	for edge := range currentDB.underlyingAttributes {
+		if _, found := mySchema.GetAttributeEdgeByName(edge.GetIdentifier()); !found {
+			delete(currentDB.underlyingAttributes, edge)
+		}
+	}
+	currentDB.underlyingGraph = mySchema
+	return toReturn
+}
+
+func unknownEdge(description string, detail string) ValidationErrors {
+	return ValidationErrors{unknownName("", description, detail)}
+}
+
+// removes the edge, every equation that uses it and its data
+func (currentDB *InstantiatedDB) RemoveFunctionEdge(description string) (RemovalReport, error) {
+	if err := currentDB.beginChange(); err != nil {
+		return RemovalReport{}, err
+	}
+	if _, found := currentDB.underlyingGraph.GetFunctionEdgeByName(description); !found {
+		return RemovalReport{}, unknownEdge(description, "not a function edge of the schema")
+	}
+	return currentDB.removeFromSchema(func(schema *cgs.SchemaGraph) { schema.RemoveFunctionEdge(description) }), nil
+}
+
+func (currentDB *InstantiatedDB) RemovePartialFunctionEdge(description string) (RemovalReport, error) {
+	if err := currentDB.beginChange(); err != nil {
+		return RemovalReport{}, err
+	}
+	if _, found := currentDB.underlyingGraph.GetDefPartialFunctionEdgeByName(description); !found {
+		return RemovalReport{}, unknownEdge(description, "not a partial function edge of the schema")
+	}
+	return currentDB.removeFromSchema(func(schema *cgs.SchemaGraph) { schema.RemovePartialFunctionEdge(description) }), nil
+}
+
+func (currentDB *InstantiatedDB) RemoveRelationEdge(description string) (RemovalReport, error) {
+	if err := currentDB.beginChange(); err != nil {
+		return RemovalReport{}, err
+	}
+	if _, found := currentDB.underlyingGraph.GetDefRelationEdgeByName(description); !found {
+		return RemovalReport{}, unknownEdge(description, "not a relation edge of the schema")
+	}
+	return currentDB.removeFromSchema(func(schema *cgs.SchemaGraph) { schema.RemoveRelationEdge(description) }), nil
+}
+
+func (currentDB *InstantiatedDB) RemoveAttributeEdge(description string) (RemovalReport, error) {
+	if err := currentDB.beginChange(); err != nil {
+		return RemovalReport{}, err
+	}
+	if _, found := currentDB.underlyingGraph.GetAttributeEdgeByName(description); !found {
+		return RemovalReport{}, unknownEdge(description, "not an attribute of the schema")
+	}
+	return currentDB.removeFromSchema(func(schema *cgs.SchemaGraph) { schema.RemoveAttributeEdge(description) }), nil
+}
+
+// whichever of the four removals fits the kind of the edge called description
+// this is one change however many edges and equations go with it
+func (currentDB *InstantiatedDB) RemoveEdge(description string) (RemovalReport, error) {
+	if err := currentDB.beginChange(); err != nil {
+		return RemovalReport{}, err
+	}
+	var remove func(*cgs.SchemaGraph)
+	if _, found := currentDB.underlyingGraph.GetFunctionEdgeByName(description); found {
+		remove = func(schema *cgs.SchemaGraph) { schema.RemoveFunctionEdge(description) }
+	} else if _, found := currentDB.underlyingGraph.GetDefPartialFunctionEdgeByName(description); found {
+		remove = func(schema *cgs.SchemaGraph) { schema.RemovePartialFunctionEdge(description) }
+	} else if _, found := currentDB.underlyingGraph.GetDefRelationEdgeByName(description); found {
+		remove = func(schema *cgs.SchemaGraph) { schema.RemoveRelationEdge(description) }
+	} else if _, found := currentDB.underlyingGraph.GetAttributeEdgeByName(description); found {
+		remove = func(schema *cgs.SchemaGraph) { schema.RemoveAttributeEdge(description) }
+	} else {
+		return RemovalReport{}, unknownEdge(description, "no such edge in the schema")
+	}
+	return currentDB.removeFromSchema(remove), nil
+}
+
+// removes the vertex with its set, every edge into or out of it and everything those take with them
+func (currentDB *InstantiatedDB) DeleteVertex(badVertex string) (RemovalReport, error) {
+	if err := currentDB.beginChange(); err != nil {
+		return RemovalReport{}, err
+	}
+	if !currentDB.hasVertex(badVertex) {
+		return RemovalReport{}, ValidationErrors{unknownName(badVertex, "", "not a vertex of the schema")}
+	}
+	return currentDB.removeFromSchema(func(schema *cgs.SchemaGraph) { schema.DeleteVertex(badVertex) }), nil
+}
+
+func (currentDB *InstantiatedDB) hasVertex(name string) bool {
+	for _, v := range currentDB.underlyingGraph.GetVertices() {
+		if v.GetIdentifier() == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package relationalGraphDB
+
+import "testing"
+
+func TestRemoveEdge(t *testing.T) {
+	db := companyDB(t)
+	before := db.changes
+	report, err := db.RemoveEdge("worksIn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.changes != before+1 {
+		t.Errorf("removing one edge counted as %d changes", db.changes-before)
+	}
+	if len(report.Edges) != 1 || len(report.Equations) != 1 || report.Equations[0] != "heads work there" {
+		t.Errorf("removed edges %v and equations %v, want worksIn and heads work there", report.Edges, report.Equations)
+	}
+	schema := db.GetSchema()
+	if schema.HasEdgeNamed("worksIn") {
+		t.Error("worksIn is still in the schema")
+	}
+}
+
+func TestRemoveEdgeUnknown(t *testing.T) {
+	db := companyDB(t)
+	_, err := db.RemoveEdge("salary")
+	violation := expectKind(t, err, UnknownName)
+	if violation.Edge != "salary" || violation.Detail != "no such edge in the schema" {
+		t.Errorf("got %v, want salary reported as no such edge", violation)
+	}
+}
@@ -0,0 +1,172 @@
+package relationalGraphDB
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// a database that many goroutines can read and write at once
+// every committed change makes a new version and a version is never changed once it is published
+// so a reader holding a Snapshot sees one consistent version however many writers commit meanwhile
+// writers take turns, each one works on a copy of the latest version inside a Transaction
+// every version is kept, so older ones can still be read by number or by when they were committed
+type SharedDB struct {
+	writer  sync.Mutex
+	current atomic.Pointer[Snapshot]
+	// history[n] is version n
+	historyLock sync.RWMutex
+	history     []*Snapshot
+	clock       func() time.Time
+}
+
+// one published version of a SharedDB, only the reading methods of InstantiatedDB are offered
+// and everything they hand back is a copy, so nothing done with it can change the version
+type Snapshot struct {
+	version     int
+	committedAt time.Time
+	db          InstantiatedDB
+}
+
+// shares a copy of startingDB, changes to startingDB afterwards are not seen
+func NewSharedDB(startingDB InstantiatedDB) *SharedDB {
+	toReturn := &SharedDB{clock: time.Now}
+	first := startingDB.Clone()
+	first.deferValidation = false
+	toReturn.publish(&Snapshot{committedAt: toReturn.clock(), db: first})
+	return toReturn
+}
+
+// the latest version, it never blocks on writers
+func (shared *SharedDB) Snapshot() *Snapshot {
+	return shared.current.Load()
+}
+
+// runs change inside a transaction on the latest version and publishes the result if it commits
+// the error is whatever change returned, in which case nothing is published, or else what Commit returned
+// writers are serialized so change always starts from the version the previous writer published
+func (shared *SharedDB) Update(change func(tx *Transaction) error) error {
+	shared.writer.Lock()
+	defer shared.writer.Unlock()
+	latest := shared.current.Load()
+	var next InstantiatedDB
+	tx := latest.db.Begin()
+	tx.target, tx.seen = &next, next.changes
+	if err := change(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	// a wall clock can step backwards, AsOf needs the times in order
+	committedAt := shared.clock()
+	if committedAt.Before(latest.committedAt) {
+		committedAt = latest.committedAt
+	}
+	shared.publish(&Snapshot{version: latest.version + 1, committedAt: committedAt, db: next})
+	return nil
+}
+
+// the history is extended before current moves on, so any version a reader can see is already in it
+func (shared *SharedDB) publish(snapshot *Snapshot) {
+	shared.historyLock.Lock()
+	shared.history = append(shared.history, snapshot)
+	shared.historyLock.Unlock()
+	shared.current.Store(snapshot)
+}
+
+// second return is false if there is no such version yet
+func (shared *SharedDB) Version(version int) (*Snapshot, bool) {
+	shared.historyLock.RLock()
+	defer shared.historyLock.RUnlock()
+	if version < 0 || version >= len(shared.history) {
+		return nil, false
+	}
+	return shared.history[version], true
+}
+
+// the version that was the latest at moment, second return is false if moment is before the first version
+func (shared *SharedDB) AsOf(moment time.Time) (*Snapshot, bool) {
+	shared.historyLock.RLock()
+	defer shared.historyLock.RUnlock()
+	// the first version committed after moment, versions are committed in order
+	after := sort.Search(len(shared.history), func(i int) bool { return shared.history[i].committedAt.After(moment) })
+	if after == 0 {
+		return nil, false
+	}
+	return shared.history[after-1], true
+}
+
+// starts at 0 and goes up by one with every Update that commits
+func (snapshot *Snapshot) Version() int {
+	return snapshot.version
+}
+
+func (snapshot *Snapshot) CommittedAt() time.Time {
+	return snapshot.committedAt
+}
+
+// what changed going from this version to later, see Diff
+func (snapshot *Snapshot) DiffTo(later *Snapshot) DBDiff {
+	return Diff(snapshot.db, later.db)
+}
+
+// a database of its own to change as wanted
+func (snapshot *Snapshot) Clone() InstantiatedDB {
+	return snapshot.db.Clone()
+}
+
+func (snapshot *Snapshot) GetSchema() cgs.SchemaGraph {
+	return snapshot.db.underlyingGraph.Copy()
+}
+
+func (snapshot *Snapshot) GetUnderlyingSet(vertex cgs.Vertex) ([]homs.Element, bool) {
+	toReturn, present := snapshot.db.GetUnderlyingSet(vertex)
+	return append([]homs.Element{}, toReturn...), present
+}
+
+func (snapshot *Snapshot) GetFunction(edge cgs.FunctionEdge) (homs.MyFunction, bool) {
+	return snapshot.db.GetFunction(edge)
+}
+
+func (snapshot *Snapshot) GetPartialFunction(edge cgs.PartialFunctionEdge) (homs.MyPartialFunction, bool) {
+	return snapshot.db.GetPartialFunction(edge)
+}
+
+func (snapshot *Snapshot) GetRelation(edge cgs.RelationEdge) (homs.MyRelation, bool) {
+	return snapshot.db.GetRelation(edge)
+}
+
+func (snapshot *Snapshot) GetAttribute(edge cgs.AttributeEdge) (homs.MyFunction, bool) {
+	return snapshot.db.GetAttribute(edge)
+}
+
+func (snapshot *Snapshot) GetFunctionTable(edge cgs.FunctionEdge) (homs.FunctionTable, bool) {
+	return snapshot.db.GetFunctionTable(edge)
+}
+
+func (snapshot *Snapshot) GetPartialFunctionTable(edge cgs.PartialFunctionEdge) (homs.PartialFunctionTable, bool) {
+	return snapshot.db.GetPartialFunctionTable(edge)
+}
+
+func (snapshot *Snapshot) GetRelationTable(edge cgs.RelationEdge) (homs.RelationTable, bool) {
+	return snapshot.db.GetRelationTable(edge)
+}
+
+func (snapshot *Snapshot) GetAttributeTable(edge cgs.AttributeEdge) (homs.FunctionTable, bool) {
+	return snapshot.db.GetAttributeTable(edge)
+}
+
+// every published version passed validation, this is for checking that again
+func (snapshot *Snapshot) Validate() ValidationErrors {
+	return ValidateDB(snapshot.db)
+}
+
+func (snapshot *Snapshot) MarshalJSON() ([]byte, error) {
+	return snapshot.db.MarshalJSON()
+}
@@ -0,0 +1,232 @@
+package relationalGraphDB
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// run with go test -race
+
+// one boss who is their own manager, everyone added after reports to the boss
+// and the equation manager.manager = manager keeps the hierarchy one level deep
+func sharedTestDB(t *testing.T) InstantiatedDB {
+	t.Helper()
+	boss := homs.NewStringElement("boss")
+	db := EmptyInstantiatedDB()
+	if err := db.AddVertex("Employee", []homs.Element{boss}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AddFunctionEdge("Employee", "Employee", "manager", homs.NewFunction(func(homs.Element) homs.Element { return boss })); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AddFunctionEquation([]string{"manager", "manager"}, []string{"manager"}, "one level"); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func reportsTo(manager string) ElementInsertion {
+	return ElementInsertion{FunctionValues: map[string]homs.Element{"manager": homs.NewStringElement(manager)}}
+}
+
+func employeeCount(t *testing.T, snapshot *Snapshot) int {
+	t.Helper()
+	set, present := snapshot.GetUnderlyingSet(cgs.NewVertex("Employee"))
+	if !present {
+		t.Fatal("Employee missing from snapshot")
+	}
+	return len(set)
+}
+
+func TestSharedDBParallelInsert(t *testing.T) {
+	shared := NewSharedDB(sharedTestDB(t))
+	const writers = 16
+	const perWriter = 10
+	var group sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		group.Add(1)
+		go func(w int) {
+			defer group.Done()
+			for i := 0; i < perWriter; i++ {
+				name := homs.NewStringElement(fmt.Sprintf("e%d-%d", w, i))
+				err := shared.Update(func(tx *Transaction) error {
+					return tx.AddElementToSet("Employee", name, reportsTo("boss"))
+				})
+				if err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	group.Wait()
+	final := shared.Snapshot()
+	if got, want := employeeCount(t, final), 1+writers*perWriter; got != want {
+		t.Errorf("%d employees, want %d", got, want)
+	}
+	if got, want := final.Version(), writers*perWriter; got != want {
+		t.Errorf("version %d, want %d", got, want)
+	}
+	if problems := final.Validate(); len(problems) > 0 {
+		t.Error(problems)
+	}
+}
+
+// readers validate whatever version they hold while writers keep committing
+// a snapshot must stay exactly as it was when it was taken
+func TestSharedDBSnapshotIsolation(t *testing.T) {
+	shared := NewSharedDB(sharedTestDB(t))
+	manager, _ := shared.Snapshot().db.underlyingGraph.GetFunctionEdgeByName("manager")
+	done := make(chan struct{})
+	var writers, readers sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		writers.Add(1)
+		go func(w int) {
+			defer writers.Done()
+			for i := 0; i < 25; i++ {
+				name := homs.NewStringElement(fmt.Sprintf("w%d-%d", w, i))
+				if err := shared.Update(func(tx *Transaction) error {
+					return tx.AddElementToSet("Employee", name, reportsTo("boss"))
+				}); err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	for r := 0; r < 8; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				snapshot := shared.Snapshot()
+				before := employeeCount(t, snapshot)
+				if before != 1+snapshot.Version() {
+					t.Errorf("version %d has %d employees", snapshot.Version(), before)
+				}
+				if problems := snapshot.Validate(); len(problems) > 0 {
+					t.Error(problems)
+				}
+				table, _ := snapshot.GetFunctionTable(manager)
+				if table.Len() != before {
+					t.Errorf("manager defined on %d of %d employees", table.Len(), before)
+				}
+				if after := employeeCount(t, snapshot); after != before {
+					t.Errorf("snapshot changed from %d to %d employees", before, after)
+				}
+			}
+		}()
+	}
+	writers.Wait()
+	close(done)
+	readers.Wait()
+	if got := shared.Snapshot().Version(); got != 100 {
+		t.Errorf("version %d, want 100", got)
+	}
+}
+
+func TestSharedDBRejectedUpdatesPublishNothing(t *testing.T) {
+	shared := NewSharedDB(sharedTestDB(t))
+	first := shared.Snapshot()
+	var group sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		group.Add(1)
+		go func(w int) {
+			defer group.Done()
+			// x reports to y who reports to the boss, so manager.manager is not manager on x
+			err := shared.Update(func(tx *Transaction) error {
+				x, y := fmt.Sprintf("x%d", w), fmt.Sprintf("y%d", w)
+				if err := tx.AddElementToSet("Employee", homs.NewStringElement(y), reportsTo("boss")); err != nil {
+					return err
+				}
+				return tx.AddElementToSet("Employee", homs.NewStringElement(x), reportsTo(y))
+			})
+			var problems ValidationErrors
+			if !errors.As(err, &problems) {
+				t.Errorf("got %v, want the equation to fail", err)
+			}
+			aborted := errors.New("aborted")
+			if err := shared.Update(func(tx *Transaction) error {
+				tx.AddElementToSet("Employee", homs.NewStringElement(fmt.Sprintf("z%d", w)), reportsTo("boss"))
+				return aborted
+			}); err != aborted {
+				t.Errorf("got %v, want the error from change", err)
+			}
+		}(w)
+	}
+	group.Wait()
+	if shared.Snapshot() != first {
+		t.Error("a rejected update published a new version")
+	}
+}
+
+func TestSnapshotHandsOutCopies(t *testing.T) {
+	shared := NewSharedDB(sharedTestDB(t))
+	snapshot := shared.Snapshot()
+	set, _ := snapshot.GetUnderlyingSet(cgs.NewVertex("Employee"))
+	set[0] = homs.NewStringElement("intruder")
+	schema := snapshot.GetSchema()
+	schema.AddVertex2("Intruder")
+	mine := snapshot.Clone()
+	mine.AddVertex("Other", nil)
+	again, _ := snapshot.GetUnderlyingSet(cgs.NewVertex("Employee"))
+	if again[0] != homs.NewStringElement("boss") {
+		t.Errorf("set changed to %v", again)
+	}
+	againSchema := snapshot.GetSchema()
+	if len(againSchema.GetVertices()) != 1 {
+		t.Errorf("schema changed to %v", againSchema.GetVertices())
+	}
+}
+
+func TestSharedDBVersionAndAsOf(t *testing.T) {
+	shared := NewSharedDB(sharedTestDB(t))
+	first := shared.Snapshot()
+	start := first.CommittedAt()
+	// each update is committed an hour after the one before, except the third, when the clock steps back
+	moments := []time.Time{start.Add(time.Hour), start.Add(2 * time.Hour), start.Add(time.Hour / 2)}
+	for i, moment := range moments {
+		shared.clock = func() time.Time { return moment }
+		if err := shared.Update(func(tx *Transaction) error {
+			return tx.AddElementToSet("Employee", homs.NewStringElement(fmt.Sprintf("e%d", i)), reportsTo("boss"))
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for version := 0; version <= 3; version++ {
+		snapshot, present := shared.Version(version)
+		if !present || snapshot.Version() != version || employeeCount(t, snapshot) != version+1 {
+			t.Errorf("version %d is %v", version, snapshot)
+		}
+	}
+	if _, present := shared.Version(4); present {
+		t.Error("version 4 exists before it was committed")
+	}
+	if _, present := shared.Version(-1); present {
+		t.Error("version -1 exists")
+	}
+	if third, _ := shared.Version(3); !third.CommittedAt().Equal(moments[1]) {
+		t.Errorf("version 3 committed at %v, want the time of version 2 as the clock stepped back", third.CommittedAt())
+	}
+	for _, example := range []struct {
+		moment  time.Time
+		version int
+	}{{start, 0}, {start.Add(time.Minute), 0}, {moments[0], 1}, {moments[1].Add(-time.Minute), 1}, {moments[1], 3}, {start.Add(24 * time.Hour), 3}} {
+		snapshot, present := shared.AsOf(example.moment)
+		if !present || snapshot.Version() != example.version {
+			t.Errorf("as of %v got %v, want version %d", example.moment.Sub(start), snapshot, example.version)
+		}
+	}
+	if _, present := shared.AsOf(start.Add(-time.Second)); present {
+		t.Error("there is a version from before the first one")
+	}
+}
@@ -0,0 +1,123 @@
+package relationalGraphDB
+
+import (
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// the data on each edge written out over the set at its source
+// second return is false if the edge has no data
+
+func (currentDB *InstantiatedDB) GetFunctionTable(edge cgs.FunctionEdge) (homs.FunctionTable, bool) {
+	content, present := currentDB.underlyingFunctions[edge]
+	if !present {
+		return homs.FunctionTable{}, false
+	}
+	return homs.TabulateFunction(content, currentDB.underlyingSets[edge.GetSource()]), true
+}
+
+func (currentDB *InstantiatedDB) GetPartialFunctionTable(edge cgs.PartialFunctionEdge) (homs.PartialFunctionTable, bool) {
+	content, present := currentDB.underlyingPartialFunctions[edge]
+	if !present {
+		return homs.PartialFunctionTable{}, false
+	}
+	return homs.TabulatePartialFunction(content, currentDB.underlyingSets[edge.GetSource()]), true
+}
+
+func (currentDB *InstantiatedDB) GetRelationTable(edge cgs.RelationEdge) (homs.RelationTable, bool) {
+	content, present := currentDB.underlyingRelations[edge]
+	if !present {
+		return homs.RelationTable{}, false
+	}
+	return homs.TabulateRelation(content, currentDB.underlyingSets[edge.GetSource()]), true
+}
+
+func (currentDB *InstantiatedDB) GetAttributeTable(edge cgs.AttributeEdge) (homs.FunctionTable, bool) {
+	content, present := currentDB.underlyingAttributes[edge]
+	if !present {
+		return homs.FunctionTable{}, false
+	}
+	return homs.TabulateFunction(content, currentDB.underlyingSets[edge.GetSource()]), true
+}
+
+// replaces every closure with its table over the current sets
+// so later changes to whatever the closures captured no longer affect the database
+func (currentDB *InstantiatedDB) Materialize() {
+	for edge := range currentDB.underlyingFunctions {
+		table, _ := currentDB.GetFunctionTable(edge)
+		currentDB.underlyingFunctions[edge] = table.AsFunction()
+	}
+	for edge := range currentDB.underlyingPartialFunctions {
+		table, _ := currentDB.GetPartialFunctionTable(edge)
+		currentDB.underlyingPartialFunctions[edge] = table.AsPartialFunction()
+	}
+	for edge := range currentDB.underlyingRelations {
+		table, _ := currentDB.GetRelationTable(edge)
+		currentDB.underlyingRelations[edge] = table.AsRelation()
+	}
+	for edge := range currentDB.underlyingAttributes {
+		table, _ := currentDB.GetAttributeTable(edge)
+		currentDB.underlyingAttributes[edge] = table.AsFunction()
+	}
+}
+
+// same as NewInstantiatedDB but with the data given as tables
+func NewInstantiatedDBFromTables(schema cgs.SchemaGraph, sets map[cgs.Vertex]([]homs.Element), functions map[cgs.FunctionEdge](homs.FunctionTable),
+	partialFunctions map[cgs.PartialFunctionEdge](homs.PartialFunctionTable), relations map[cgs.RelationEdge](homs.RelationTable),
+	attributes map[cgs.AttributeEdge](homs.FunctionTable)) (InstantiatedDB, error) {
+	if problems := nonTotalTables(schema, sets, functions, attributes); len(problems) > 0 {
+		return InstantiatedDB{}, problems
+	}
+	toReturn := assembleDBFromTables(schema, sets, functions, partialFunctions, relations, attributes)
+	return toReturn, ValidateDB(toReturn).asError()
+}
+
+// a function or attribute table needs a key for every element of its source,
+// otherwise the closure made from it would quietly send the missing ones to the zero Element
+func nonTotalTables(schema cgs.SchemaGraph, sets map[cgs.Vertex]([]homs.Element), functions map[cgs.FunctionEdge](homs.FunctionTable),
+	attributes map[cgs.AttributeEdge](homs.FunctionTable)) ValidationErrors {
+	toReturn := make(ValidationErrors, 0)
+	missing := func(source cgs.Vertex, edge string, table homs.FunctionTable, present bool) {
+		if !present {
+			return
+		}
+		for _, x := range sets[source] {
+			if _, present := table.Evaluate(x); !present {
+				toReturn = append(toReturn, ValidationError{Kind: MissingValue, Vertex: source.GetIdentifier(), Edge: edge,
+					Element: x, HasElement: true, Detail: "the table has no value for this element"})
+			}
+		}
+	}
+	for _, edge := range schema.GetFunctionEdges() {
+		table, present := functions[edge]
+		missing(edge.GetSource(), edge.GetIdentifier(), table, present)
+	}
+	for _, edge := range schema.GetAttributeEdges() {
+		table, present := attributes[edge]
+		missing(edge.GetSource(), edge.GetIdentifier(), table, present)
+	}
+	return toReturn
+}
+
+// NewInstantiatedDBFromTables without the validation
+func assembleDBFromTables(schema cgs.SchemaGraph, sets map[cgs.Vertex]([]homs.Element), functions map[cgs.FunctionEdge](homs.FunctionTable),
+	partialFunctions map[cgs.PartialFunctionEdge](homs.PartialFunctionTable), relations map[cgs.RelationEdge](homs.RelationTable),
+	attributes map[cgs.AttributeEdge](homs.FunctionTable)) InstantiatedDB {
+	myFunctions := make(map[cgs.FunctionEdge](homs.MyFunction), len(functions))
+	for k, v := range functions {
+		myFunctions[k] = v.AsFunction()
+	}
+	myPartialFunctions := make(map[cgs.PartialFunctionEdge](homs.MyPartialFunction), len(partialFunctions))
+	for k, v := range partialFunctions {
+		myPartialFunctions[k] = v.AsPartialFunction()
+	}
+	myRelations := make(map[cgs.RelationEdge](homs.MyRelation), len(relations))
+	for k, v := range relations {
+		myRelations[k] = v.AsRelation()
+	}
+	myAttributes := make(map[cgs.AttributeEdge](homs.MyFunction), len(attributes))
+	for k, v := range attributes {
+		myAttributes[k] = v.AsFunction()
+	}
+	return assembleDB(schema, sets, myFunctions, myPartialFunctions, myRelations, myAttributes)
+}
@@ -0,0 +1,68 @@
+package relationalGraphDB
+
+import (
+	"encoding/json"
+	"testing"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// a single vertex with an endomorphism, over the ints up to size
+func countingDB(t *testing.T, size int) (cgs.SchemaGraph, InstantiatedDB) {
+	t.Helper()
+	schema := cgs.EmptySchemaGraph()
+	mustSucceed(t, schema.AddVertex2("N"), "add N")
+	mustSucceed(t, schema.AddFunctionEdge2("N", "N", "f"), "add f")
+	values := make(map[homs.Element]homs.Element)
+	elements := make([]int, size)
+	for i := range elements {
+		elements[i] = i
+		values[homs.NewIntElement(i)] = homs.NewIntElement(0)
+	}
+	db, err := NewInstantiatedDBFromTables(schema, map[cgs.Vertex]([]homs.Element){cgs.NewVertex("N"): homs.NewIntElements(elements)},
+		map[cgs.FunctionEdge](homs.FunctionTable){functionEdge(t, schema, "f"): homs.NewFunctionTable(values)}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema, db
+}
+
+func expectMissingValue(t *testing.T, err error, element homs.Element) {
+	t.Helper()
+	problems, isValidation := err.(ValidationErrors)
+	if !isValidation || len(problems) != 1 || problems[0].Kind != MissingValue || problems[0].Element != element {
+		t.Errorf("got %v, want a missing value at %v", err, element)
+	}
+}
+
+// 1 is missing from the table, it must not be read as going to 0 just because 0 is in the set
+func TestFromTablesNotTotal(t *testing.T) {
+	schema, _ := countingDB(t, 1)
+	_, err := NewInstantiatedDBFromTables(schema, map[cgs.Vertex]([]homs.Element){cgs.NewVertex("N"): homs.NewIntElements([]int{0, 1})},
+		map[cgs.FunctionEdge](homs.FunctionTable){functionEdge(t, schema, "f"): homs.NewFunctionTable(map[homs.Element]homs.Element{
+			homs.NewIntElement(0): homs.NewIntElement(0)})}, nil, nil, nil)
+	expectMissingValue(t, err, homs.NewIntElement(1))
+}
+
+func TestUnmarshalNotTotal(t *testing.T) {
+	_, larger := countingDB(t, 3)
+	_, smaller := countingDB(t, 2)
+	var largerJSON, smallerJSON map[string]json.RawMessage
+	for db, into := range map[*InstantiatedDB]*map[string]json.RawMessage{&larger: &largerJSON, &smaller: &smallerJSON} {
+		data, err := json.Marshal(*db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, into); err != nil {
+			t.Fatal(err)
+		}
+	}
+	largerJSON["functions"] = smallerJSON["functions"]
+	data, err := json.Marshal(largerJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var read InstantiatedDB
+	expectMissingValue(t, json.Unmarshal(data, &read), homs.NewIntElement(2))
+}
@@ -0,0 +1,71 @@
+package relationalGraphDB
+
+import "errors"
+
+// a batch of changes to a database that either all happen or none do
+// every mutation of InstantiatedDB can be called on the transaction itself
+// they act on a working copy and only check that the names they are given make sense,
+// so the copy may pass through states that would not validate on the way to one that does
+// Commit validates the copy once and only then replaces the database with it
+// the database must not be changed other than through the transaction until it is finished,
+// Commit fails with ErrTargetChanged if any mutation was tried on it meanwhile, even one that failed
+//
+//	tx := currentDB.Begin()
+//	tx.AddElementToSet("Employee", cy, ...) // works in hr, which is not there yet
+//	tx.AddElementToSet("Department", hr, ...) // with cy as secretary
+//	err := tx.Commit()
+type Transaction struct {
+	InstantiatedDB
+	target *InstantiatedDB
+	// the changes count of target at Begin
+	seen int
+}
+
+var ErrTransactionFinished = errors.New("transaction already committed or rolled back")
+
+var ErrTargetChanged = errors.New("database changed outside the transaction since it began")
+
+// nothing done through the transaction is seen in currentDB until Commit
+func (currentDB *InstantiatedDB) Begin() *Transaction {
+	toReturn := &Transaction{InstantiatedDB: currentDB.Clone(), target: currentDB, seen: currentDB.changes}
+	toReturn.deferValidation = true
+	return toReturn
+}
+
+// every mutation starts here, so the working copy of a finished transaction can not be changed
+// and a transaction on this database can tell it was changed
+func (currentDB *InstantiatedDB) beginChange() error {
+	if currentDB.finished {
+		return ErrTransactionFinished
+	}
+	currentDB.changes = currentDB.changes + 1
+	return nil
+}
+
+// the error is ErrTargetChanged, or a ValidationErrors with everything wrong with the result
+// the database is left as it was whenever there is an error
+// either way the transaction is finished
+func (tx *Transaction) Commit() error {
+	if tx.finished {
+		return ErrTransactionFinished
+	}
+	tx.finished = true
+	if tx.target.changes != tx.seen {
+		return ErrTargetChanged
+	}
+	if problems := ValidateDB(tx.InstantiatedDB); len(problems) > 0 {
+		return problems
+	}
+	tx.target.replaceWith(tx.InstantiatedDB.Clone())
+	tx.target.changes = tx.target.changes + 1
+	return nil
+}
+
+// throws away everything done through the transaction
+func (tx *Transaction) Rollback() error {
+	if tx.finished {
+		return ErrTransactionFinished
+	}
+	tx.finished = true
+	return nil
+}
@@ -0,0 +1,157 @@
+package relationalGraphDB
+
+import (
+	"errors"
+	"testing"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+// di works in hr, which does not exist until it is added with di as its head
+func addHR(t *testing.T, tx *Transaction) {
+	t.Helper()
+	s := homs.NewStringElement
+	if err := tx.AddElementToSet("Person", s("di"), ElementInsertion{FunctionValues: map[string]homs.Element{"worksIn": s("hr")},
+		AttributeValues: map[string]homs.Element{"name": s("Di")}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.AddElementToSet("Department", s("hr"), ElementInsertion{FunctionValues: map[string]homs.Element{"head": s("di")}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTransactionCommit(t *testing.T) {
+	db := companyDB(t)
+	tx := db.Begin()
+	addHR(t, tx)
+	if departments, _ := db.GetUnderlyingSet(cgs.NewVertex("Department")); len(departments) != 2 {
+		t.Errorf("departments %v before Commit, want sales and ops", departments)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	head, _ := db.GetFunction(functionEdge(t, companySchema(t), "head"))
+	if got := head.Evaluate(homs.NewStringElement("hr")); got != homs.NewStringElement("di") {
+		t.Errorf("head of hr is %v, want di", got)
+	}
+	// the database is not left deferring validation
+	if err := db.AddElementToSet("Department", homs.NewStringElement("it"), ElementInsertion{FunctionValues: map[string]homs.Element{
+		"head": homs.NewStringElement("al")}}); err == nil {
+		t.Error("a department headed by someone working elsewhere was added after Commit")
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	db := companyDB(t)
+	tx := db.Begin()
+	addHR(t, tx)
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := Diff(companyDB(t), db); !diff.IsEmpty() {
+		t.Errorf("a rolled back transaction changed the data: %+v", diff)
+	}
+}
+
+// only the first step on its own would leave di working in a department that is not there
+func TestTransactionDeferredValidation(t *testing.T) {
+	db := companyDB(t)
+	tx := db.Begin()
+	s := homs.NewStringElement
+	if err := tx.AddElementToSet("Person", s("di"), ElementInsertion{FunctionValues: map[string]homs.Element{"worksIn": s("hr")},
+		AttributeValues: map[string]homs.Element{"name": s("Di")}}); err != nil {
+		t.Fatal(err)
+	}
+	violation := expectKind(t, tx.Commit(), ValueOutsideTarget)
+	if violation.Edge != "worksIn" || violation.Element != s("di") {
+		t.Errorf("got %v along %s, want di along worksIn", violation.Element, violation.Edge)
+	}
+	if diff := Diff(companyDB(t), db); !diff.IsEmpty() {
+		t.Errorf("a failed Commit changed the data: %+v", diff)
+	}
+}
+
+func TestTransactionFinished(t *testing.T) {
+	db := companyDB(t)
+	committed := db.Begin()
+	if err := committed.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	rolledBack := db.Begin()
+	if err := rolledBack.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tx := range []*Transaction{committed, rolledBack} {
+		if err := tx.AddVertex("Project", nil); err != ErrTransactionFinished {
+			t.Errorf("AddVertex after the end gave %v", err)
+		}
+		if _, err := tx.DeleteElementFromSet("Person", homs.NewStringElement("bo")); err != ErrTransactionFinished {
+			t.Errorf("DeleteElementFromSet after the end gave %v", err)
+		}
+		if err := tx.Commit(); err != ErrTransactionFinished {
+			t.Errorf("Commit after the end gave %v", err)
+		}
+		if err := tx.Rollback(); err != ErrTransactionFinished {
+			t.Errorf("Rollback after the end gave %v", err)
+		}
+	}
+}
+
+// whichever commits second would overwrite what the first did
+func TestTransactionTargetChanged(t *testing.T) {
+	db := companyDB(t)
+	first, second := db.Begin(), db.Begin()
+	addHR(t, first)
+	if err := first.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Commit(); !errors.Is(err, ErrTargetChanged) {
+		t.Errorf("got %v after another transaction committed, want ErrTargetChanged", err)
+	}
+	tx := db.Begin()
+	if _, err := db.DeleteElementFromSet("Person", homs.NewStringElement("bo")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); !errors.Is(err, ErrTargetChanged) {
+		t.Errorf("got %v after the database was changed directly, want ErrTargetChanged", err)
+	}
+	if people, _ := db.GetUnderlyingSet(cgs.NewVertex("Person")); len(people) != 3 {
+		t.Errorf("people %v, want al, cy and di", people)
+	}
+}
+
+// inserting and deleting replace the whole state of the database, which must not reset its count of changes
+// so a database whose count starts at 0 is changed once before the transaction and once during it
+func TestTransactionTargetChangedByReplacement(t *testing.T) {
+	s := homs.NewStringElement
+	insert := func(db *InstantiatedDB, person string) error {
+		return db.AddElementToSet("Person", s(person), ElementInsertion{FunctionValues: map[string]homs.Element{"worksIn": s("ops")},
+			AttributeValues: map[string]homs.Element{"name": s(person)}})
+	}
+	for _, change := range []func(*InstantiatedDB) error{
+		func(db *InstantiatedDB) error { return insert(db, "di") },
+		func(db *InstantiatedDB) error {
+			_, err := db.DeleteElementFromSet("Person", s("bo"))
+			return err
+		},
+	} {
+		db := companyDB(t)
+		if err := change(&db); err != nil {
+			t.Fatal(err)
+		}
+		tx := db.Begin()
+		if err := tx.AddVertex("Project", nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := insert(&db, "ed"); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Commit(); !errors.Is(err, ErrTargetChanged) {
+			t.Errorf("got %v after the database was changed directly, want ErrTargetChanged", err)
+		}
+		if people, _ := db.GetUnderlyingSet(cgs.NewVertex("Person")); !elementInSet(s("ed"), people) {
+			t.Errorf("people %v, the direct insert of ed was lost", people)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package relationalGraphDB
+
+import (
+	"fmt"
+	"strings"
+
+	homs "RelationalGraphDB/src/morphismTypes"
+)
+
+type ViolationKind int
+
+const (
+	// the schema fails cgs.ValidateGraph
+	InvalidSchema ViolationKind = iota
+	// a vertex or edge with that name is already present
+	DuplicateName
+	// an edge is in the schema but the database has no morphism for it
+	MissingMorphism
+	// a morphism sends an element of its source outside of its target
+	ValueOutsideTarget
+	FunctionEquationViolated
+	PartialFunctionEquationViolated
+	RelationEquationViolated
+	// an attribute value is not of the sort the schema declares
+	AttributeSortMismatch
+	AttributeEquationViolated
+	// a change names a vertex or edge the schema does not have
+	UnknownName
+	// the element is already in the set it is being added to
+	DuplicateElement
+	// a change leaves a function edge or attribute with no value on some element
+	MissingValue
+	// an element can not be deleted because a restrict edge still points at it
+	DeleteRestricted
+	// a migration would give one element two different attribute values
+	ConflictingValues
+	// the chase was still changing the data when it ran out of rounds
+	ChaseDidNotTerminate
+	// a migration was asked to move data along an edge of a kind it does not handle
+	UnsupportedEdge
+	// a query names variables, edges or vertices that do not fit its schemas
+	InvalidQuery
+)
+
+func (kind ViolationKind) String() string {
+	switch kind {
+	case InvalidSchema:
+		return "invalid schema"
+	case DuplicateName:
+		return "duplicate name"
+	case MissingMorphism:
+		return "missing morphism"
+	case ValueOutsideTarget:
+		return "value outside target"
+	case FunctionEquationViolated:
+		return "function equation violated"
+	case PartialFunctionEquationViolated:
+		return "partial function equation violated"
+	case RelationEquationViolated:
+		return "relation equation violated"
+	case AttributeSortMismatch:
+		return "attribute sort mismatch"
+	case AttributeEquationViolated:
+		return "attribute equation violated"
+	case UnknownName:
+		return "unknown name"
+	case DuplicateElement:
+		return "duplicate element"
+	case MissingValue:
+		return "missing value"
+	case DeleteRestricted:
+		return "delete restricted"
+	case ConflictingValues:
+		return "conflicting values"
+	case ChaseDidNotTerminate:
+		return "chase did not terminate"
+	case UnsupportedEdge:
+		return "unsupported edge"
+	case InvalidQuery:
+		return "invalid query"
+	}
+	return "unknown violation"
+}
+
+// one thing wrong with a database
+// Vertex, Edge and Equation are the names of whichever of those are involved, empty otherwise
+// Element is only meaningful when HasElement is true
+type ValidationError struct {
+	Kind       ViolationKind
+	Vertex     string
+	Edge       string
+	Equation   string
+	Element    homs.Element
+	HasElement bool
+	Detail     string
+}
+
+func (err ValidationError) Error() string {
+	parts := make([]string, 0, 5)
+	if err.Vertex != "" {
+		parts = append(parts, "vertex "+err.Vertex)
+	}
+	if err.Edge != "" {
+		parts = append(parts, "edge "+err.Edge)
+	}
+	if err.Equation != "" {
+		parts = append(parts, "equation "+err.Equation)
+	}
+	if err.HasElement {
+		parts = append(parts, fmt.Sprintf("element %v", err.Element))
+	}
+	if err.Detail != "" {
+		parts = append(parts, err.Detail)
+	}
+	return err.Kind.String() + ": " + strings.Join(parts, ", ")
+}
+
+// every violation found in one pass
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d violation(s): %s", len(errs), strings.Join(messages, "; "))
+}
+
+// nil rather than an empty ValidationErrors so that err == nil works for callers
+func (errs ValidationErrors) asError() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,428 @@
+package schemaLanguage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+	rgdb "RelationalGraphDB/src/relationalGraphDB"
+)
+
+// the text of an instance looks like
+//
+//	instance {
+//		generators
+//			al bo : Employee
+//			sales : Department
+//		equations
+//			al.manager = bo
+//			bo.manager = bo
+//			sales.secretary = al
+//			al.worksIn = sales
+//			al."last name" = "Smith"
+//	}
+//
+// generators are the elements of each vertex, a bare or quoted name is a string element
+// and a whole number is an int element
+// each equation gives the value of one edge on one generator
+// a function edge or attribute needs a value on every generator of its source
+// a partial function edge is undefined wherever it is not given
+// a relation edge relates the generator to every value given for it
+// attribute values are read according to the sort, dates as 2006-01-02 and bools as true or false
+const (
+	generatorsSection = "generators"
+	equationsSection  = "equations"
+)
+
+var instanceSections = []string{generatorsSection, equationsSection}
+
+// a violation ValidateDB found in the loaded instance
+// along with the declaration that gave the offending data
+type InstanceError struct {
+	Position
+	Violation rgdb.ValidationError
+}
+
+func (err InstanceError) Error() string {
+	return err.Position.String() + ": " + err.Violation.Error()
+}
+
+type InstanceErrors []InstanceError
+
+func (errs InstanceErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d violation(s): %s", len(errs), strings.Join(messages, "; "))
+}
+
+// reads an instance of schema, the error is a ParseError if the text is malformed
+// and InstanceErrors if it parsed but the result fails validation
+func ParseInstance(schema cgs.SchemaGraph, text string) (rgdb.InstantiatedDB, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return rgdb.InstantiatedDB{}, err
+	}
+	stream := &tokenStream{tokens: tokens}
+	toReturn, err := parseInstanceBlock(stream, schema)
+	if err != nil {
+		return rgdb.InstantiatedDB{}, err
+	}
+	if t := stream.next(); t.kind != endToken {
+		return rgdb.InstantiatedDB{}, unexpected(t, "end of input")
+	}
+	return toReturn, nil
+}
+
+// a schema block followed by an instance block of that schema
+func ParseDatabase(text string) (rgdb.InstantiatedDB, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return rgdb.InstantiatedDB{}, err
+	}
+	stream := &tokenStream{tokens: tokens}
+	schema, err := parseSchemaBlock(stream)
+	if err != nil {
+		return rgdb.InstantiatedDB{}, err
+	}
+	toReturn, err := parseInstanceBlock(stream, schema)
+	if err != nil {
+		return rgdb.InstantiatedDB{}, err
+	}
+	if t := stream.next(); t.kind != endToken {
+		return rgdb.InstantiatedDB{}, unexpected(t, "end of input")
+	}
+	return toReturn, nil
+}
+
+// everything read so far, along with where each piece came from
+type instanceBuilder struct {
+	schema     cgs.SchemaGraph
+	start      Position
+	sets       map[cgs.Vertex]([]homs.Element)
+	generators map[cgs.Vertex](map[homs.Element]Position)
+	// edge name then generator to the value given and where
+	values        map[string](map[homs.Element]homs.Element)
+	relatedValues map[string](map[homs.Element]([]homs.Element))
+	declaredAt    map[string](map[homs.Element]Position)
+}
+
+func newInstanceBuilder(schema cgs.SchemaGraph, start Position) *instanceBuilder {
+	toReturn := &instanceBuilder{schema: schema, start: start,
+		sets:          make(map[cgs.Vertex]([]homs.Element)),
+		generators:    make(map[cgs.Vertex](map[homs.Element]Position)),
+		values:        make(map[string](map[homs.Element]homs.Element)),
+		relatedValues: make(map[string](map[homs.Element]([]homs.Element))),
+		declaredAt:    make(map[string](map[homs.Element]Position))}
+	for _, v := range schema.GetVertices() {
+		toReturn.sets[v] = make([]homs.Element, 0)
+		toReturn.generators[v] = make(map[homs.Element]Position)
+	}
+	return toReturn
+}
+
+// instance { sections }
+func parseInstanceBlock(stream *tokenStream, schema cgs.SchemaGraph) (rgdb.InstantiatedDB, error) {
+	startToken, err := stream.expectKeyword("instance")
+	if err != nil {
+		return rgdb.InstantiatedDB{}, err
+	}
+	if _, err := stream.expectSymbol("{"); err != nil {
+		return rgdb.InstantiatedDB{}, err
+	}
+	builder := newInstanceBuilder(schema, startToken.where)
+	for !stream.peek().isSymbol("}") {
+		sectionToken := stream.next()
+		if !isSectionKeyword(sectionToken, instanceSections) {
+			return rgdb.InstantiatedDB{}, unexpected(sectionToken, "a section name or '}'")
+		}
+		for startsDeclaration(stream.peek(), instanceSections) || stream.peek().kind == numberToken {
+			if sectionToken.text == generatorsSection {
+				err = builder.parseGenerators(stream)
+			} else {
+				err = builder.parseValue(stream)
+			}
+			if err != nil {
+				return rgdb.InstantiatedDB{}, err
+			}
+		}
+	}
+	stream.next()
+	return builder.build()
+}
+
+func elementOfToken(t token) (homs.Element, error) {
+	if t.kind == numberToken {
+		value, err := strconv.Atoi(t.text)
+		if err != nil {
+			return homs.Element{}, ParseError{Position: t.where, Message: "generators must be names or whole numbers, not " + t.describe()}
+		}
+		return homs.NewIntElement(value), nil
+	}
+	return homs.NewStringElement(t.text), nil
+}
+
+func (stream *tokenStream) expectElement() (token, homs.Element, error) {
+	t := stream.next()
+	if !t.isName() && t.kind != numberToken {
+		return t, homs.Element{}, unexpected(t, "a generator")
+	}
+	element, err := elementOfToken(t)
+	return t, element, err
+}
+
+// one or more generators : vertex
+func (builder *instanceBuilder) parseGenerators(stream *tokenStream) error {
+	generatorTokens := make([]token, 0, 1)
+	for stream.peek().isName() || stream.peek().kind == numberToken {
+		generatorTokens = append(generatorTokens, stream.next())
+	}
+	if _, err := stream.expectSymbol(":"); err != nil {
+		return err
+	}
+	vertexToken, err := stream.expectName()
+	if err != nil {
+		return err
+	}
+	vertex := cgs.NewVertex(vertexToken.text)
+	declared, present := builder.generators[vertex]
+	if !present {
+		return ParseError{Position: vertexToken.where, Message: "unknown entity " + vertexToken.describe()}
+	}
+	for _, generatorToken := range generatorTokens {
+		element, err := elementOfToken(generatorToken)
+		if err != nil {
+			return err
+		}
+		if earlier, repeated := declared[element]; repeated {
+			return ParseError{Position: generatorToken.where, Message: fmt.Sprintf("generator %s of %s was already declared at %v", generatorToken.describe(), vertexToken.text, earlier)}
+		}
+		declared[element] = generatorToken.where
+		builder.sets[vertex] = append(builder.sets[vertex], element)
+	}
+	return nil
+}
+
+// an attribute value of the given sort
+func literalOfToken(t token, sort cgs.Sort) (homs.Element, error) {
+	bad := ParseError{Position: t.where, Message: t.describe() + " is not a " + sort.String()}
+	switch sort {
+	case cgs.StringSort:
+		if !t.isName() && t.kind != numberToken {
+			return homs.Element{}, bad
+		}
+		return homs.NewStringElement(t.text), nil
+	case cgs.IntSort:
+		value, err := strconv.Atoi(t.text)
+		if err != nil || t.kind != numberToken {
+			return homs.Element{}, bad
+		}
+		return homs.NewIntElement(value), nil
+	case cgs.FloatSort:
+		value, err := strconv.ParseFloat(t.text, 64)
+		if err != nil || t.kind != numberToken {
+			return homs.Element{}, bad
+		}
+		toReturn, valid := homs.NewFloatElement(value)
+		if !valid {
+			return homs.Element{}, bad
+		}
+		return toReturn, nil
+	case cgs.BoolSort:
+		if t.kind != wordToken || (t.text != "true" && t.text != "false") {
+			return homs.Element{}, bad
+		}
+		return homs.NewBoolElement(t.text == "true"), nil
+	case cgs.DateSort:
+		toReturn, valid := homs.ParseDateElement(t.text)
+		if !valid {
+			return homs.Element{}, bad
+		}
+		return toReturn, nil
+	}
+	return homs.Element{}, bad
+}
+
+func (builder *instanceBuilder) recordDeclaration(edgeName string, generator homs.Element, where Position) {
+	if _, present := builder.declaredAt[edgeName]; !present {
+		builder.declaredAt[edgeName] = make(map[homs.Element]Position)
+	}
+	if _, present := builder.declaredAt[edgeName][generator]; !present {
+		builder.declaredAt[edgeName][generator] = where
+	}
+}
+
+// generator.edge = value
+func (builder *instanceBuilder) parseValue(stream *tokenStream) error {
+	generatorToken, generator, err := stream.expectElement()
+	if err != nil {
+		return err
+	}
+	if _, err := stream.expectSymbol("."); err != nil {
+		return err
+	}
+	edgeToken, err := stream.expectName()
+	if err != nil {
+		return err
+	}
+	if _, err := stream.expectSymbol("="); err != nil {
+		return err
+	}
+	valueToken := stream.next()
+	var source cgs.Vertex
+	var value homs.Element
+	isRelation := false
+	if attribute, isAttribute := builder.schema.GetAttributeEdgeByName(edgeToken.text); isAttribute {
+		source = attribute.GetSource()
+		value, err = literalOfToken(valueToken, attribute.GetSort())
+	} else if edge, isEdge := builder.schema.GetRelationEdgeByName(edgeToken.text); isEdge {
+		_, isRelationOnly := builder.schema.GetDefRelationEdgeByName(edgeToken.text)
+		isRelation = isRelationOnly
+		source = edge.GetSource()
+		if !valueToken.isName() && valueToken.kind != numberToken {
+			return unexpected(valueToken, "a generator")
+		}
+		value, err = elementOfToken(valueToken)
+	} else {
+		return ParseError{Position: edgeToken.where, Message: "unknown edge " + edgeToken.describe()}
+	}
+	if err != nil {
+		return err
+	}
+	if _, isGenerator := builder.generators[source][generator]; !isGenerator {
+		return ParseError{Position: generatorToken.where, Message: generatorToken.describe() + " is not a generator of " + source.GetIdentifier()}
+	}
+	if isRelation {
+		if _, present := builder.relatedValues[edgeToken.text]; !present {
+			builder.relatedValues[edgeToken.text] = make(map[homs.Element]([]homs.Element))
+		}
+		builder.relatedValues[edgeToken.text][generator] = append(builder.relatedValues[edgeToken.text][generator], value)
+		builder.recordDeclaration(edgeToken.text, generator, generatorToken.where)
+		return nil
+	}
+	if _, present := builder.values[edgeToken.text]; !present {
+		builder.values[edgeToken.text] = make(map[homs.Element]homs.Element)
+	}
+	if _, repeated := builder.values[edgeToken.text][generator]; repeated {
+		return ParseError{Position: generatorToken.where, Message: fmt.Sprintf("%s.%s was already given at %v", generatorToken.text, edgeToken.text,
+			builder.declaredAt[edgeToken.text][generator])}
+	}
+	builder.values[edgeToken.text][generator] = value
+	builder.recordDeclaration(edgeToken.text, generator, generatorToken.where)
+	return nil
+}
+
+// a function edge or attribute must have a value on every generator of its source
+func (builder *instanceBuilder) missingValues(edgeName string, source cgs.Vertex) InstanceErrors {
+	toReturn := make(InstanceErrors, 0)
+	for _, generator := range builder.sets[source] {
+		if _, present := builder.values[edgeName][generator]; !present {
+			toReturn = append(toReturn, InstanceError{Position: builder.generators[source][generator],
+				Violation: rgdb.ValidationError{Kind: rgdb.MissingMorphism, Vertex: source.GetIdentifier(), Edge: edgeName,
+					Element: generator, HasElement: true, Detail: "no value given for this generator"}})
+		}
+	}
+	return toReturn
+}
+
+func (builder *instanceBuilder) build() (rgdb.InstantiatedDB, error) {
+	problems := make(InstanceErrors, 0)
+	functions := make(map[cgs.FunctionEdge](homs.FunctionTable))
+	for _, edge := range builder.schema.GetFunctionEdges() {
+		problems = append(problems, builder.missingValues(edge.GetIdentifier(), edge.GetSource())...)
+		functions[edge] = homs.NewFunctionTable(builder.values[edge.GetIdentifier()])
+	}
+	partialFunctions := make(map[cgs.PartialFunctionEdge](homs.PartialFunctionTable))
+	for _, edge := range builder.schema.GetPartialFunctionEdges() {
+		partialFunctions[edge] = homs.NewPartialFunctionTable(builder.values[edge.GetIdentifier()])
+	}
+	relations := make(map[cgs.RelationEdge](homs.RelationTable))
+	for _, edge := range builder.schema.GetRelationEdges() {
+		relations[edge] = homs.NewRelationTable(builder.relatedValues[edge.GetIdentifier()])
+	}
+	attributes := make(map[cgs.AttributeEdge](homs.FunctionTable))
+	for _, edge := range builder.schema.GetAttributeEdges() {
+		problems = append(problems, builder.missingValues(edge.GetIdentifier(), edge.GetSource())...)
+		attributes[edge] = homs.NewFunctionTable(builder.values[edge.GetIdentifier()])
+	}
+	if len(problems) > 0 {
+		return rgdb.InstantiatedDB{}, problems
+	}
+	toReturn, err := rgdb.NewInstantiatedDBFromTables(builder.schema, builder.sets, functions, partialFunctions, relations, attributes)
+	if err != nil {
+		for _, violation := range err.(rgdb.ValidationErrors) {
+			problems = append(problems, InstanceError{Position: builder.blame(violation), Violation: violation})
+		}
+		return rgdb.InstantiatedDB{}, problems
+	}
+	return toReturn, nil
+}
+
+// the first edge of each side of the schema equation named name, the ones given on the element an equation fails at
+func (builder *instanceBuilder) firstEdges(name string) []string {
+	toReturn := make([]string, 0, 2)
+	addFirst := func(path []cgs.PossiblyRelationEdge) {
+		if len(path) > 0 {
+			toReturn = append(toReturn, path[0].GetIdentifier())
+		}
+	}
+	for _, equation := range builder.schema.GetFunctionEquations() {
+		if equation.GetIdentifier() == name {
+			addFirst(equation.GetLHS())
+			addFirst(equation.GetRHS())
+		}
+	}
+	for _, equation := range builder.schema.GetPartialFunctionEquations() {
+		if equation.GetIdentifier() == name {
+			addFirst(equation.GetLHS())
+			addFirst(equation.GetRHS())
+		}
+	}
+	for _, equation := range builder.schema.GetRelationEquations() {
+		if equation.GetIdentifier() == name {
+			addFirst(equation.GetLHS())
+			addFirst(equation.GetRHS())
+		}
+	}
+	for _, equation := range builder.schema.GetAttributeEquations() {
+		if equation.GetIdentifier() != name {
+			continue
+		}
+		for _, side := range []struct {
+			path      []cgs.FunctionEdge
+			attribute cgs.AttributeEdge
+		}{{equation.GetLHSPath(), equation.GetLHSAttribute()}, {equation.GetRHSPath(), equation.GetRHSAttribute()}} {
+			if len(side.path) > 0 {
+				toReturn = append(toReturn, side.path[0].GetIdentifier())
+			} else {
+				toReturn = append(toReturn, side.attribute.GetIdentifier())
+			}
+		}
+	}
+	return toReturn
+}
+
+// the equation that gave the bad value, for a violated schema equation the first one given on the element along either side,
+// or failing that the generator the violation is about or failing that the start of the instance
+func (builder *instanceBuilder) blame(violation rgdb.ValidationError) Position {
+	if !violation.HasElement {
+		return builder.start
+	}
+	if where, present := builder.declaredAt[violation.Edge][violation.Element]; present && violation.Edge != "" {
+		return where
+	}
+	if violation.Equation != "" {
+		for _, edge := range builder.firstEdges(violation.Equation) {
+			if where, present := builder.declaredAt[edge][violation.Element]; present {
+				return where
+			}
+		}
+	}
+	if where, present := builder.generators[cgs.NewVertex(violation.Vertex)][violation.Element]; present {
+		return where
+	}
+	return builder.start
+}
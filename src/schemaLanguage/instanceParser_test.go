@@ -0,0 +1,108 @@
+package schemaLanguage
+
+import (
+	"testing"
+
+	homs "RelationalGraphDB/src/morphismTypes"
+	rgdb "RelationalGraphDB/src/relationalGraphDB"
+)
+
+const numbersSchema = `schema {
+	entities
+		N
+	foreign_keys
+		next : N -> N
+	attributes
+		half : N -> float
+	path_equations
+		"two steps" : next.next = id
+}`
+
+func TestParseInstanceIntGenerators(t *testing.T) {
+	schema, err := ParseSchema(numbersSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := ParseInstance(schema, `instance {
+	generators
+		0 1 : N
+	equations
+		0.next = 1
+		1.next = 0
+		0.half = 0.5
+		1.half = -1.5
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextEdge, _ := schema.GetFunctionEdgeByName("next")
+	next, _ := db.GetFunction(nextEdge)
+	if got := next.Evaluate(homs.NewIntElement(0)); got != homs.NewIntElement(1) {
+		t.Errorf("next of 0 is %v, want 1", got)
+	}
+	half, _ := schema.GetAttributeEdgeByName("half")
+	values, _ := db.GetAttribute(half)
+	minusHalf, _ := homs.NewFloatElement(-1.5)
+	if got := values.Evaluate(homs.NewIntElement(1)); got != minusHalf {
+		t.Errorf("half of 1 is %v, want -1.5", got)
+	}
+}
+
+func expectInstanceError(t *testing.T, err error, kind rgdb.ViolationKind, line int, column int) {
+	t.Helper()
+	problems, isInstanceErrors := err.(InstanceErrors)
+	if !isInstanceErrors || len(problems) == 0 {
+		t.Fatalf("got %v, want InstanceErrors", err)
+	}
+	for _, problem := range problems {
+		if problem.Violation.Kind == kind {
+			if problem.Line != line || problem.Column != column {
+				t.Errorf("%v reported at line %d, column %d, want line %d, column %d", kind, problem.Line, problem.Column, line, column)
+			}
+			return
+		}
+	}
+	t.Errorf("got %v, want a %v", err, kind)
+}
+
+// an equation of the schema that fails is blamed on the line that gave the first edge on the element, not its declaration
+func TestParseInstanceErrorPositions(t *testing.T) {
+	schema, err := ParseSchema(numbersSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseInstance(schema, `instance {
+	generators
+		0 1 : N
+	equations
+		0.next = 0
+		1.next = 0
+		0.half = 0
+		1.half = 1
+}`)
+	expectInstanceError(t, err, rgdb.FunctionEquationViolated, 6, 3)
+	_, err = ParseInstance(schema, `instance {
+	generators
+		0 1 : N
+	equations
+		0.next = 1
+		1.next = 0
+		0.half = 0
+}`)
+	expectInstanceError(t, err, rgdb.MissingMorphism, 3, 5)
+	_, err = ParseInstance(schema, `instance {
+	generators
+		0 : N
+	equations
+		0.next = 2
+		0.half = 0
+}`)
+	expectInstanceError(t, err, rgdb.ValueOutsideTarget, 5, 3)
+	_, err = ParseInstance(schema, `instance {
+	generators
+		0 : N
+	equations
+		0.half = zero
+}`)
+	expectParseError(t, err, 5, 12)
+}
@@ -0,0 +1,200 @@
+package schemaLanguage
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// where something is in the source text, both start at 1
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+type ParseError struct {
+	Position
+	Message string
+}
+
+func (err ParseError) Error() string {
+	return err.Position.String() + ": " + err.Message
+}
+
+type tokenKind int
+
+const (
+	endToken tokenKind = iota
+	// a bare word, could also be a keyword
+	wordToken
+	// a double quoted name, never a keyword
+	quotedToken
+	// a number, possibly negative or with a decimal point
+	numberToken
+	symbolToken
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	where Position
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case endToken:
+		return "end of input"
+	case quotedToken:
+		return fmt.Sprintf("%q", t.text)
+	}
+	return "'" + t.text + "'"
+}
+
+// names can be bare words or quoted so that they may contain spaces
+func (t token) isName() bool {
+	return t.kind == wordToken || t.kind == quotedToken
+}
+
+func (t token) isSymbol(symbol string) bool {
+	return t.kind == symbolToken && t.text == symbol
+}
+
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == wordToken && t.text == keyword
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// splits text into tokens, comments run from // to the end of the line
+func tokenize(text string) ([]token, error) {
+	toReturn := make([]token, 0)
+	runes := []rune(text)
+	line, column := 1, 1
+	advance := func() {
+		if runes[0] == '\n' {
+			line = line + 1
+			column = 1
+		} else {
+			column = column + 1
+		}
+		runes = runes[1:]
+	}
+	for len(runes) > 0 {
+		r := runes[0]
+		where := Position{Line: line, Column: column}
+		switch {
+		case unicode.IsSpace(r):
+			advance()
+		case r == '/' && len(runes) > 1 && runes[1] == '/':
+			for len(runes) > 0 && runes[0] != '\n' {
+				advance()
+			}
+		case r == '"':
+			advance()
+			var builder strings.Builder
+			for {
+				if len(runes) == 0 || runes[0] == '\n' {
+					return nil, ParseError{Position: where, Message: "unterminated quoted name"}
+				}
+				if runes[0] == '"' {
+					advance()
+					break
+				}
+				if runes[0] == '\\' && len(runes) > 1 {
+					advance()
+				}
+				builder.WriteRune(runes[0])
+				advance()
+			}
+			toReturn = append(toReturn, token{kind: quotedToken, text: builder.String(), where: where})
+		case unicode.IsDigit(r) || (r == '-' && len(runes) > 1 && unicode.IsDigit(runes[1])):
+			var builder strings.Builder
+			builder.WriteRune(r)
+			advance()
+			// a . is only a decimal point when a digit follows, so 1.f is the generator 1 followed by .f
+			for len(runes) > 0 && (unicode.IsDigit(runes[0]) || runes[0] == '-' ||
+				(runes[0] == '.' && len(runes) > 1 && unicode.IsDigit(runes[1]))) {
+				builder.WriteRune(runes[0])
+				advance()
+			}
+			toReturn = append(toReturn, token{kind: numberToken, text: builder.String(), where: where})
+		case r == '-' && len(runes) > 1 && runes[1] == '>':
+			advance()
+			advance()
+			toReturn = append(toReturn, token{kind: symbolToken, text: "->", where: where})
+		case strings.ContainsRune("{}:=.,()[]", r):
+			advance()
+			toReturn = append(toReturn, token{kind: symbolToken, text: string(r), where: where})
+		case isWordRune(r):
+			var builder strings.Builder
+			for len(runes) > 0 && isWordRune(runes[0]) {
+				builder.WriteRune(runes[0])
+				advance()
+			}
+			toReturn = append(toReturn, token{kind: wordToken, text: builder.String(), where: where})
+		default:
+			return nil, ParseError{Position: where, Message: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+	toReturn = append(toReturn, token{kind: endToken, where: Position{Line: line, Column: column}})
+	return toReturn, nil
+}
+
+// a cursor over the tokens
+type tokenStream struct {
+	tokens []token
+	index  int
+}
+
+func (stream *tokenStream) peek() token {
+	return stream.tokens[stream.index]
+}
+
+func (stream *tokenStream) peekAhead(offset int) token {
+	if stream.index+offset >= len(stream.tokens) {
+		return stream.tokens[len(stream.tokens)-1]
+	}
+	return stream.tokens[stream.index+offset]
+}
+
+func (stream *tokenStream) next() token {
+	toReturn := stream.tokens[stream.index]
+	if toReturn.kind != endToken {
+		stream.index = stream.index + 1
+	}
+	return toReturn
+}
+
+func unexpected(t token, wanted string) ParseError {
+	return ParseError{Position: t.where, Message: "expected " + wanted + " but found " + t.describe()}
+}
+
+func (stream *tokenStream) expectSymbol(symbol string) (token, error) {
+	t := stream.next()
+	if !t.isSymbol(symbol) {
+		return t, unexpected(t, "'"+symbol+"'")
+	}
+	return t, nil
+}
+
+func (stream *tokenStream) expectKeyword(keyword string) (token, error) {
+	t := stream.next()
+	if !t.isKeyword(keyword) {
+		return t, unexpected(t, "'"+keyword+"'")
+	}
+	return t, nil
+}
+
+func (stream *tokenStream) expectName() (token, error) {
+	t := stream.next()
+	if !t.isName() {
+		return t, unexpected(t, "a name")
+	}
+	return t, nil
+}
@@ -0,0 +1,292 @@
+package schemaLanguage
+
+import (
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	rgdb "RelationalGraphDB/src/relationalGraphDB"
+)
+
+// the text of a query looks like
+//
+//	query {
+//		entity Pair {
+//			from
+//				e : Employee
+//				d : Department
+//			where
+//				e.worksIn = d
+//				e."last name" = "Smith"
+//			return
+//				name = e."last name"
+//				secretaryOf = { s = d.secretary }
+//		}
+//		entity Secretary {
+//			from
+//				s : Employee
+//		}
+//	}
+//
+// there is one entity block for each entity of the target, its from section binds variables to entities of the source
+// where keeps only the choices of values for which both sides are equal
+// return gives a term for each attribute of the target on the entity, and for each foreign key out of it
+// a term for every variable of the block at its target, between { and }
+// a term is a variable followed by edges of the source joined by . or a constant, read by the sort it is compared with
+const (
+	entityKeyword = "entity"
+	fromSection   = "from"
+	whereSection  = "where"
+	returnSection = "return"
+)
+
+var querySections = []string{fromSection, whereSection, returnSection}
+
+// reads a query from source to target, the error is a ParseError if the text is malformed
+// and the ValidationErrors of rgdb.NewQuery if it parsed but does not fit the schemas
+func ParseQuery(source cgs.SchemaGraph, target cgs.SchemaGraph, text string) (rgdb.Query, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return rgdb.Query{}, err
+	}
+	stream := &tokenStream{tokens: tokens}
+	blocks := make(map[string]rgdb.QueryBlock)
+	if _, err := stream.expectKeyword("query"); err != nil {
+		return rgdb.Query{}, err
+	}
+	if _, err := stream.expectSymbol("{"); err != nil {
+		return rgdb.Query{}, err
+	}
+	for !stream.peek().isSymbol("}") {
+		if _, err := stream.expectKeyword(entityKeyword); err != nil {
+			return rgdb.Query{}, err
+		}
+		vertexToken, err := stream.expectName()
+		if err != nil {
+			return rgdb.Query{}, err
+		}
+		if _, repeated := blocks[vertexToken.text]; repeated {
+			return rgdb.Query{}, ParseError{Position: vertexToken.where, Message: "duplicate entity " + vertexToken.describe()}
+		}
+		block, err := parseQueryBlock(stream, &source, &target)
+		if err != nil {
+			return rgdb.Query{}, err
+		}
+		blocks[vertexToken.text] = block
+	}
+	stream.next()
+	if t := stream.next(); t.kind != endToken {
+		return rgdb.Query{}, unexpected(t, "end of input")
+	}
+	return rgdb.NewQuery(source, target, blocks)
+}
+
+type queryBlockParser struct {
+	source    *cgs.SchemaGraph
+	target    *cgs.SchemaGraph
+	variables map[string]cgs.Vertex
+	block     rgdb.QueryBlock
+}
+
+// { sections }
+func parseQueryBlock(stream *tokenStream, source *cgs.SchemaGraph, target *cgs.SchemaGraph) (rgdb.QueryBlock, error) {
+	parser := &queryBlockParser{source: source, target: target, variables: make(map[string]cgs.Vertex),
+		block: rgdb.QueryBlock{From: make([]rgdb.Generator, 0), Where: make([]rgdb.PathEquality, 0),
+			Attributes: make(map[string]rgdb.Term), ForeignKeys: make(map[string](map[string]rgdb.Term))}}
+	if _, err := stream.expectSymbol("{"); err != nil {
+		return rgdb.QueryBlock{}, err
+	}
+	for !stream.peek().isSymbol("}") {
+		sectionToken := stream.next()
+		if !isSectionKeyword(sectionToken, querySections) {
+			return rgdb.QueryBlock{}, unexpected(sectionToken, "from, where, return or '}'")
+		}
+		// a where equality may start with a numeric constant
+		for startsDeclaration(stream.peek(), querySections) || stream.peek().kind == numberToken {
+			var err error
+			switch sectionToken.text {
+			case fromSection:
+				err = parser.parseGenerators(stream)
+			case whereSection:
+				err = parser.parseEquality(stream)
+			case returnSection:
+				err = parser.parseReturn(stream)
+			}
+			if err != nil {
+				return rgdb.QueryBlock{}, err
+			}
+		}
+	}
+	stream.next()
+	return parser.block, nil
+}
+
+// one or more variables : entity of the source
+func (parser *queryBlockParser) parseGenerators(stream *tokenStream) error {
+	variableTokens := make([]token, 0, 1)
+	for stream.peek().isName() {
+		variableTokens = append(variableTokens, stream.next())
+	}
+	if _, err := stream.expectSymbol(":"); err != nil {
+		return err
+	}
+	vertexToken, err := stream.expectName()
+	if err != nil {
+		return err
+	}
+	if !hasVertex(parser.source, vertexToken.text) {
+		return ParseError{Position: vertexToken.where, Message: "unknown entity " + vertexToken.describe()}
+	}
+	for _, variableToken := range variableTokens {
+		if _, repeated := parser.variables[variableToken.text]; repeated {
+			return ParseError{Position: variableToken.where, Message: "variable " + variableToken.describe() + " is already bound"}
+		}
+		parser.variables[variableToken.text] = cgs.NewVertex(vertexToken.text)
+		parser.block.From = append(parser.block.From, rgdb.Generator{Variable: variableToken.text, Vertex: vertexToken.text})
+	}
+	return nil
+}
+
+func hasVertex(schema *cgs.SchemaGraph, name string) bool {
+	for _, v := range schema.GetVertices() {
+		if v.GetIdentifier() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// a variable and a path, or a single token to be read as a constant once its sort is known
+type parsedTerm struct {
+	start      token
+	term       rgdb.Term
+	isConstant bool
+}
+
+func (parser *queryBlockParser) parseTerm(stream *tokenStream) (parsedTerm, error) {
+	start := stream.next()
+	if _, bound := parser.variables[start.text]; !start.isName() || !bound {
+		if !start.isName() && start.kind != numberToken {
+			return parsedTerm{}, unexpected(start, "a variable or a constant")
+		}
+		return parsedTerm{start: start, isConstant: true}, nil
+	}
+	path := make([]string, 0)
+	for stream.peek().isSymbol(".") {
+		stream.next()
+		edgeToken, err := stream.expectName()
+		if err != nil {
+			return parsedTerm{}, err
+		}
+		if !parser.source.HasEdgeNamed(edgeToken.text) {
+			return parsedTerm{}, ParseError{Position: edgeToken.where, Message: "unknown edge " + edgeToken.describe()}
+		}
+		path = append(path, edgeToken.text)
+	}
+	return parsedTerm{start: start, term: rgdb.VariableTerm(start.text, path...)}, nil
+}
+
+// the sort of the attribute a variable term ends in, second return is false if it does not end in one
+func (parser *queryBlockParser) sortOf(term rgdb.Term) (cgs.Sort, bool) {
+	if len(term.Path) == 0 {
+		return cgs.StringSort, false
+	}
+	attribute, isAttribute := parser.source.GetAttributeEdgeByName(term.Path[len(term.Path)-1])
+	return attribute.GetSort(), isAttribute
+}
+
+func constantOf(parsed parsedTerm, sort cgs.Sort) (rgdb.Term, error) {
+	value, err := literalOfToken(parsed.start, sort)
+	if err != nil {
+		return rgdb.Term{}, err
+	}
+	return rgdb.ConstantTerm(value), nil
+}
+
+// term = term, a constant takes the sort of the attribute the other side ends in
+func (parser *queryBlockParser) parseEquality(stream *tokenStream) error {
+	lhs, err := parser.parseTerm(stream)
+	if err != nil {
+		return err
+	}
+	if _, err := stream.expectSymbol("="); err != nil {
+		return err
+	}
+	rhs, err := parser.parseTerm(stream)
+	if err != nil {
+		return err
+	}
+	if lhs.isConstant && rhs.isConstant {
+		return ParseError{Position: lhs.start.where, Message: "at least one side of an equality must start with a variable"}
+	}
+	for _, sides := range [][2]*parsedTerm{{&lhs, &rhs}, {&rhs, &lhs}} {
+		constant, other := sides[0], sides[1]
+		if !constant.isConstant {
+			continue
+		}
+		sort, hasSort := parser.sortOf(other.term)
+		if !hasSort {
+			return ParseError{Position: constant.start.where, Message: constant.start.describe() +
+				" is not a bound variable and the other side does not end in an attribute"}
+		}
+		if constant.term, err = constantOf(*constant, sort); err != nil {
+			return err
+		}
+	}
+	parser.block.Where = append(parser.block.Where, rgdb.PathEquality{LHS: lhs.term, RHS: rhs.term})
+	return nil
+}
+
+// attribute = term, or foreign key = { variable = term ... }
+func (parser *queryBlockParser) parseReturn(stream *tokenStream) error {
+	edgeToken := stream.next()
+	if _, err := stream.expectSymbol("="); err != nil {
+		return err
+	}
+	if attribute, isAttribute := parser.target.GetAttributeEdgeByName(edgeToken.text); isAttribute {
+		if _, repeated := parser.block.Attributes[edgeToken.text]; repeated {
+			return ParseError{Position: edgeToken.where, Message: "attribute " + edgeToken.describe() + " is already given"}
+		}
+		parsed, err := parser.parseTerm(stream)
+		if err != nil {
+			return err
+		}
+		if parsed.isConstant {
+			if parsed.term, err = constantOf(parsed, attribute.GetSort()); err != nil {
+				return err
+			}
+		}
+		parser.block.Attributes[edgeToken.text] = parsed.term
+		return nil
+	}
+	if _, isEdge := parser.target.GetFunctionEdgeByName(edgeToken.text); !isEdge {
+		return ParseError{Position: edgeToken.where, Message: "unknown attribute or foreign key " + edgeToken.describe()}
+	}
+	if _, repeated := parser.block.ForeignKeys[edgeToken.text]; repeated {
+		return ParseError{Position: edgeToken.where, Message: "foreign key " + edgeToken.describe() + " is already given"}
+	}
+	terms := make(map[string]rgdb.Term)
+	if _, err := stream.expectSymbol("{"); err != nil {
+		return err
+	}
+	for !stream.peek().isSymbol("}") {
+		variableToken, err := stream.expectName()
+		if err != nil {
+			return err
+		}
+		if _, err := stream.expectSymbol("="); err != nil {
+			return err
+		}
+		parsed, err := parser.parseTerm(stream)
+		if err != nil {
+			return err
+		}
+		if parsed.isConstant {
+			return ParseError{Position: parsed.start.where, Message: parsed.start.describe() + " is not a variable of this block"}
+		}
+		terms[variableToken.text] = parsed.term
+		if stream.peek().isSymbol(",") {
+			stream.next()
+		}
+	}
+	stream.next()
+	parser.block.ForeignKeys[edgeToken.text] = terms
+	return nil
+}
@@ -0,0 +1,223 @@
+package schemaLanguage
+
+import (
+	"fmt"
+	"testing"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+	homs "RelationalGraphDB/src/morphismTypes"
+	rgdb "RelationalGraphDB/src/relationalGraphDB"
+)
+
+const companySchema = `schema {
+	entities
+		Person Department
+	foreign_keys
+		worksIn : Person -> Department
+		head : Department -> Person
+	attributes
+		name : Person -> string
+		age : Person -> int
+	path_equations
+		"heads work there" : head.worksIn = id
+}`
+
+const companyInstance = `instance {
+	generators
+		al bo cy : Person
+		sales ops : Department
+	equations
+		al.worksIn = sales
+		bo.worksIn = sales
+		cy.worksIn = ops
+		sales.head = al
+		ops.head = cy
+		al.name = Al
+		bo.name = Bo
+		cy.name = Cy
+		al.age = 30
+		bo.age = 40
+		cy.age = 30
+}`
+
+// pairs of people working in the same department, each pair pointing at its first member
+const colleaguesSchema = `schema {
+	entities
+		Pair Member
+	foreign_keys
+		first : Pair -> Member
+	attributes
+		firstName : Pair -> string
+		name : Member -> string
+}`
+
+// only the pairs whose first member is 30
+const colleaguesQuery = `query {
+	entity Pair {
+		from
+			p q : Person
+		where
+			p.worksIn = q.worksIn
+			30 = p.age
+		return
+			firstName = p.name
+			first = { m = p }
+	}
+	entity Member {
+		from
+			m : Person
+		return
+			name = m.name
+	}
+}`
+
+func colleagueSchemas(t *testing.T) (cgs.SchemaGraph, cgs.SchemaGraph) {
+	t.Helper()
+	source, err := ParseSchema(companySchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := ParseSchema(colleaguesSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return source, target
+}
+
+func TestParseQuery(t *testing.T) {
+	source, target := colleagueSchemas(t)
+	query, err := ParseQuery(source, target, colleaguesQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair, _ := query.Block("Pair")
+	want := rgdb.QueryBlock{From: []rgdb.Generator{{Variable: "p", Vertex: "Person"}, {Variable: "q", Vertex: "Person"}},
+		Where: []rgdb.PathEquality{{LHS: rgdb.VariableTerm("p", "worksIn"), RHS: rgdb.VariableTerm("q", "worksIn")},
+			{LHS: rgdb.ConstantTerm(homs.NewIntElement(30)), RHS: rgdb.VariableTerm("p", "age")}},
+		Attributes:  map[string]rgdb.Term{"firstName": rgdb.VariableTerm("p", "name")},
+		ForeignKeys: map[string](map[string]rgdb.Term){"first": {"m": rgdb.VariableTerm("p")}}}
+	// terms print as they are written, whether a path is empty or nil
+	if fmt.Sprintf("%+v", pair) != fmt.Sprintf("%+v", want) {
+		t.Errorf("Pair is %+v, want %+v", pair, want)
+	}
+}
+
+// the query read from text gives the same instance as evaluating it by hand would
+func TestParseQueryEvaluate(t *testing.T) {
+	source, target := colleagueSchemas(t)
+	db, err := ParseInstance(source, companyInstance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query, err := ParseQuery(source, target, colleaguesQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	colleagues, err := query.Evaluate(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := homs.NewStringElement
+	pairs, _ := colleagues.GetUnderlyingSet(cgs.NewVertex("Pair"))
+	want := []homs.Element{homs.NewTupleElement(s("al"), s("al")), homs.NewTupleElement(s("al"), s("bo")), homs.NewTupleElement(s("cy"), s("cy"))}
+	if !sameElements(pairs, want) {
+		t.Errorf("pairs %v, want %v", pairs, want)
+	}
+	firstEdge, _ := target.GetFunctionEdgeByName("first")
+	first, _ := colleagues.GetFunction(firstEdge)
+	firstNameEdge, _ := target.GetAttributeEdgeByName("firstName")
+	firstName, _ := colleagues.GetAttribute(firstNameEdge)
+	alBo := homs.NewTupleElement(s("al"), s("bo"))
+	if first.Evaluate(alBo) != s("al") || firstName.Evaluate(alBo) != s("Al") {
+		t.Errorf("(al, bo) points at %v with first name %v, want al and Al", first.Evaluate(alBo), firstName.Evaluate(alBo))
+	}
+}
+
+func sameElements(these []homs.Element, those []homs.Element) bool {
+	if len(these) != len(those) {
+		return false
+	}
+	seen := make(map[homs.Element]bool, len(these))
+	for _, x := range these {
+		seen[x] = true
+	}
+	for _, x := range those {
+		if !seen[x] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	source, target := colleagueSchemas(t)
+	member := "\tentity Member {\n\t\tfrom\n\t\t\tm : Person\n\t\treturn\n\t\t\tname = m.name\n\t}\n"
+	for _, example := range []struct {
+		text         string
+		line, column int
+	}{
+		{"schema {\n}", 1, 1},
+		{"query {\n" + member + member + "}", 8, 9},
+		{"query {\n\tentity Member {\n\t\tfrom\n\t\t\tm : Company\n\t}\n}", 4, 8},
+		{"query {\n\tentity Member {\n\t\tfrom\n\t\t\tm m : Person\n\t}\n}", 4, 6},
+		{"query {\n\tentity Member {\n\t\tfrom\n\t\t\tm : Person\n\t\treturn\n\t\t\tname = m.surname\n\t}\n}", 6, 13},
+		{"query {\n\tentity Member {\n\t\tfrom\n\t\t\tm : Person\n\t\twhere\n\t\t\t1 = 2\n\t}\n}", 6, 4},
+		{"query {\n\tentity Member {\n\t\tfrom\n\t\t\tm : Person\n\t\twhere\n\t\t\tn = m.worksIn\n\t}\n}", 6, 4},
+		{"query {\n\tentity Member {\n\t\tfrom\n\t\t\tm : Person\n\t\twhere\n\t\t\tm.age = old\n\t}\n}", 6, 12},
+		{"query {\n\tentity Member {\n\t\tfrom\n\t\t\tm : Person\n\t\treturn\n\t\t\tnickname = m.name\n\t}\n}", 6, 4},
+		{"query {\n\tentity Member {\n\t\tfrom\n\t\t\tm : Person\n\t\treturn\n\t\t\tname = m.name\n\t\t\tname = m.name\n\t}\n}", 7, 4},
+		{"query {\n\tentity Pair {\n\t\tfrom\n\t\t\tp : Person\n\t\treturn\n\t\t\tfirst = { m = someone }\n\t}\n}", 6, 18},
+		{"query {\n\tentity Member {\n\t\tselect\n\t}\n}", 3, 3},
+		{"query {\n}\n}", 3, 1},
+	} {
+		_, err := ParseQuery(source, target, example.text)
+		expectParseError(t, err, example.line, example.column)
+	}
+}
+
+// a query that reads but leaves out the attributes of the target is reported by NewQuery
+func TestParseQueryDoesNotFit(t *testing.T) {
+	source, target := colleagueSchemas(t)
+	_, err := ParseQuery(source, target, "query {\n\tentity Member {\n\t\tfrom\n\t\t\tm : Person\n\t}\n}")
+	if _, isValidation := err.(rgdb.ValidationErrors); !isValidation {
+		t.Errorf("got %v, want ValidationErrors", err)
+	}
+}
+
+// the variables of a composite are bare names, so it can be written back as a query
+func TestComposedVariablesAreNames(t *testing.T) {
+	source, target := colleagueSchemas(t)
+	first, err := ParseQuery(source, target, colleaguesQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ParseQuery(target, target, `query {
+	entity Pair {
+		from
+			a : Pair
+		return
+			firstName = a.firstName
+			first = { m = a.first }
+	}
+	entity Member {
+		from
+			m : Member
+		return
+			name = m.name
+	}
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	composite, err := first.Compose(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair, _ := composite.Block("Pair")
+	for _, generator := range pair.From {
+		tokens, err := tokenize(generator.Variable)
+		if err != nil || len(tokens) != 2 || tokens[0].kind != wordToken {
+			t.Errorf("variable %q does not read as a single name", generator.Variable)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package schemaLanguage
+
+import (
+	"strings"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+)
+
+// the text of a schema looks like
+//
+//	schema {
+//		entities
+//			Employee Department
+//		foreign_keys
+//			manager : Employee -> Employee
+//			secretary : Department -> Employee on_delete cascade
+//		partial_foreign_keys
+//			worksIn : Employee -> Department on_delete set_undefined
+//		attributes
+//			"last name" : Employee -> string
+//		partial_path_equations
+//			"secretaries work in the correct department" : secretary.worksIn = id
+//		attribute_equations
+//			manager."last name" = "last name"
+//	}
+//
+// paths are edge names joined by . in the order they are followed and id is the empty path
+// names can be quoted to hold spaces or to use a keyword as a name
+// equations may be given a name before a colon, otherwise their text is their name
+// the other sections are relations, path_equations, kleene_path_equations,
+// relation_equations and relation_inclusions and any of them may be repeated or left out
+// a foreign key or partial foreign key may end with on_delete and one of restrict, cascade or set_undefined
+// to say what deleting an element does to the elements that point at it, restrict is the default
+const (
+	entitiesSection             = "entities"
+	foreignKeysSection          = "foreign_keys"
+	partialForeignKeysSection   = "partial_foreign_keys"
+	relationsSection            = "relations"
+	attributesSection           = "attributes"
+	pathEquationsSection        = "path_equations"
+	partialPathEquationsSection = "partial_path_equations"
+	kleenePathEquationsSection  = "kleene_path_equations"
+	relationEquationsSection    = "relation_equations"
+	relationInclusionsSection   = "relation_inclusions"
+	attributeEquationsSection   = "attribute_equations"
+	identityPath                = "id"
+	onDeleteKeyword             = "on_delete"
+)
+
+var schemaSections = []string{entitiesSection, foreignKeysSection, partialForeignKeysSection, relationsSection, attributesSection,
+	pathEquationsSection, partialPathEquationsSection, kleenePathEquationsSection, relationEquationsSection, relationInclusionsSection,
+	attributeEquationsSection}
+
+func isSectionKeyword(t token, sections []string) bool {
+	for _, section := range sections {
+		if t.isKeyword(section) {
+			return true
+		}
+	}
+	return false
+}
+
+// a declaration starts with a name that is not the start of the next section
+func startsDeclaration(t token, sections []string) bool {
+	return t.isName() && !isSectionKeyword(t, sections)
+}
+
+func ParseSchema(text string) (cgs.SchemaGraph, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return cgs.SchemaGraph{}, err
+	}
+	stream := &tokenStream{tokens: tokens}
+	toReturn, err := parseSchemaBlock(stream)
+	if err != nil {
+		return cgs.SchemaGraph{}, err
+	}
+	if t := stream.next(); t.kind != endToken {
+		return cgs.SchemaGraph{}, unexpected(t, "end of input")
+	}
+	return toReturn, nil
+}
+
+// schema { sections }
+func parseSchemaBlock(stream *tokenStream) (cgs.SchemaGraph, error) {
+	toReturn := cgs.EmptySchemaGraph()
+	if _, err := stream.expectKeyword("schema"); err != nil {
+		return toReturn, err
+	}
+	if _, err := stream.expectSymbol("{"); err != nil {
+		return toReturn, err
+	}
+	for !stream.peek().isSymbol("}") {
+		sectionToken := stream.next()
+		if !isSectionKeyword(sectionToken, schemaSections) {
+			return toReturn, unexpected(sectionToken, "a section name or '}'")
+		}
+		for startsDeclaration(stream.peek(), schemaSections) {
+			if err := parseSchemaDeclaration(stream, sectionToken.text, &toReturn); err != nil {
+				return toReturn, err
+			}
+		}
+	}
+	stream.next()
+	return toReturn, nil
+}
+
+func parseSchemaDeclaration(stream *tokenStream, section string, schema *cgs.SchemaGraph) error {
+	switch section {
+	case entitiesSection:
+		nameToken := stream.next()
+		if !schema.AddVertex2(nameToken.text) {
+			return ParseError{Position: nameToken.where, Message: "duplicate entity " + nameToken.describe()}
+		}
+		return nil
+	case foreignKeysSection, partialForeignKeysSection, relationsSection, attributesSection:
+		return parseEdgeDeclaration(stream, section, schema)
+	case attributeEquationsSection:
+		return parseAttributeEquation(stream, schema)
+	}
+	return parsePathEquation(stream, section, schema)
+}
+
+// one or more names : source -> target, then on_delete action for the foreign keys if it is given
+// for attributes the target is a sort
+func parseEdgeDeclaration(stream *tokenStream, section string, schema *cgs.SchemaGraph) error {
+	names := make([]token, 0, 1)
+	for stream.peek().isName() {
+		names = append(names, stream.next())
+	}
+	if _, err := stream.expectSymbol(":"); err != nil {
+		return err
+	}
+	sourceToken, err := stream.expectName()
+	if err != nil {
+		return err
+	}
+	if _, err := stream.expectSymbol("->"); err != nil {
+		return err
+	}
+	targetToken, err := stream.expectName()
+	if err != nil {
+		return err
+	}
+	action, hasAction := cgs.Restrict, false
+	if (section == foreignKeysSection || section == partialForeignKeysSection) && stream.peek().isKeyword(onDeleteKeyword) {
+		stream.next()
+		actionToken, err := stream.expectName()
+		if err != nil {
+			return err
+		}
+		var isAction bool
+		action, isAction = cgs.DeleteActionByName(actionToken.text)
+		if !isAction {
+			return ParseError{Position: actionToken.where, Message: "unknown delete action " + actionToken.describe()}
+		}
+		if section == foreignKeysSection && action == cgs.SetUndefined {
+			return ParseError{Position: actionToken.where, Message: "a foreign key is always defined so it can not be set_undefined"}
+		}
+		hasAction = true
+	}
+	for _, nameToken := range names {
+		if schema.HasEdgeNamed(nameToken.text) {
+			return ParseError{Position: nameToken.where, Message: "duplicate edge " + nameToken.describe()}
+		}
+		var added bool
+		switch section {
+		case foreignKeysSection:
+			added = schema.AddFunctionEdge2(sourceToken.text, targetToken.text, nameToken.text)
+		case partialForeignKeysSection:
+			added = schema.AddPartialFunctionEdge2(sourceToken.text, targetToken.text, nameToken.text)
+		case relationsSection:
+			added = schema.AddRelationEdge2(sourceToken.text, targetToken.text, nameToken.text)
+		case attributesSection:
+			sort, isSort := cgs.SortByName(targetToken.text)
+			if !isSort {
+				return ParseError{Position: targetToken.where, Message: "unknown sort " + targetToken.describe()}
+			}
+			added = schema.AddAttributeEdge2(sourceToken.text, sort, nameToken.text)
+		}
+		if !added {
+			return missingEndpoint(schema, sourceToken, targetToken, section == attributesSection)
+		}
+		if hasAction {
+			schema.SetDeleteAction(nameToken.text, action)
+		}
+	}
+	return nil
+}
+
+func missingEndpoint(schema *cgs.SchemaGraph, sourceToken token, targetToken token, isAttribute bool) ParseError {
+	present := make(map[string]bool)
+	for _, v := range schema.GetVertices() {
+		present[v.GetIdentifier()] = true
+	}
+	if !present[sourceToken.text] || isAttribute {
+		return ParseError{Position: sourceToken.where, Message: "unknown entity " + sourceToken.describe()}
+	}
+	return ParseError{Position: targetToken.where, Message: "unknown entity " + targetToken.describe()}
+}
+
+// name : or nothing, in which case the equation is named by its own text
+func parseEquationName(stream *tokenStream) (token, bool) {
+	if stream.peek().isName() && stream.peekAhead(1).isSymbol(":") {
+		nameToken := stream.next()
+		stream.next()
+		return nameToken, true
+	}
+	return stream.peek(), false
+}
+
+// id or edge names joined by .
+func parsePath(stream *tokenStream) ([]token, error) {
+	first, err := stream.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if first.isKeyword(identityPath) {
+		return []token{}, nil
+	}
+	toReturn := []token{first}
+	for stream.peek().isSymbol(".") {
+		stream.next()
+		nextToken, err := stream.expectName()
+		if err != nil {
+			return nil, err
+		}
+		toReturn = append(toReturn, nextToken)
+	}
+	return toReturn, nil
+}
+
+func pathText(path []token) string {
+	if len(path) == 0 {
+		return identityPath
+	}
+	parts := make([]string, len(path))
+	for i, t := range path {
+		parts[i] = t.text
+	}
+	return strings.Join(parts, ".")
+}
+
+func pathNames(path []token) []string {
+	toReturn := make([]string, len(path))
+	for i, t := range path {
+		toReturn[i] = t.text
+	}
+	return toReturn
+}
+
+// every edge of the path must exist already, returns the first one that does not
+func unknownEdge(schema *cgs.SchemaGraph, path []token) (token, bool) {
+	for _, t := range path {
+		if !schema.HasEdgeNamed(t.text) {
+			return t, true
+		}
+	}
+	return token{}, false
+}
+
+// equations of every kind share one namespace
+func hasEquationNamed(schema *cgs.SchemaGraph, name string) bool {
+	names := make([]string, 0)
+	for _, equation := range schema.GetFunctionEquations() {
+		names = append(names, equation.GetIdentifier())
+	}
+	for _, equation := range schema.GetPartialFunctionEquations() {
+		names = append(names, equation.GetIdentifier())
+	}
+	for _, equation := range schema.GetRelationEquations() {
+		names = append(names, equation.GetIdentifier())
+	}
+	for _, equation := range schema.GetAttributeEquations() {
+		names = append(names, equation.GetIdentifier())
+	}
+	for _, present := range names {
+		if present == name {
+			return true
+		}
+	}
+	return false
+}
+
+// [name :] path = path
+func parsePathEquation(stream *tokenStream, section string, schema *cgs.SchemaGraph) error {
+	nameToken, named := parseEquationName(stream)
+	lhs, err := parsePath(stream)
+	if err != nil {
+		return err
+	}
+	if _, err := stream.expectSymbol("="); err != nil {
+		return err
+	}
+	rhs, err := parsePath(stream)
+	if err != nil {
+		return err
+	}
+	name := pathText(lhs) + " = " + pathText(rhs)
+	if named {
+		name = nameToken.text
+	}
+	if hasEquationNamed(schema, name) {
+		return ParseError{Position: nameToken.where, Message: "duplicate equation " + name}
+	}
+	if len(lhs) == 0 && len(rhs) == 0 {
+		return ParseError{Position: nameToken.where, Message: "both sides of " + name + " are id"}
+	}
+	if bad, isBad := unknownEdge(schema, append(append([]token{}, lhs...), rhs...)); isBad {
+		return ParseError{Position: bad.where, Message: "unknown edge " + bad.describe()}
+	}
+	var added bool
+	switch section {
+	case pathEquationsSection:
+		added = schema.AddFunctionEquation2(pathNames(lhs), pathNames(rhs), name)
+	case partialPathEquationsSection:
+		added = schema.AddPartialFunctionEquation2(pathNames(lhs), pathNames(rhs), name)
+	case kleenePathEquationsSection:
+		added = schema.AddKleenePartialFunctionEquation2(pathNames(lhs), pathNames(rhs), name)
+	case relationEquationsSection:
+		added = schema.AddRelationEquation2(pathNames(lhs), pathNames(rhs), name)
+	case relationInclusionsSection:
+		added = schema.AddRelationInclusion2(pathNames(lhs), pathNames(rhs), name)
+	}
+	if !added {
+		return ParseError{Position: nameToken.where, Message: "equation " + name + " can not be imposed in " + section +
+			", its paths must compose, share source and target and only use the edge kinds that section allows"}
+	}
+	return nil
+}
+
+// [name :] function path ending in an attribute = function path ending in an attribute
+func parseAttributeEquation(stream *tokenStream, schema *cgs.SchemaGraph) error {
+	nameToken, named := parseEquationName(stream)
+	lhs, err := parsePath(stream)
+	if err != nil {
+		return err
+	}
+	if _, err := stream.expectSymbol("="); err != nil {
+		return err
+	}
+	rhs, err := parsePath(stream)
+	if err != nil {
+		return err
+	}
+	name := pathText(lhs) + " = " + pathText(rhs)
+	if named {
+		name = nameToken.text
+	}
+	if hasEquationNamed(schema, name) {
+		return ParseError{Position: nameToken.where, Message: "duplicate equation " + name}
+	}
+	if len(lhs) == 0 || len(rhs) == 0 {
+		return ParseError{Position: nameToken.where, Message: "both sides of " + name + " must end in an attribute"}
+	}
+	if bad, isBad := unknownEdge(schema, append(append([]token{}, lhs...), rhs...)); isBad {
+		return ParseError{Position: bad.where, Message: "unknown edge " + bad.describe()}
+	}
+	added := schema.AddAttributeEquation2(pathNames(lhs[:len(lhs)-1]), lhs[len(lhs)-1].text, pathNames(rhs[:len(rhs)-1]), rhs[len(rhs)-1].text, name)
+	if !added {
+		return ParseError{Position: nameToken.where, Message: "attribute equation " + name +
+			" can not be imposed, each side must be foreign keys followed by an attribute and both must share source and sort"}
+	}
+	return nil
+}
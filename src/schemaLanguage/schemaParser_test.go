@@ -0,0 +1,78 @@
+package schemaLanguage
+
+import (
+	"testing"
+
+	cgs "RelationalGraphDB/src/coloredGraphSchema"
+)
+
+const employeesSchema = `schema {
+	entities
+		Employee Department
+	foreign_keys
+		manager : Employee -> Employee
+		secretary : Department -> Employee on_delete cascade
+	partial_foreign_keys
+		worksIn : Employee -> Department on_delete set_undefined
+	attributes
+		"last name" : Employee -> string
+	partial_path_equations
+		"secretaries work in the correct department" : secretary.worksIn = id
+	attribute_equations
+		manager."last name" = "last name"
+}`
+
+func TestParseSchema(t *testing.T) {
+	schema, err := ParseSchema(employeesSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(schema.GetVertices()); got != 2 {
+		t.Errorf("%d entities, want 2", got)
+	}
+	if got := len(schema.GetFunctionEdges()); got != 2 {
+		t.Errorf("%d foreign keys, want 2", got)
+	}
+	if got := len(schema.GetPartialFunctionEquations()); got != 1 {
+		t.Errorf("%d partial path equations, want 1", got)
+	}
+	equations := schema.GetAttributeEquations()
+	if len(equations) != 1 || equations[0].GetIdentifier() != `manager.last name = last name` {
+		t.Errorf("attribute equations %v, want one named by its text", equations)
+	}
+	if got := schema.GetDeleteAction("secretary"); got != cgs.Cascade {
+		t.Errorf("secretary deletes with %v, want cascade", got)
+	}
+	if got := schema.GetDeleteAction("manager"); got != cgs.Restrict {
+		t.Errorf("manager deletes with %v, want restrict", got)
+	}
+}
+
+func expectParseError(t *testing.T, err error, line int, column int) {
+	t.Helper()
+	parseError, isParseError := err.(ParseError)
+	if !isParseError {
+		t.Fatalf("got %v, want a ParseError", err)
+	}
+	if parseError.Line != line || parseError.Column != column {
+		t.Errorf("error %v, want it at line %d, column %d", parseError, line, column)
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	for _, example := range []struct {
+		text         string
+		line, column int
+	}{
+		{"schema {\n\tentities\n\t\tA A\n}", 3, 5},
+		{"schema {\n\tentities\n\t\tA\n\tforeign_keys\n\t\tf : A -> B\n}", 5, 12},
+		{"schema {\n\tentities\n\t\tA\n\tforeign_keys\n\t\tf : A -> A on_delete set_undefined\n}", 5, 24},
+		{"schema {\n\tentities\n\t\tA\n\tforeign_keys\n\t\tf : A -> A\n\tpath_equations\n\t\tf.g = f\n}", 7, 5},
+		{"schema {\n\tentities\n\t\tA\n\tforeign_keys\n\t\tf : A -> A\n\tpath_equations\n\t\te : f.f = f\n\t\te : f = f.f\n}", 8, 3},
+		{"schema {\n\tentities\n\t\tA\n\tforeign_keys\n\t\tf : A -> A\n\tpath_equations\n\t\tf.f = f\n\tkleene_path_equations\n\t\tf.f = f\n}", 9, 3},
+		{"schema {\n\tbogus\n}", 2, 2},
+	} {
+		_, err := ParseSchema(example.text)
+		expectParseError(t, err, example.line, example.column)
+	}
+}
@@ -0,0 +1,5 @@
+// src2 is an older copy of src that does not build against the current API of src,
+// it is kept as its own module so that building the root module skips it
+module RelationalGraphDB/src2
+
+go 1.21